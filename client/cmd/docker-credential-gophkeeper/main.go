@@ -0,0 +1,12 @@
+// Помощник учетных данных docker: docker-credential-gophkeeper <store|get|erase|list>.
+// Подключение: "credsStore": "gophkeeper" в ~/.docker/config.json
+package main
+
+import (
+	"github.com/iurnickita/gophkeeper/client/internal/app"
+	"github.com/iurnickita/gophkeeper/client/internal/cli"
+)
+
+func main() {
+	cli.ExecuteDockerCredential(app.Start)
+}
@@ -0,0 +1,39 @@
+// Пакет app. Сборка сервиса клиента для выбранного профиля
+package app
+
+import (
+	"github.com/iurnickita/gophkeeper/client/internal/cache"
+	"github.com/iurnickita/gophkeeper/client/internal/config"
+	grpcclient "github.com/iurnickita/gophkeeper/client/internal/grpc_client/client"
+	"github.com/iurnickita/gophkeeper/client/internal/logger"
+	"github.com/iurnickita/gophkeeper/client/internal/profile"
+	"github.com/iurnickita/gophkeeper/client/internal/service"
+)
+
+// Start создает сервис профиля
+func Start(cfg config.Config, p profile.Profile) (service.Service, error) {
+	cfg.GRPCClient.Address = p.Server
+	cfg.GRPCClient.CAFile = p.CA
+	cfg.Cache.FileRepo = p.Cache
+
+	// Лог
+	zaplog, err := logger.NewZapLog(cfg.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Клиент
+	client, err := grpcclient.NewClient(cfg.GRPCClient)
+	if err != nil {
+		return nil, err
+	}
+
+	// Кэш
+	cache, err := cache.NewCache(cfg.Cache, zaplog)
+	if err != nil {
+		return nil, err
+	}
+
+	// Логика
+	return service.NewService(cfg.Service, client, cache, zaplog)
+}
@@ -0,0 +1,238 @@
+// Пакет audit. Проверка качества хранимых секретов: слабые, повторяющиеся и давно
+// не менявшиеся пароли, истекающие карты. Выполняется на клиенте, результаты на сервер не передаются
+package audit
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ccojocar/zxcvbn-go"
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
+)
+
+// Значения по умолчанию
+const (
+	DefaultMinScore   = 3
+	DefaultMaxAge     = 365 * 24 * time.Hour
+	DefaultCardWithin = 60 * 24 * time.Hour
+)
+
+// Options - параметры проверки
+type Options struct {
+	// Минимальная допустимая оценка пароля от 0 до 4
+	MinScore int
+	// Пароль, не менявшийся дольше MaxAge, считается устаревшим. 0 - не проверяется
+	MaxAge time.Duration
+	// Карты, срок действия которых истекает в течение CardWithin
+	CardWithin time.Duration
+	// Текущее время
+	Now time.Time
+}
+
+// DefaultOptions
+func DefaultOptions() Options {
+	return Options{
+		MinScore:   DefaultMinScore,
+		MaxAge:     DefaultMaxAge,
+		CardWithin: DefaultCardWithin,
+		Now:        time.Now(),
+	}
+}
+
+// Report - результат проверки. Сами секреты в отчет не попадают
+type Report struct {
+	// Проверено единиц данных входа и карт
+	Logins int `json:"logins"`
+	Cards  int `json:"cards"`
+
+	Weak     []Weak     `json:"weak"`
+	Reused   []Reused   `json:"reused"`
+	Old      []Old      `json:"old"`
+	Expiring []Expiring `json:"expiring"`
+}
+
+// Weak - слабый пароль
+type Weak struct {
+	Name string `json:"name"`
+	// Оценка от 0 до 4
+	Score   int     `json:"score"`
+	Entropy float64 `json:"entropy"`
+	// Оценка времени подбора
+	CrackTime string `json:"crack_time"`
+}
+
+// Reused - один пароль у нескольких единиц данных
+type Reused struct {
+	Names []string `json:"names"`
+}
+
+// Old - пароль, не менявшийся дольше допустимого
+type Old struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Days      int       `json:"days"`
+}
+
+// Expiring - карта с истекшим или истекающим сроком действия
+type Expiring struct {
+	Name      string    `json:"name"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Expired   bool      `json:"expired"`
+}
+
+// Issues - количество найденных проблем
+func (r Report) Issues() int {
+	return len(r.Weak) + len(r.Reused) + len(r.Old) + len(r.Expiring)
+}
+
+// Run проверяет единицы данных входа и карты
+func Run(units []model.Unit, opts Options) Report {
+	report := Report{Weak: []Weak{}, Reused: []Reused{}, Old: []Old{}, Expiring: []Expiring{}}
+	byPassword := make(map[string][]string)
+
+	for _, unit := range units {
+		switch unit.Body.Meta.Type {
+		case model.UnitTypeLogin:
+			report.Logins++
+			login := model.ParseLogin(unit.Body.Data)
+			if login.Password == "" {
+				continue
+			}
+			byPassword[login.Password] = append(byPassword[login.Password], unit.Name)
+
+			// Логин, имя и адрес - подсказки для поиска совпадений в пароле
+			strength := zxcvbn.PasswordStrength(login.Password, hints(unit.Name, login))
+			if strength.Score < opts.MinScore {
+				report.Weak = append(report.Weak, Weak{
+					Name:      unit.Name,
+					Score:     strength.Score,
+					Entropy:   strength.Entropy,
+					CrackTime: strength.CrackTimeDisplay,
+				})
+			}
+
+			updatedAt := unit.Body.Meta.UpdatedAt
+			if opts.MaxAge > 0 && !updatedAt.IsZero() && opts.Now.Sub(updatedAt) > opts.MaxAge {
+				report.Old = append(report.Old, Old{
+					Name:      unit.Name,
+					UpdatedAt: updatedAt,
+					Days:      int(opts.Now.Sub(updatedAt) / (24 * time.Hour)),
+				})
+			}
+		case model.UnitTypeCard:
+			report.Cards++
+			expiresAt, ok := CardExpiresAt(model.ParseCard(unit.Body.Data).Expiry)
+			if !ok {
+				expiresAt = unit.Body.Meta.ExpiresAt
+			}
+			if !expiresAt.IsZero() && !expiresAt.After(opts.Now.Add(opts.CardWithin)) {
+				report.Expiring = append(report.Expiring, Expiring{
+					Name:      unit.Name,
+					ExpiresAt: expiresAt,
+					Expired:   !expiresAt.After(opts.Now),
+				})
+			}
+		}
+	}
+
+	for _, names := range byPassword {
+		if len(names) > 1 {
+			slices.Sort(names)
+			report.Reused = append(report.Reused, Reused{Names: names})
+		}
+	}
+	slices.SortFunc(report.Reused, func(a, b Reused) int { return strings.Compare(a.Names[0], b.Names[0]) })
+	slices.SortFunc(report.Weak, func(a, b Weak) int { return a.Score - b.Score })
+	slices.SortFunc(report.Old, func(a, b Old) int { return b.Days - a.Days })
+	slices.SortFunc(report.Expiring, func(a, b Expiring) int { return a.ExpiresAt.Compare(b.ExpiresAt) })
+	return report
+}
+
+// hints возвращает слова, связанные с единицей данных
+func hints(name string, login model.LoginData) []string {
+	words := strings.FieldsFunc(unitpath.Base(name), func(r rune) bool {
+		return r == '.' || r == '-' || r == '_' || r == ' '
+	})
+	if login.Login != "" {
+		user, _, _ := strings.Cut(login.Login, "@")
+		words = append(words, login.Login, user)
+	}
+	if login.URL != "" {
+		host := strings.TrimPrefix(strings.TrimPrefix(login.URL, "https://"), "http://")
+		host, _, _ = strings.Cut(host, "/")
+		words = append(words, strings.Split(host, ".")...)
+	}
+	return words
+}
+
+// CardExpiresAt разбирает срок действия карты MM/YY или MM/YYYY.
+// Карта действует до конца указанного месяца
+func CardExpiresAt(expiry string) (time.Time, bool) {
+	month, year, found := strings.Cut(strings.TrimSpace(expiry), "/")
+	if !found {
+		return time.Time{}, false
+	}
+	m, err := strconv.Atoi(strings.TrimSpace(month))
+	if err != nil || m < 1 || m > 12 {
+		return time.Time{}, false
+	}
+	y, err := strconv.Atoi(strings.TrimSpace(year))
+	if err != nil || y < 0 {
+		return time.Time{}, false
+	}
+	if y < 100 {
+		y += 2000
+	}
+	return time.Date(y, time.Month(m)+1, 1, 0, 0, 0, 0, time.Local), true
+}
+
+// Counter возвращает количество утечек, в которых встречается пароль
+type Counter interface {
+	Count(password string) (int, error)
+}
+
+// BreachReport - результат проверки паролей по утечкам
+type BreachReport struct {
+	// Проверено единиц данных входа
+	Logins   int      `json:"logins"`
+	Breached []Breach `json:"breached"`
+}
+
+// Breach - пароль единицы данных, найденный в утечках
+type Breach struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Breaches проверяет пароли единиц данных входа по утечкам
+func Breaches(units []model.Unit, counter Counter) (BreachReport, error) {
+	report := BreachReport{Breached: []Breach{}}
+	counts := make(map[string]int)
+	for _, unit := range units {
+		if unit.Body.Meta.Type != model.UnitTypeLogin {
+			continue
+		}
+		report.Logins++
+		password := model.ParseLogin(unit.Body.Data).Password
+		if password == "" {
+			continue
+		}
+		count, ok := counts[password]
+		if !ok {
+			var err error
+			count, err = counter.Count(password)
+			if err != nil {
+				return BreachReport{}, err
+			}
+			counts[password] = count
+		}
+		if count > 0 {
+			report.Breached = append(report.Breached, Breach{Name: unit.Name, Count: count})
+		}
+	}
+	slices.SortFunc(report.Breached, func(a, b Breach) int { return b.Count - a.Count })
+	return report, nil
+}
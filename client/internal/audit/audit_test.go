@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local)
+	login := func(name string, password string, updatedAt time.Time) model.Unit {
+		return model.Unit{Name: name, Body: model.UnitBody{
+			Meta: model.UnitMeta{Type: model.UnitTypeLogin, UpdatedAt: updatedAt},
+			Data: model.LoginData{Login: "bob", Password: password}.Bytes(),
+		}}
+	}
+	card := func(name string, expiry string) model.Unit {
+		return model.Unit{Name: name, Body: model.UnitBody{
+			Meta: model.UnitMeta{Type: model.UnitTypeCard},
+			Data: model.CardData{Number: "4111111111111111", Expiry: expiry}.Bytes(),
+		}}
+	}
+	units := []model.Unit{
+		login("mail/google", "password1", now.AddDate(0, 0, -10)),
+		login("shop/amazon", "wQ7#vLp2!xZr9@Kt", now.AddDate(-2, 0, 0)),
+		login("shop/ebay", "wQ7#vLp2!xZr9@Kt", now.AddDate(0, -1, 0)),
+		card("cards/visa", "11/26"),
+		card("cards/master", "01/30"),
+		{Name: "notes/todo", Body: model.UnitBody{Meta: model.UnitMeta{Type: model.UnitTypeText}, Data: []byte("password1")}},
+	}
+
+	opts := DefaultOptions()
+	opts.Now = now
+	report := Run(units, opts)
+
+	require.Equal(t, 3, report.Logins)
+	require.Equal(t, 2, report.Cards)
+	require.Len(t, report.Weak, 1)
+	require.Equal(t, "mail/google", report.Weak[0].Name)
+	require.Equal(t, []Reused{{Names: []string{"shop/amazon", "shop/ebay"}}}, report.Reused)
+	require.Len(t, report.Old, 1)
+	require.Equal(t, "shop/amazon", report.Old[0].Name)
+	require.Len(t, report.Expiring, 1)
+	require.Equal(t, "cards/visa", report.Expiring[0].Name)
+	require.False(t, report.Expiring[0].Expired)
+	require.Equal(t, 4, report.Issues())
+}
+
+func TestCardExpiresAt(t *testing.T) {
+	expiresAt, ok := CardExpiresAt("12/27")
+	require.True(t, ok)
+	require.Equal(t, time.Date(2028, 1, 1, 0, 0, 0, 0, time.Local), expiresAt)
+
+	expiresAt, ok = CardExpiresAt("03/2031")
+	require.True(t, ok)
+	require.Equal(t, time.Date(2031, 4, 1, 0, 0, 0, 0, time.Local), expiresAt)
+
+	_, ok = CardExpiresAt("13/27")
+	require.False(t, ok)
+}
+
+// counter - утечки по паролю
+type counter map[string]int
+
+func (c counter) Count(password string) (int, error) {
+	return c[password], nil
+}
+
+func TestBreaches(t *testing.T) {
+	login := func(name string, password string) model.Unit {
+		return model.Unit{Name: name, Body: model.UnitBody{
+			Meta: model.UnitMeta{Type: model.UnitTypeLogin},
+			Data: model.LoginData{Password: password}.Bytes(),
+		}}
+	}
+	units := []model.Unit{login("a", "123456"), login("b", "secret"), login("c", "strong-unique"), login("d", "")}
+
+	report, err := Breaches(units, counter{"123456": 37000000, "secret": 300000})
+	require.NoError(t, err)
+	require.Equal(t, 4, report.Logins)
+	require.Equal(t, []Breach{{Name: "a", Count: 37000000}, {Name: "b", Count: 300000}}, report.Breached)
+}
@@ -0,0 +1,6 @@
+package config
+
+type Config struct {
+	// Локальная копия Pwned Passwords для audit breaches: файл, упорядоченный по хешу, или каталог диапазонов
+	PwnedFile string
+}
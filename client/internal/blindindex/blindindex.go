@@ -0,0 +1,179 @@
+// Пакет blindindex. Слепой индекс имен и тегов.
+// Клиент шифрует имена и теги и передает серверу вместо них токены HMAC,
+// по которым сервер выполняет поиск, не зная открытых значений
+package blindindex
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// NGramLen - длина n-грамм имени для поиска по подстроке
+const NGramLen = 3
+
+// KeyLen - длина ключа индекса
+const KeyLen = 32
+
+var (
+	ErrInvalidKey = errors.New("invalid index key")
+	ErrDecrypt    = errors.New("index decryption failed")
+)
+
+// Виды токенов: одинаковые строки разного назначения дают разные токены
+const (
+	kindName   = "name"
+	kindFolder = "folder"
+	kindNGram  = "ngram"
+	kindTag    = "tag"
+)
+
+// Indexer рассчитывает токены и шифрует имена ключом клиента
+type Indexer struct {
+	tokenKey []byte
+	aead     cipher.AEAD
+}
+
+// DeriveKey получает ключ индекса из учетных данных пользователя.
+// Одинаковые логин и пароль дают одинаковый ключ на всех устройствах
+func DeriveKey(login string, password string) []byte {
+	salt := sha256.Sum256([]byte("gophkeeper blind index:" + login))
+	return argon2.IDKey([]byte(password), salt[:], 1, 64*1024, 4, KeyLen)
+}
+
+// NewIndexer создает индексатор из ключа
+func NewIndexer(key []byte) (Indexer, error) {
+	if len(key) != KeyLen {
+		return Indexer{}, ErrInvalidKey
+	}
+	// Раздельные ключи для токенов и шифрования
+	block, err := aes.NewCipher(subKey(key, "encrypt"))
+	if err != nil {
+		return Indexer{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return Indexer{}, err
+	}
+	return Indexer{tokenKey: subKey(key, "token"), aead: aead}, nil
+}
+
+// subKey получает производный ключ для отдельного назначения
+func subKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("gophkeeper " + purpose))
+	return mac.Sum(nil)
+}
+
+// token рассчитывает токен значения
+func (i Indexer) token(kind string, value string) string {
+	mac := hmac.New(sha256.New, i.tokenKey)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// NameToken возвращает токен полного имени единицы данных
+func (i Indexer) NameToken(name string) string {
+	return i.token(kindName, name)
+}
+
+// FolderToken возвращает токен папки. Для корня - пустая строка
+func (i Indexer) FolderToken(folder string) string {
+	if folder == "" {
+		return ""
+	}
+	return i.token(kindFolder, folder)
+}
+
+// TagToken возвращает токен тега
+func (i Indexer) TagToken(tag string) string {
+	return i.token(kindTag, tag)
+}
+
+// TagTokens возвращает токены тегов
+func (i Indexer) TagTokens(tags []string) []string {
+	var tokens []string
+	for _, tag := range tags {
+		tokens = append(tokens, i.TagToken(tag))
+	}
+	return tokens
+}
+
+// NGrams возвращает токены n-грамм строки без учета регистра (без повторов).
+// Строка короче NGramLen n-грамм не имеет
+func (i Indexer) NGrams(s string) []string {
+	runes := []rune(strings.ToLower(s))
+	seen := make(map[string]bool)
+	var tokens []string
+	for pos := 0; pos+NGramLen <= len(runes); pos++ {
+		token := i.token(kindNGram, string(runes[pos:pos+NGramLen]))
+		if !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// Encrypt шифрует значение
+func (i Indexer) Encrypt(plaintext string) ([]byte, error) {
+	nonce := make([]byte, i.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return i.aead.Seal(nonce, nonce, []byte(plaintext), nil), nil
+}
+
+// Decrypt расшифровывает значение
+func (i Indexer) Decrypt(ciphertext []byte) (string, error) {
+	nonceSize := i.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return "", ErrDecrypt
+	}
+	plaintext, err := i.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plaintext), nil
+}
+
+// EncryptTags шифрует теги в строковом виде для передачи серверу
+func (i Indexer) EncryptTags(tags []string) ([]string, error) {
+	var encTags []string
+	for _, tag := range tags {
+		enc, err := i.Encrypt(tag)
+		if err != nil {
+			return nil, err
+		}
+		encTags = append(encTags, base64.StdEncoding.EncodeToString(enc))
+	}
+	return encTags, nil
+}
+
+// DecryptTags расшифровывает теги, полученные от сервера
+func (i Indexer) DecryptTags(encTags []string) ([]string, error) {
+	var tags []string
+	for _, encTag := range encTags {
+		enc, err := base64.StdEncoding.DecodeString(encTag)
+		if err != nil {
+			return nil, ErrDecrypt
+		}
+		tag, err := i.Decrypt(enc)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
@@ -0,0 +1,45 @@
+package blindindex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexer(t *testing.T) {
+	key := DeriveKey("bob", "bob")
+	require.Equal(t, key, DeriveKey("bob", "bob"))
+
+	idx, err := NewIndexer(key)
+	require.NoError(t, err)
+	other, err := NewIndexer(DeriveKey("alice", "bob"))
+	require.NoError(t, err)
+
+	// Токены детерминированы и зависят от ключа и назначения
+	require.Equal(t, idx.NameToken("infra/db/prod"), idx.NameToken("infra/db/prod"))
+	require.NotEqual(t, idx.NameToken("infra/db/prod"), other.NameToken("infra/db/prod"))
+	require.NotEqual(t, idx.NameToken("infra"), idx.FolderToken("infra"))
+	require.Empty(t, idx.FolderToken(""))
+
+	// Поиск по подстроке: n-граммы запроса входят в n-граммы имени
+	name := idx.NGrams("infra/DB/prod")
+	for _, token := range idx.NGrams("db/pr") {
+		require.Contains(t, name, token)
+	}
+	require.Empty(t, idx.NGrams("db"))
+
+	// Шифрование имен и тегов
+	enc, err := idx.Encrypt("infra/db/prod")
+	require.NoError(t, err)
+	dec, err := idx.Decrypt(enc)
+	require.NoError(t, err)
+	require.Equal(t, "infra/db/prod", dec)
+	_, err = other.Decrypt(enc)
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	encTags, err := idx.EncryptTags([]string{"prod", "db"})
+	require.NoError(t, err)
+	tags, err := idx.DecryptTags(encTags)
+	require.NoError(t, err)
+	require.Equal(t, []string{"prod", "db"}, tags)
+}
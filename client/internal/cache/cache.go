@@ -2,86 +2,133 @@
 package cache
 
 import (
-	"bufio"
-	"encoding/json"
+	"crypto/cipher"
 	"errors"
 	"os"
 	"slices"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/iurnickita/gophkeeper/client/internal/cache/config"
 	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
 	"go.uber.org/zap"
 )
 
 // Cache - интерфейс кэша
 type Cache interface {
-	GetList() ([]string, error)
-	SyncList(serverList []string) error
+	GetList() ([]string, []string, error)
+	SyncList(folder string, serverList []string, serverFolders []string) error
 	GetUnit(unitName string) (model.Unit, error)
+	GetUnits() ([]model.Unit, error)
 	SetUnit(unit model.Unit) error
 	DeleteUnit(unitName string) error
+	MoveUnit(from string, to string) error
+	AddFolder(folder string) error
 	GetToken() string
 	SetToken(token string)
+	GetIndexKey() string
+	SetIndexKey(key string)
+	ApplySync(units []model.Unit, deleted []string, full bool) error
+	GetCursor() int64
+	SetCursor(cursor int64)
+	GetOutbox() []model.Op
+	Pending() (int, error)
+	PushOutbox(op model.Op) (int64, error)
+	UpdateOutbox(op model.Op) error
+	RemoveOutbox(id int64) error
+	Unlock(secret []byte) error
+	Lock() error
+	Locked() bool
+	Hold() func()
+	Reset(secret []byte) error
+	Flush() error
 	Close() error
 }
 
 var (
-	ErrNotFound = errors.New("data not found")
-)
-
-const (
-	ListFileName  = "dataList.txt"
-	UnitsFileName = "dataUnits.json"
-	TokenFileName = "token.txt"
+	ErrNotFound      = errors.New("data not found")
+	ErrAlreadyExists = errors.New("already exists")
 )
 
 type cache struct {
-	cfg    config.Config
+	cfg config.Config
+	// Состояние блокировки: данные доступны при aead != nil
+	mux    sync.RWMutex
+	aead   cipher.AEAD
+	macKey []byte
+	idle   *time.Timer
+	// Число удержаний: пока больше 0, кэш не блокируется по бездействию
+	held   int
+	saved  snapshot
 	list   list
 	units  units
 	token  token
+	key    token
+	cursor token
+	outbox outbox
 	logger *zap.Logger
 }
 
 type list struct {
-	list []string
-	mux  *sync.Mutex
-	chg  bool
-	file *os.File
+	list    []string
+	folders []string
+	mux     *sync.Mutex
+	chg     bool
 }
 
 type units struct {
 	units map[string]model.Unit
 	mux   *sync.Mutex
 	chg   bool
-	file  *os.File
 }
 
 type token struct {
 	token string
 	chg   bool
-	file  *os.File
 }
 
-// GetList возвращает список доступных данных
-func (c *cache) GetList() ([]string, error) {
-	return c.list.list, nil
+// GetList возвращает список доступных данных и явно созданных папок
+func (c *cache) GetList() ([]string, []string, error) {
+	if err := c.use(); err != nil {
+		return nil, nil, err
+	}
+	defer c.mux.RUnlock()
+	c.list.mux.Lock()
+	defer c.list.mux.Unlock()
+	// Копии: списки изменяются синхронизацией и очищаются при блокировке
+	return slices.Clone(c.list.list), slices.Clone(c.list.folders), nil
 }
 
-// SyncList
-func (c *cache) SyncList(serverList []string) error {
+// SyncList заменяет содержимое папки folder (рекурсивно) списком с сервера
+func (c *cache) SyncList(folder string, serverList []string, serverFolders []string) error {
+	if err := c.use(); err != nil {
+		return err
+	}
+	defer c.mux.RUnlock()
 	c.list.mux.Lock()
 	defer c.list.mux.Unlock()
 
-	c.list.list = serverList
+	outside := func(name string) bool {
+		return !unitpath.InFolder(name, folder, true)
+	}
+	c.list.list = append(slices.DeleteFunc(c.list.list, func(name string) bool {
+		return !outside(name)
+	}), serverList...)
+	c.list.folders = append(slices.DeleteFunc(c.list.folders, func(name string) bool {
+		return !outside(name)
+	}), serverFolders...)
 	c.list.chg = true
 	return nil
 }
 
 // GetUnit
 func (c *cache) GetUnit(unitName string) (model.Unit, error) {
+	if err := c.use(); err != nil {
+		return model.Unit{}, err
+	}
+	defer c.mux.RUnlock()
 	c.units.mux.Lock()
 	defer c.units.mux.Unlock()
 	c.list.mux.Lock()
@@ -105,11 +152,37 @@ func (c *cache) GetUnit(unitName string) (model.Unit, error) {
 		return model.Unit{}, ErrNotFound
 	}
 
-	return unit, nil
+	return cloneUnit(unit), nil
+}
+
+// GetUnits возвращает все действительные единицы данных из кэша
+func (c *cache) GetUnits() ([]model.Unit, error) {
+	if err := c.use(); err != nil {
+		return nil, err
+	}
+	defer c.mux.RUnlock()
+	c.units.mux.Lock()
+	defer c.units.mux.Unlock()
+	c.list.mux.Lock()
+	defer c.list.mux.Unlock()
+
+	var units []model.Unit
+	for _, unitName := range c.list.list {
+		unit, ok := c.units.units[unitName]
+		if !ok || unit.Body.Meta.ValidUntil.Before(time.Now()) {
+			continue
+		}
+		units = append(units, cloneUnit(unit))
+	}
+	return units, nil
 }
 
 // SetUnit
 func (c *cache) SetUnit(unit model.Unit) error {
+	if err := c.use(); err != nil {
+		return err
+	}
+	defer c.mux.RUnlock()
 	c.units.mux.Lock()
 	defer c.units.mux.Unlock()
 	c.list.mux.Lock()
@@ -121,241 +194,259 @@ func (c *cache) SetUnit(unit model.Unit) error {
 	// Установка даты действия
 	unit.Body.Meta.ValidUntil = time.Now().AddDate(0, 0, c.cfg.ValidPeriod)
 	// Запись
-	c.list.list = append(c.list.list, unit.Name)
+	if !slices.Contains(c.list.list, unit.Name) {
+		c.list.list = append(c.list.list, unit.Name)
+	}
 	c.list.chg = true
-	c.units.units[unit.Name] = unit
+	c.units.units[unit.Name] = cloneUnit(unit)
 	c.units.chg = true
 	return nil
 }
 
-// DeleteUnit
+// DeleteUnit удаляет единицу данных из списка и полезные данные
 func (c *cache) DeleteUnit(unitName string) error {
+	if err := c.use(); err != nil {
+		return err
+	}
+	defer c.mux.RUnlock()
 	c.units.mux.Lock()
 	defer c.units.mux.Unlock()
+	c.list.mux.Lock()
+	defer c.list.mux.Unlock()
+
+	// Полезные данные
+	_, cached := c.units.units[unitName]
+	if cached {
+		delete(c.units.units, unitName)
+		c.units.chg = true
+	}
 
 	// Удаление из списка
 	idx := slices.Index(c.list.list, unitName)
 	if idx == -1 {
+		if cached {
+			return nil
+		}
 		return ErrNotFound
 	}
 	c.list.list = slices.Delete(c.list.list, idx, idx+1)
+	c.list.chg = true
 	return nil
 }
 
-// GetToken
-func (c *cache) GetToken() string {
-	return c.token.token
-}
-
-// SetToken
-func (c *cache) SetToken(token string) {
-	c.token.token = token
-	c.token.chg = true
-}
-
-// Close сохраняет данные и закрывает файлы
-func (c *cache) Close() error {
-	err := c.saveList()
-	if err != nil {
+// MoveUnit переименовывает единицу данных или папку целиком
+func (c *cache) MoveUnit(from string, to string) error {
+	if err := c.use(); err != nil {
 		return err
 	}
-	err = c.saveUnits()
-	if err != nil {
-		return err
-	}
-	err = c.saveToken()
-	if err != nil {
-		return err
-	}
-
-	c.list.file.Close()
-	c.units.file.Close()
-	c.token.file.Close()
-
-	return nil
-}
+	defer c.mux.RUnlock()
+	c.units.mux.Lock()
+	defer c.units.mux.Unlock()
+	c.list.mux.Lock()
+	defer c.list.mux.Unlock()
 
-// saveList сохраняет список в файл
-func (c *cache) saveList() error {
-	// были изменения
-	if !c.list.chg {
-		return nil
+	affected := func(name string) bool {
+		return name == from || unitpath.InFolder(name, from, true)
 	}
 
-	// перезапись файла
-	err := c.list.file.Truncate(0)
-	if err != nil {
-		return err
-	}
-	_, err = c.token.file.Seek(0, 0)
-	if err != nil {
-		return err
-	}
-	writer := bufio.NewWriter(c.list.file)
-	for _, unitName := range c.list.list {
-		// записываем в буфер
-		if _, err := writer.Write([]byte(unitName)); err != nil {
-			return err
+	// Проверка конфликтов с существующими данными
+	var moved int
+	for _, name := range c.list.list {
+		if !affected(name) {
+			continue
 		}
-		// добавляем перенос строки
-		if err := writer.WriteByte('\n'); err != nil {
-			return err
+		moved++
+		if target := unitpath.Rebase(name, from, to); slices.Contains(c.list.list, target) {
+			return ErrAlreadyExists
 		}
 	}
-	// записываем буфер в файл
-	writer.Flush()
-	return nil
-}
-
-// saveUnits сохраняет полезные данные в файл
-func (c *cache) saveUnits() error {
-	// были изменения
-	if !c.units.chg {
-		return nil
+	if moved == 0 && !slices.ContainsFunc(c.list.folders, affected) {
+		return ErrNotFound
 	}
 
-	// перезапись файла
-	err := c.units.file.Truncate(0)
-	if err != nil {
-		return err
-	}
-	_, err = c.token.file.Seek(0, 0)
-	if err != nil {
-		return err
-	}
-	writer := bufio.NewWriter(c.units.file)
-	for _, unit := range c.units.units {
-		// сериализация
-		jsonLine, err := json.Marshal(unit)
-		if err != nil {
-			return err
+	// Список
+	for i, name := range c.list.list {
+		if affected(name) {
+			c.list.list[i] = unitpath.Rebase(name, from, to)
 		}
-		// записываем в буфер
-		if _, err := writer.Write(jsonLine); err != nil {
-			return err
+	}
+	for i, name := range c.list.folders {
+		if affected(name) {
+			c.list.folders[i] = unitpath.Rebase(name, from, to)
 		}
-		// добавляем перенос строки
-		if err := writer.WriteByte('\n'); err != nil {
-			return err
+	}
+	slices.Sort(c.list.folders)
+	c.list.folders = slices.Compact(c.list.folders)
+	c.list.chg = true
+
+	// Полезные данные
+	for name, unit := range c.units.units {
+		if affected(name) {
+			delete(c.units.units, name)
+			unit.Name = unitpath.Rebase(name, from, to)
+			c.units.units[unit.Name] = unit
 		}
 	}
-	// записываем буфер в файл
-	writer.Flush()
+	c.units.chg = true
 	return nil
 }
 
-// saveToken сохраняет токен в файл
-func (c *cache) saveToken() error {
-	// были изменения
-	if !c.token.chg {
-		return nil
-	}
-
-	// перезапись файла
-	err := c.token.file.Truncate(0)
-	if err != nil {
+// AddFolder добавляет явно созданную папку
+func (c *cache) AddFolder(folder string) error {
+	if err := c.use(); err != nil {
 		return err
 	}
-	_, err = c.token.file.Seek(0, 0)
-	if err != nil {
-		return err
-	}
-	writer := bufio.NewWriter(c.token.file)
-	// записываем в буфер
-	_, err = writer.WriteString(c.token.token)
-	if err != nil {
-		return err
+	defer c.mux.RUnlock()
+	c.list.mux.Lock()
+	defer c.list.mux.Unlock()
+
+	if !slices.Contains(c.list.folders, folder) {
+		c.list.folders = append(c.list.folders, folder)
+		c.list.chg = true
 	}
-	// записываем буфер в файл
-	writer.Flush()
 	return nil
 }
 
-// init подготавливает данные из файлов
-func (c *cache) init() error {
-	// Чтение списка
-	list, err := c.initList()
-	if err != nil {
-		return err
-	}
-	// Чтение полезных данных
-	units, err := c.initUnits()
-	if err != nil {
-		return err
-	}
-	// Чтение токена
-	token, err := c.initToken()
-	if err != nil {
-		return err
+// GetToken
+func (c *cache) GetToken() string {
+	if c.use() != nil {
+		return ""
 	}
-
-	c.list = list
-	c.units = units
-	c.token = token
-	return nil
+	defer c.mux.RUnlock()
+	return c.token.token
 }
 
-// initList чтение списка
-func (c *cache) initList() (list, error) {
-	var l list
+// SetToken
+func (c *cache) SetToken(token string) {
+	if c.use() != nil {
+		return
+	}
+	defer c.mux.RUnlock()
+	c.token.token = token
+	c.token.chg = true
+}
 
-	file, err := os.OpenFile(c.cfg.FileRepo+ListFileName, os.O_RDWR|os.O_CREATE, 0666)
-	if err != nil {
-		return list{}, err
+// GetIndexKey возвращает ключ слепого индекса (hex)
+func (c *cache) GetIndexKey() string {
+	if c.use() != nil {
+		return ""
 	}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		l.list = append(l.list, string(scanner.Bytes()))
+	defer c.mux.RUnlock()
+	return c.key.token
+}
+
+// SetIndexKey
+func (c *cache) SetIndexKey(key string) {
+	if c.use() != nil {
+		return
 	}
-	l.mux = &sync.Mutex{}
-	l.file = file
-	return l, nil
+	defer c.mux.RUnlock()
+	c.key.token = key
+	c.key.chg = true
 }
 
-// initUnits чтение полезных данных
-func (c *cache) initUnits() (units, error) {
-	var u units
-	u.units = make(map[string]model.Unit)
+// ApplySync применяет изменения с сервера: записывает units и удаляет deleted.
+// При full единицы данных, отсутствующие в units, удаляются. Успешная синхронизация
+// подтверждает весь кэш: срок действия продлевается и не измененным единицам данных
+func (c *cache) ApplySync(units []model.Unit, deleted []string, full bool) error {
+	if err := c.use(); err != nil {
+		return err
+	}
+	defer c.mux.RUnlock()
+	c.units.mux.Lock()
+	defer c.units.mux.Unlock()
+	c.list.mux.Lock()
+	defer c.list.mux.Unlock()
 
-	file, err := os.OpenFile(c.cfg.FileRepo+UnitsFileName, os.O_RDWR|os.O_CREATE, 0666)
-	if err != nil {
-		return units{}, err
+	if full {
+		c.list.list = nil
+		c.units.units = make(map[string]model.Unit)
 	}
-	scanner := bufio.NewScanner(file)
-	var unit model.Unit
-	for scanner.Scan() {
-		if err := json.Unmarshal(scanner.Bytes(), &unit); err == nil {
-			u.units[unit.Name] = unit
+	for _, unit := range units {
+		if !slices.Contains(c.list.list, unit.Name) {
+			c.list.list = append(c.list.list, unit.Name)
 		}
+		c.units.units[unit.Name] = cloneUnit(unit)
+	}
+	for _, unitName := range deleted {
+		c.list.list = slices.DeleteFunc(c.list.list, func(name string) bool {
+			return name == unitName
+		})
+		delete(c.units.units, unitName)
+	}
+	validUntil := time.Now().AddDate(0, 0, c.cfg.ValidPeriod)
+	for name, unit := range c.units.units {
+		unit.Body.Meta.ValidUntil = validUntil
+		c.units.units[name] = unit
 	}
-	u.mux = &sync.Mutex{}
-	u.file = file
-	return u, nil
+	c.list.chg = true
+	c.units.chg = true
+	return nil
 }
 
-// initToken чтение токена
-func (c *cache) initToken() (token, error) {
-	file, err := os.OpenFile(c.cfg.FileRepo+TokenFileName, os.O_RDWR|os.O_CREATE, 0666)
+// GetCursor возвращает курсор последней синхронизации (0 - не выполнялась)
+func (c *cache) GetCursor() int64 {
+	if c.use() != nil {
+		return 0
+	}
+	defer c.mux.RUnlock()
+	cursor, err := strconv.ParseInt(c.cursor.token, 10, 64)
 	if err != nil {
-		return token{}, err
+		return 0
 	}
+	return cursor
+}
 
-	scanner := bufio.NewScanner(file)
-	scanner.Scan()
-	tokenString := scanner.Text()
+// SetCursor
+func (c *cache) SetCursor(cursor int64) {
+	if c.use() != nil {
+		return
+	}
+	defer c.mux.RUnlock()
+	c.cursor.token = strconv.FormatInt(cursor, 10)
+	c.cursor.chg = true
+}
 
-	var token token
-	token.token = tokenString
-	token.file = file
-	return token, nil
+// Flush сохраняет изменения в хранилище без закрытия кэша
+func (c *cache) Flush() error {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.flush()
 }
 
-// NewCache создает объект кэша
+// Close сохраняет данные и блокирует кэш
+func (c *cache) Close() error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.lock()
+}
+
+// NewCache создает объект кэша. Кэш разблокируется файлом ключа
+// или мастер-паролем из конфигурации, иначе остается заблокированным до Unlock
 func NewCache(cfg config.Config, logger *zap.Logger) (Cache, error) {
-	var cache cache
-	cache.cfg = cfg
-	cache.logger = logger
-	cache.init()
+	cache := &cache{cfg: cfg, logger: logger}
+	if cfg.FileRepo != "" {
+		if err := os.MkdirAll(cfg.FileRepo, 0700); err != nil {
+			return nil, err
+		}
+	}
+	var err error
 
-	return &cache, nil
+	var secret []byte
+	switch {
+	case cfg.KeyFile != "":
+		secret, err = os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+	case cfg.MasterPassword != "":
+		secret = []byte(cfg.MasterPassword)
+	default:
+		return cache, nil
+	}
+	err = cache.Unlock(secret)
+	if err != nil {
+		return nil, err
+	}
+	return cache, nil
 }
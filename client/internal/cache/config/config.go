@@ -1,6 +1,15 @@
 package config
 
+import "time"
+
 type Config struct {
+	// Каталог кэша
 	FileRepo    string
 	ValidPeriod int
+	// Файл ключа шифрования кэша. Имеет приоритет над мастер-паролем
+	KeyFile string
+	// Мастер-пароль для разблокировки кэша без входа
+	MasterPassword string
+	// Блокировка после бездействия. 0 - без блокировки
+	LockTimeout time.Duration
 }
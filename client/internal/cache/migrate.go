@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
+)
+
+// Файлы кэша прежнего формата. Переносятся в хранилище при первой разблокировке
+const (
+	ListFileName   = "dataList.txt"
+	UnitsFileName  = "dataUnits.json"
+	TokenFileName  = "token.txt"
+	KeyFileName    = "indexKey.txt"
+	CursorFileName = "syncCursor.txt"
+	OutboxFileName = "outbox.json"
+	VaultFileName  = "vault.json"
+)
+
+var legacyFileNames = []string{ListFileName, UnitsFileName, TokenFileName, KeyFileName, CursorFileName, OutboxFileName, VaultFileName}
+
+// maxLineSize - максимальный размер строки файла (единица данных с полезными данными)
+const maxLineSize = 64 << 20
+
+// migrate читает файлы прежнего формата. plain - файлы записаны без шифрования.
+// Возвращает найденные файлы, которые можно удалить после записи в хранилище
+func (c *cache) migrate(plain bool) ([]string, error) {
+	var found []string
+	read := func(fileName string, fn func(line []byte)) error {
+		file, err := os.Open(c.path(fileName))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		found = append(found, fileName)
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(nil, maxLineSize)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if !plain {
+				data, err := base64.StdEncoding.DecodeString(scanner.Text())
+				if err == nil {
+					line, err = c.open(data)
+				}
+				if err != nil {
+					c.logger.Sugar().Debugf("cache %s: skip line: %s", fileName, err)
+					continue
+				}
+			}
+			fn(line)
+		}
+		return scanner.Err()
+	}
+
+	// Список: повторяющиеся имена сводятся к одному
+	err := read(ListFileName, func(line []byte) {
+		if folder, ok := strings.CutSuffix(string(line), unitpath.Separator); ok {
+			if !slices.Contains(c.list.folders, folder) {
+				c.list.folders = append(c.list.folders, folder)
+			}
+			return
+		}
+		if !slices.Contains(c.list.list, string(line)) {
+			c.list.list = append(c.list.list, string(line))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = read(UnitsFileName, func(line []byte) {
+		var unit model.Unit
+		if err := json.Unmarshal(line, &unit); err == nil {
+			c.units.units[unit.Name] = unit
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = read(OutboxFileName, func(line []byte) {
+		var op model.Op
+		if err := json.Unmarshal(line, &op); err == nil {
+			c.outbox.ops = append(c.outbox.ops, op)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for fileName, t := range map[string]*token{TokenFileName: &c.token, KeyFileName: &c.key, CursorFileName: &c.cursor} {
+		err = read(fileName, func(line []byte) {
+			if t.token == "" {
+				t.token = string(line)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := os.Stat(c.path(VaultFileName)); err == nil {
+		found = append(found, VaultFileName)
+	}
+
+	c.list.chg, c.units.chg, c.outbox.chg = true, true, true
+	c.token.chg, c.key.chg, c.cursor.chg = true, true, true
+	return found, nil
+}
+
+// removeLegacy удаляет файлы кэша
+func (c *cache) removeLegacy(fileNames []string) error {
+	for _, fileName := range fileNames {
+		err := os.Remove(c.path(fileName))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	bolt "go.etcd.io/bbolt"
+)
+
+// outbox очередь отложенных операций. Очередь общая для процессов с одним хранилищем:
+// каждое изменение сразу записывается отдельной транзакцией по перечитанному состоянию
+type outbox struct {
+	ops []model.Op
+	mux *sync.Mutex
+	chg bool
+}
+
+// GetOutbox возвращает отложенные операции в порядке выполнения.
+// Очередь перечитывается из хранилища: ее могли изменить другие процессы
+func (c *cache) GetOutbox() []model.Op {
+	if c.use() != nil {
+		return nil
+	}
+	defer c.mux.RUnlock()
+	c.outbox.mux.Lock()
+	defer c.outbox.mux.Unlock()
+	err := c.updateOutbox(func(*bolt.Bucket) error { return nil })
+	if err != nil {
+		c.logger.Sugar().Debugf("cache outbox reload: %s", err)
+	}
+	ops := make([]model.Op, 0, len(c.outbox.ops))
+	for _, op := range c.outbox.ops {
+		op.Unit = cloneUnit(op.Unit)
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// Pending возвращает число отложенных операций. Заблокированный кэш не расшифровывается:
+// считаются записи очереди в хранилище, файл очереди прежнего формата - как одна операция
+func (c *cache) Pending() (int, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	if c.aead != nil {
+		c.outbox.mux.Lock()
+		defer c.outbox.mux.Unlock()
+		return len(c.outbox.ops), nil
+	}
+
+	var count int
+	if info, err := os.Stat(c.path(OutboxFileName)); err == nil && info.Size() > 0 {
+		count++
+	}
+	err := c.withDB(func(db *bolt.DB) error {
+		return db.View(func(tx *bolt.Tx) error {
+			if b := tx.Bucket(bucketOutbox); b != nil {
+				count += b.Stats().KeyN
+			}
+			return nil
+		})
+	})
+	return count, err
+}
+
+// PushOutbox добавляет операцию в конец очереди и возвращает ее номер
+func (c *cache) PushOutbox(op model.Op) (int64, error) {
+	if err := c.use(); err != nil {
+		return 0, err
+	}
+	defer c.mux.RUnlock()
+	c.outbox.mux.Lock()
+	defer c.outbox.mux.Unlock()
+
+	err := c.updateOutbox(func(b *bolt.Bucket) error {
+		// Номер больше номеров, выданных до появления счетчика
+		if key, _ := b.Cursor().Last(); key != nil && binary.BigEndian.Uint64(key) > b.Sequence() {
+			if err := b.SetSequence(binary.BigEndian.Uint64(key)); err != nil {
+				return err
+			}
+		}
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		op.ID = int64(id)
+		return c.putOp(b, op)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return op.ID, nil
+}
+
+// UpdateOutbox заменяет операцию с тем же номером
+func (c *cache) UpdateOutbox(op model.Op) error {
+	if err := c.use(); err != nil {
+		return err
+	}
+	defer c.mux.RUnlock()
+	c.outbox.mux.Lock()
+	defer c.outbox.mux.Unlock()
+
+	return c.updateOutbox(func(b *bolt.Bucket) error {
+		if b.Get(opKey(op.ID)) == nil {
+			return ErrNotFound
+		}
+		return c.putOp(b, op)
+	})
+}
+
+// RemoveOutbox удаляет операцию из очереди
+func (c *cache) RemoveOutbox(id int64) error {
+	if err := c.use(); err != nil {
+		return err
+	}
+	defer c.mux.RUnlock()
+	c.outbox.mux.Lock()
+	defer c.outbox.mux.Unlock()
+
+	return c.updateOutbox(func(b *bolt.Bucket) error {
+		if b.Get(opKey(id)) == nil {
+			return ErrNotFound
+		}
+		return b.Delete(opKey(id))
+	})
+}
+
+// updateOutbox изменяет очередь в хранилище одной транзакцией и загружает
+// получившуюся очередь. Вызывается под блокировкой c.outbox.mux
+func (c *cache) updateOutbox(fn func(b *bolt.Bucket) error) error {
+	var ops []model.Op
+	var saved map[int64][sha256.Size]byte
+	err := c.withDB(func(db *bolt.DB) error {
+		return db.Update(func(tx *bolt.Tx) error {
+			b, err := tx.CreateBucketIfNotExists(bucketOutbox)
+			if err != nil {
+				return err
+			}
+			if err := fn(b); err != nil {
+				return err
+			}
+			ops, saved, err = c.loadOutbox(tx)
+			return err
+		})
+	})
+	if err != nil {
+		return err
+	}
+	c.outbox.ops, c.saved.outbox = ops, saved
+	return nil
+}
+
+// putOp записывает операцию
+func (c *cache) putOp(b *bolt.Bucket, op model.Op) error {
+	value, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	return b.Put(opKey(op.ID), c.seal(value))
+}
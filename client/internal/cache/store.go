@@ -0,0 +1,364 @@
+package cache
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	bolt "go.etcd.io/bbolt"
+	berrors "go.etcd.io/bbolt/errors"
+)
+
+// DBFileName - файл хранилища кэша
+const DBFileName = "cache.db"
+
+// dbLockTimeout - ожидание хранилища, открытого другим процессом
+const dbLockTimeout = 10 * time.Second
+
+var (
+	ErrBusy = errors.New("cache is used by another gophkeeper process")
+)
+
+// Разделы хранилища
+var (
+	bucketMeta    = []byte("meta")
+	bucketList    = []byte("list")
+	bucketFolders = []byte("folders")
+	bucketUnits   = []byte("units")
+	bucketOutbox  = []byte("outbox")
+)
+
+// Ключи раздела meta
+var (
+	metaVault  = []byte("vault")
+	metaToken  = []byte("token")
+	metaKey    = []byte("indexkey")
+	metaCursor = []byte("cursor")
+)
+
+// snapshot - состояние хранилища после последней загрузки или записи.
+// Записываются только отличия от него, поэтому изменения другого процесса
+// в остальных записях не затираются
+type snapshot struct {
+	list    map[string]bool
+	folders map[string]bool
+	units   map[string][sha256.Size]byte
+	outbox  map[int64][sha256.Size]byte
+}
+
+// path возвращает путь к файлу в каталоге кэша
+func (c *cache) path(name string) string {
+	return filepath.Join(c.cfg.FileRepo, name)
+}
+
+// withDB открывает хранилище на время fn. Файл хранилища блокируется,
+// и другие процессы ждут его освобождения
+func (c *cache) withDB(fn func(db *bolt.DB) error) error {
+	db, err := bolt.Open(c.path(DBFileName), fileMode, &bolt.Options{Timeout: dbLockTimeout})
+	if errors.Is(err, berrors.ErrTimeout) {
+		return ErrBusy
+	}
+	if err != nil {
+		return err
+	}
+	err = fn(db)
+	if cerr := db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// nameKey - ключ записи по имени. Имена в хранилище не раскрываются
+func (c *cache) nameKey(name string) []byte {
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write([]byte(name))
+	return mac.Sum(nil)
+}
+
+// opKey - ключ записи очереди
+func opKey(id int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(id))
+}
+
+// load читает данные из хранилища
+func (c *cache) load(tx *bolt.Tx) error {
+	c.list = list{mux: &sync.Mutex{}}
+	c.units = units{units: make(map[string]model.Unit), mux: &sync.Mutex{}}
+	c.outbox = outbox{mux: &sync.Mutex{}}
+	c.token, c.key, c.cursor = token{}, token{}, token{}
+	c.saved = snapshot{
+		list:    make(map[string]bool),
+		folders: make(map[string]bool),
+		units:   make(map[string][sha256.Size]byte),
+		outbox:  make(map[int64][sha256.Size]byte),
+	}
+
+	// Список и папки
+	err := c.forEach(tx, bucketList, func(_ []byte, value []byte) error {
+		c.list.list = append(c.list.list, string(value))
+		c.saved.list[string(value)] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = c.forEach(tx, bucketFolders, func(_ []byte, value []byte) error {
+		c.list.folders = append(c.list.folders, string(value))
+		c.saved.folders[string(value)] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Порядок записей в хранилище определяется ключами
+	slices.Sort(c.list.list)
+	slices.Sort(c.list.folders)
+
+	// Полезные данные
+	err = c.forEach(tx, bucketUnits, func(_ []byte, value []byte) error {
+		var unit model.Unit
+		if err := json.Unmarshal(value, &unit); err != nil {
+			return err
+		}
+		c.units.units[unit.Name] = unit
+		c.saved.units[unit.Name] = sha256.Sum256(value)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Очередь
+	c.outbox.ops, c.saved.outbox, err = c.loadOutbox(tx)
+	if err != nil {
+		return err
+	}
+	// Токен, ключ индекса и курсор
+	if meta := tx.Bucket(bucketMeta); meta != nil {
+		for _, t := range []struct {
+			key   []byte
+			token *token
+		}{{metaToken, &c.token}, {metaKey, &c.key}, {metaCursor, &c.cursor}} {
+			if value := meta.Get(t.key); value != nil {
+				plain, err := c.open(value)
+				if err != nil {
+					return err
+				}
+				t.token.token = string(plain)
+			}
+		}
+	}
+	return nil
+}
+
+// loadOutbox читает очередь в порядке номеров операций
+func (c *cache) loadOutbox(tx *bolt.Tx) ([]model.Op, map[int64][sha256.Size]byte, error) {
+	var ops []model.Op
+	saved := make(map[int64][sha256.Size]byte)
+	err := c.forEach(tx, bucketOutbox, func(_ []byte, value []byte) error {
+		var op model.Op
+		if err := json.Unmarshal(value, &op); err != nil {
+			return err
+		}
+		ops = append(ops, op)
+		saved[op.ID] = sha256.Sum256(value)
+		return nil
+	})
+	return ops, saved, err
+}
+
+// forEach расшифровывает записи раздела. Поврежденные записи пропускаются
+func (c *cache) forEach(tx *bolt.Tx, bucket []byte, fn func(key []byte, value []byte) error) error {
+	b := tx.Bucket(bucket)
+	if b == nil {
+		return nil
+	}
+	return b.ForEach(func(key []byte, value []byte) error {
+		plain, err := c.open(value)
+		if err == nil {
+			err = fn(key, plain)
+		}
+		if err != nil {
+			c.logger.Sugar().Debugf("cache %s: skip record: %s", bucket, err)
+		}
+		return nil
+	})
+}
+
+// flush записывает изменения в хранилище одной транзакцией.
+// Вызывается под блокировкой c.mux
+func (c *cache) flush() error {
+	if c.aead == nil {
+		return nil
+	}
+	c.units.mux.Lock()
+	defer c.units.mux.Unlock()
+	c.list.mux.Lock()
+	defer c.list.mux.Unlock()
+	c.outbox.mux.Lock()
+	defer c.outbox.mux.Unlock()
+
+	// были изменения
+	if !c.list.chg && !c.units.chg && !c.outbox.chg && !c.token.chg && !c.key.chg && !c.cursor.chg {
+		return nil
+	}
+	return c.withDB(c.save)
+}
+
+// save записывает изменения. Вызывается под блокировками данных
+func (c *cache) save(db *bolt.DB) error {
+	saved := c.saved
+	err := db.Update(func(tx *bolt.Tx) error {
+		var err error
+		if c.list.chg {
+			saved.list, err = c.putNames(tx, bucketList, c.list.list, c.saved.list)
+			if err != nil {
+				return err
+			}
+			saved.folders, err = c.putNames(tx, bucketFolders, c.list.folders, c.saved.folders)
+			if err != nil {
+				return err
+			}
+		}
+		if c.units.chg {
+			saved.units, err = c.putUnits(tx)
+			if err != nil {
+				return err
+			}
+		}
+		if c.outbox.chg {
+			saved.outbox, err = c.putOutbox(tx)
+			if err != nil {
+				return err
+			}
+		}
+		meta, err := tx.CreateBucketIfNotExists(bucketMeta)
+		if err != nil {
+			return err
+		}
+		for _, t := range []struct {
+			key   []byte
+			token *token
+		}{{metaToken, &c.token}, {metaKey, &c.key}, {metaCursor, &c.cursor}} {
+			if !t.token.chg {
+				continue
+			}
+			if err := meta.Put(t.key, c.seal([]byte(t.token.token))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Изменения записаны
+	c.saved = saved
+	c.list.chg, c.units.chg, c.outbox.chg = false, false, false
+	c.token.chg, c.key.chg, c.cursor.chg = false, false, false
+	return nil
+}
+
+// putNames записывает отличия набора имен от сохраненного
+func (c *cache) putNames(tx *bolt.Tx, bucket []byte, names []string, saved map[string]bool) (map[string]bool, error) {
+	b, err := tx.CreateBucketIfNotExists(bucket)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]bool, len(names))
+	for _, name := range names {
+		current[name] = true
+		if saved[name] {
+			continue
+		}
+		if err := b.Put(c.nameKey(name), c.seal([]byte(name))); err != nil {
+			return nil, err
+		}
+	}
+	for name := range saved {
+		if current[name] {
+			continue
+		}
+		if err := b.Delete(c.nameKey(name)); err != nil {
+			return nil, err
+		}
+	}
+	return current, nil
+}
+
+// putUnits записывает измененные и удаляет отсутствующие единицы данных
+func (c *cache) putUnits(tx *bolt.Tx) (map[string][sha256.Size]byte, error) {
+	b, err := tx.CreateBucketIfNotExists(bucketUnits)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string][sha256.Size]byte, len(c.units.units))
+	for name, unit := range c.units.units {
+		value, err := json.Marshal(unit)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(value)
+		current[name] = sum
+		if prev, ok := c.saved.units[name]; ok && prev == sum {
+			continue
+		}
+		if err := b.Put(c.nameKey(name), c.seal(value)); err != nil {
+			return nil, err
+		}
+	}
+	for name := range c.saved.units {
+		if _, ok := current[name]; ok {
+			continue
+		}
+		if err := b.Delete(c.nameKey(name)); err != nil {
+			return nil, err
+		}
+	}
+	return current, nil
+}
+
+// putOutbox записывает изменения очереди
+func (c *cache) putOutbox(tx *bolt.Tx) (map[int64][sha256.Size]byte, error) {
+	b, err := tx.CreateBucketIfNotExists(bucketOutbox)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[int64][sha256.Size]byte, len(c.outbox.ops))
+	for _, op := range c.outbox.ops {
+		// Номера перенесенных операций не выдаются повторно
+		if uint64(op.ID) > b.Sequence() {
+			if err := b.SetSequence(uint64(op.ID)); err != nil {
+				return nil, err
+			}
+		}
+		value, err := json.Marshal(op)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(value)
+		current[op.ID] = sum
+		if prev, ok := c.saved.outbox[op.ID]; ok && prev == sum {
+			continue
+		}
+		if err := b.Put(opKey(op.ID), c.seal(value)); err != nil {
+			return nil, err
+		}
+	}
+	for id := range c.saved.outbox {
+		if _, ok := current[id]; ok {
+			continue
+		}
+		if err := b.Delete(opKey(id)); err != nil {
+			return nil, err
+		}
+	}
+	return current, nil
+}
@@ -0,0 +1,286 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/crypto/argon2"
+)
+
+// vaultKeyLen - длина ключа шифрования кэша
+const vaultKeyLen = 32
+
+// vaultCheck - контрольное значение для проверки мастер-пароля
+const vaultCheck = "gophkeeper cache"
+
+// fileMode - права на файл хранилища
+const fileMode = 0600
+
+var (
+	ErrLocked        = errors.New("cache is locked")
+	ErrWrongPassword = errors.New("wrong master password")
+)
+
+// vaultParams - параметры ключа, хранящиеся вместе с кэшем
+type vaultParams struct {
+	Salt  []byte `json:"salt"`
+	Check []byte `json:"check"`
+}
+
+// Unlock получает ключ из мастер-пароля (или содержимого файла ключа),
+// расшифровывает и загружает данные. Для нового кэша ключ создается,
+// а файлы кэша прежнего формата переносятся в хранилище
+func (c *cache) Unlock(secret []byte) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.aead != nil {
+		return nil
+	}
+
+	err := c.withDB(func(db *bolt.DB) error {
+		err := c.unlock(db, secret)
+		if err != nil {
+			c.wipe()
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if c.cfg.LockTimeout > 0 {
+		c.idle = time.AfterFunc(c.cfg.LockTimeout, c.lockIdle)
+	}
+	return nil
+}
+
+// unlock проверяет ключ и загружает данные из открытого хранилища
+func (c *cache) unlock(db *bolt.DB, secret []byte) error {
+	// Параметры ключа: в хранилище, в файле прежнего формата или новые
+	var params vaultParams
+	var data []byte
+	err := db.View(func(tx *bolt.Tx) error {
+		if meta := tx.Bucket(bucketMeta); meta != nil {
+			data = slices.Clone(meta.Get(metaVault))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	stored := data != nil
+	if !stored {
+		data, err = os.ReadFile(c.path(VaultFileName))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if data != nil {
+		if err := json.Unmarshal(data, &params); err != nil {
+			return err
+		}
+	} else {
+		params.Salt = make([]byte, 16)
+		if _, err := rand.Read(params.Salt); err != nil {
+			return err
+		}
+	}
+
+	// Ключ шифрования и ключ имен
+	key := argon2.IDKey(secret, params.Salt, 1, 64*1024, 4, vaultKeyLen)
+	defer clear(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	c.aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("gophkeeper cache names"))
+	c.macKey = mac.Sum(nil)
+
+	if params.Check == nil {
+		params.Check = c.seal([]byte(vaultCheck))
+	} else if check, err := c.open(params.Check); err != nil || string(check) != vaultCheck {
+		return ErrWrongPassword
+	}
+
+	err = db.View(c.load)
+	if err != nil {
+		return err
+	}
+	if stored {
+		return nil
+	}
+
+	// Новое хранилище: параметры ключа и перенос файлов прежнего формата
+	migrated, err := c.migrate(data == nil)
+	if err != nil {
+		return err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(bucketMeta)
+		if err != nil {
+			return err
+		}
+		vault, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		return meta.Put(metaVault, vault)
+	})
+	if err != nil {
+		return err
+	}
+	err = c.save(db)
+	if err != nil {
+		return err
+	}
+	return c.removeLegacy(migrated)
+}
+
+// Lock сохраняет изменения и удаляет из памяти ключ и расшифрованные данные
+func (c *cache) Lock() error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.lock()
+}
+
+// Locked проверяет, что кэш заблокирован
+func (c *cache) Locked() bool {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.aead == nil
+}
+
+// Reset удаляет кэш вместе с ключом и создает новый ключ из secret.
+// Выполняется по явному запросу пользователя, если пароль не подходит к кэшу
+func (c *cache) Reset(secret []byte) error {
+	c.mux.Lock()
+	err := c.lock()
+	if err == nil {
+		err = c.removeLegacy(append(legacyFileNames, DBFileName))
+	}
+	c.mux.Unlock()
+	if err != nil {
+		return err
+	}
+	return c.Unlock(secret)
+}
+
+// lock блокирует кэш. Вызывается под блокировкой c.mux
+func (c *cache) lock() error {
+	if c.aead == nil {
+		return nil
+	}
+	if c.idle != nil {
+		c.idle.Stop()
+		c.idle = nil
+	}
+	err := c.flush()
+	if err != nil {
+		return err
+	}
+	c.wipe()
+	return nil
+}
+
+// wipe удаляет из памяти ключ и расшифрованные данные
+func (c *cache) wipe() {
+	for _, unit := range c.units.units {
+		clear(unit.Body.Data)
+	}
+	for _, op := range c.outbox.ops {
+		clear(op.Unit.Body.Data)
+	}
+	clear(c.macKey)
+	c.list, c.units, c.token, c.key, c.cursor, c.outbox = list{}, units{}, token{}, token{}, token{}, outbox{}
+	c.saved = snapshot{}
+	c.aead, c.macKey = nil, nil
+}
+
+// Hold удерживает кэш от блокировки по бездействию для длительных команд
+// (наблюдение за изменениями). Возвращает функцию снятия удержания
+func (c *cache) Hold() func() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.held++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mux.Lock()
+			defer c.mux.Unlock()
+			c.held--
+			if c.held == 0 && c.idle != nil {
+				c.idle.Reset(c.cfg.LockTimeout)
+			}
+		})
+	}
+}
+
+// lockIdle блокирует кэш по истечении времени бездействия
+func (c *cache) lockIdle() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.idle == nil || c.held > 0 {
+		return
+	}
+	err := c.lock()
+	if err != nil {
+		c.logger.Sugar().Errorf("cache idle lock: %s", err)
+		return
+	}
+	c.logger.Sugar().Debug("cache locked after idle timeout")
+}
+
+// use захватывает кэш для работы с данными и продлевает время до блокировки.
+// При успехе вызывающий освобождает кэш через c.mux.RUnlock
+func (c *cache) use() error {
+	c.mux.RLock()
+	if c.aead == nil {
+		c.mux.RUnlock()
+		return ErrLocked
+	}
+	if c.idle != nil {
+		c.idle.Reset(c.cfg.LockTimeout)
+	}
+	return nil
+}
+
+// cloneUnit копирует полезные данные, чтобы очистка при блокировке
+// не затрагивала данные вызывающего
+func cloneUnit(unit model.Unit) model.Unit {
+	unit.Body.Data = slices.Clone(unit.Body.Data)
+	return unit
+}
+
+// seal шифрует запись
+func (c *cache) seal(plain []byte) []byte {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plain)+c.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		panic(err)
+	}
+	return c.aead.Seal(nonce, nonce, plain, nil)
+}
+
+// open расшифровывает запись
+func (c *cache) open(data []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(data) < size {
+		return nil, ErrWrongPassword
+	}
+	return c.aead.Open(nil, data[:size], data[size:], nil)
+}
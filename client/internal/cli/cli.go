@@ -1,81 +1,184 @@
 package cli
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/iurnickita/gophkeeper/client/internal/audit"
+	"github.com/iurnickita/gophkeeper/client/internal/cache"
+	"github.com/iurnickita/gophkeeper/client/internal/config"
 	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/client/internal/profile"
 	"github.com/iurnickita/gophkeeper/client/internal/service"
+	"github.com/iurnickita/gophkeeper/client/internal/tui"
+	"github.com/iurnickita/gophkeeper/contract/pwned"
+	"github.com/iurnickita/gophkeeper/contract/settings"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
-// Execute инициализация CLI интерфейса
-func Execute(service service.Service) {
-	handler := cliHandler{service: service}
+// Starter создает сервис для профиля
+type Starter func(cfg config.Config, profile profile.Profile) (service.Service, error)
 
+// Execute инициализация CLI интерфейса.
+// Конфигурация загружается после разбора флагов, сервис создается
+// для выбранного профиля перед выполнением команды
+func Execute(start Starter) {
+	// Запуск как помощник учетных данных git или docker
+	args, _ := credentialArgs(os.Args[0], os.Args[1:])
+	execute(start, args)
+}
+
+// ExecuteDockerCredential запускает помощник учетных данных docker: аргументы - действие
+func ExecuteDockerCredential(start Starter) {
+	execute(start, append([]string{"docker-credential"}, os.Args[1:]...))
+}
+
+// execute выполняет команду. Без args используются аргументы запуска
+func execute(start Starter, args []string) {
+	handler := &cliHandler{start: start, in: bufio.NewReader(os.Stdin)}
+
+	rootCmd := newRootCmd(handler)
+	rootCmd.PersistentPreRunE = handler.startService
+	rootCmd.PersistentFlags().String("profile", "", "профиль (по умолчанию текущий)")
+	handler.cfg, handler.settings = config.GetConfig(rootCmd.PersistentFlags())
+
+	// Shell
+	var shellCmd = &cobra.Command{
+		Use:   "shell",
+		Short: "Shell",
+		Long: "Shell запускает интерактивный режим: одно подключение к серверу и разблокированный кэш на всю сессию, " +
+			"дополнение имен по Tab, скрытый ввод паролей. Команды с секретами в историю не попадают. Выход: exit или Ctrl+D",
+		Args: cobra.NoArgs,
+		RunE: handler.shell,
+	}
+	rootCmd.AddCommand(shellCmd)
+	usageArgs(shellCmd)
+
+	if args != nil {
+		rootCmd.SetArgs(args)
+	}
+
+	cmd, err := rootCmd.ExecuteC()
+	if handler.service != nil {
+		handler.service.Close()
+	}
+	if code := handler.report(cmd, err); code != exitOK {
+		os.Exit(code)
+	}
+}
+
+// newRootCmd создает дерево команд. В интерактивном режиме создается для каждой строки,
+// чтобы значения флагов не переходили между командами
+func newRootCmd(handler *cliHandler) *cobra.Command {
 	// root
 	var rootCmd = &cobra.Command{
 		Use:   "gophkpr",
 		Short: "GophKeeper менеджер паролей",
-		Long:  "GophKeeper менеджер паролей",
+		Long:  "GophKeeper менеджер паролей\n\n" + outputHelp,
+		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 
 		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
 	}
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usage(err)
+	})
+	handler.output = outputPlain
+	rootCmd.PersistentFlags().VarP(&handler.output, "output", "o", "формат вывода: plain, json, yaml, table")
 
 	// Register
 	var registerCmd = &cobra.Command{
-		Use:     "rg",
-		Aliases: []string{"register"},
-		Short:   "Register: rg <login> <password>",
-		Long:    "Register регистрирует нового пользователя. Формат ввода: rg <login> <password>",
-		Args:    cobra.ExactArgs(2),
-		Run:     handler.register,
+		Use:         "rg",
+		Aliases:     []string{"register"},
+		Short:       "Register: rg [[login] <password>]",
+		Long:        "Register регистрирует нового пользователя. Без login используется учетная запись профиля, без password пароль запрашивается со скрытым вводом. Формат ввода: rg [[login] <password>]",
+		Args:        cobra.RangeArgs(0, 2),
+		Annotations: map[string]string{annotationNoUnlock: "true"},
+		RunE:        handler.register,
 	}
+	registerCmd.Flags().Bool("reset-cache", false, "удалить кэш, зашифрованный другим паролем (без неотправленных изменений)")
 	rootCmd.AddCommand(registerCmd)
 
 	// Login
 	var loginCmd = &cobra.Command{
-		Use:     "lg",
-		Aliases: []string{"login"},
-		Short:   "Login: lg <login> <password>",
-		Long:    "Login производит вход на устройстве. Формат ввода: lg <login> <password>",
-		Args:    cobra.ExactArgs(2),
-		Run:     handler.login,
+		Use:         "lg",
+		Aliases:     []string{"login"},
+		Short:       "Login: lg [[login] <password>]",
+		Long:        "Login производит вход на устройстве. Без login используется учетная запись профиля, без password пароль запрашивается со скрытым вводом. Формат ввода: lg [[login] <password>]",
+		Args:        cobra.RangeArgs(0, 2),
+		Annotations: map[string]string{annotationNoUnlock: "true"},
+		RunE:        handler.login,
 	}
+	loginCmd.Flags().Bool("reset-cache", false, "удалить кэш, зашифрованный другим паролем (без неотправленных изменений)")
 	rootCmd.AddCommand(loginCmd)
 
 	// List
 	var listCmd = &cobra.Command{
 		Use:     "ls",
 		Aliases: []string{"list"},
-		Short:   "List",
-		Long:    "List возвращает список имен доступных данных",
-		Run:     handler.list,
+		Short:   "List: ls [folder] [-r]",
+		Long:    "List возвращает список имен доступных данных и папок. Формат ввода: ls [folder] [-r]",
+		Args:    cobra.MaximumNArgs(1),
+		RunE:    handler.list,
 	}
+	listCmd.Flags().BoolP("recursive", "r", false, "включая вложенные папки")
 	rootCmd.AddCommand(listCmd)
 
+	// Tree
+	var treeCmd = &cobra.Command{
+		Use:   "tree",
+		Short: "Tree: tree [folder]",
+		Long:  "Tree выводит дерево папок и данных. Формат ввода: tree [folder]",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  handler.tree,
+	}
+	rootCmd.AddCommand(treeCmd)
+
 	// Read
 	var readCmd = &cobra.Command{
 		Use:     "rd",
 		Aliases: []string{"read"},
-		Short:   "Read: rd <unitname>",
-		Long:    "Read возвращает единицу данных по имени. Формат ввода: rd <unitname>",
-		Args:    cobra.ExactArgs(1),
-		Run:     handler.read,
+		Short:   "Read: rd <unitname> [--field <name>]",
+		Long: "Read возвращает единицу данных по имени. С --field выводится только значение поля: " +
+			"login, password, url, number, holder, expiry, cvv, data, name, type, description, tags или пользовательское поле. " +
+			"Формат ввода: rd <unitname> [--field <name>]",
+		Args: cobra.ExactArgs(1),
+		RunE: handler.read,
 	}
+	readCmd.Flags().StringP("field", "F", "", "вывести только значение поля")
 	rootCmd.AddCommand(readCmd)
 
 	// Write
 	var writeCmd = &cobra.Command{
 		Use:     "wr",
 		Aliases: []string{"write"},
-		Short:   "Write: wr <unitname> <type> <data>",
-		Long:    "Write сохраняет единицу данных. Формат ввода: wr <unitname> <type> <data>",
-		Args:    cobra.ExactArgs(3),
-		Run:     handler.write,
+		Short:   "Write: wr <unitname> <type> [data | --generate]",
+		Long: "Write сохраняет единицу данных. Без data данные запрашиваются со скрытым вводом. " +
+			"С --generate пароль входа или текст генерируется по параметрам команды gen. Формат ввода: wr <unitname> <type> [data | --generate]",
+		Args: cobra.RangeArgs(2, 3),
+		RunE: handler.write,
 	}
+	writeCmd.Flags().StringP("desc", "d", "", "описание")
+	writeCmd.Flags().StringSliceP("tag", "t", nil, "теги (через запятую или повтором флага)")
+	writeCmd.Flags().StringArrayP("field", "f", nil, "пользовательское поле key=value")
+	writeCmd.Flags().StringArrayP("secret-field", "s", nil, "чувствительное (шифруемое) поле key=value")
+	writeCmd.Flags().String("expires", "", "срок действия: YYYY-MM-DD или RFC3339")
+	writeCmd.Flags().String("rotate-every", "", "периодичность смены: 90d, 12h")
+	writeCmd.Flags().Bool("generate", false, "сгенерировать пароль входа или текст")
+	addGenerateFlags(writeCmd.Flags())
 	rootCmd.AddCommand(writeCmd)
 
 	// Delete
@@ -83,102 +186,795 @@ func Execute(service service.Service) {
 		Use:     "dl",
 		Aliases: []string{"delete"},
 		Short:   "Delete: dl <unitname>",
-		Long:    "Delete удаляет единицу данных. Формат ввода: dl <unitname>",
+		Long:    "Delete перемещает единицу данных в корзину. Имя сразу становится свободным. Формат ввода: dl <unitname>",
 		Args:    cobra.ExactArgs(1),
-		Run:     handler.delete,
+		RunE:    handler.delete,
 	}
 	rootCmd.AddCommand(deleteCmd)
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Ошибка выполнения GophKeeper '%s'\n", err)
-		os.Exit(1)
+	// Move
+	var moveCmd = &cobra.Command{
+		Use:     "mv",
+		Aliases: []string{"move"},
+		Short:   "Move: mv <from> <to>",
+		Long:    "Move перемещает единицу данных или папку целиком. Если <to> оканчивается на \"/\", это папка назначения. Формат ввода: mv <from> <to>",
+		Args:    cobra.ExactArgs(2),
+		RunE:    handler.move,
+	}
+	rootCmd.AddCommand(moveCmd)
+
+	// Rename
+	var renameCmd = &cobra.Command{
+		Use:     "rn",
+		Aliases: []string{"rename"},
+		Short:   "Rename: rn <from> <name>",
+		Long:    "Rename переименовывает единицу данных или папку, не меняя родительскую папку. Формат ввода: rn <from> <name>",
+		Args:    cobra.ExactArgs(2),
+		RunE:    handler.rename,
+	}
+	rootCmd.AddCommand(renameCmd)
+
+	// Mkdir
+	var mkdirCmd = &cobra.Command{
+		Use:   "mkdir",
+		Short: "Mkdir: mkdir <folder>",
+		Long:  "Mkdir создает папку. Формат ввода: mkdir <folder>",
+		Args:  cobra.ExactArgs(1),
+		RunE:  handler.mkdir,
+	}
+	rootCmd.AddCommand(mkdirCmd)
+
+	// Find
+	var findCmd = &cobra.Command{
+		Use:   "find",
+		Short: "Find: find [--name <substr>] [--type <type>] [--tag <tag>] [--field <key>[=<value>]]",
+		Long:  "Find ищет единицы данных по подстроке имени, типу, тегам и пользовательским полям",
+		Args:  cobra.NoArgs,
+		RunE:  handler.find,
+	}
+	findCmd.Flags().StringP("name", "n", "", "подстрока имени")
+	findCmd.Flags().IntP("type", "y", 0, "тип единицы данных")
+	findCmd.Flags().StringSliceP("tag", "t", nil, "теги (через запятую или повтором флага)")
+	findCmd.Flags().StringArrayP("field", "f", nil, "поле key или key=value")
+	rootCmd.AddCommand(findCmd)
+
+	// Due
+	var dueCmd = &cobra.Command{
+		Use:   "due",
+		Short: "Due: due [--within <period>] [--fail]",
+		Long: "Due выводит единицы данных с истекшим или истекающим сроком действия и требующие смены, начиная с самых срочных. " +
+			"С --fail завершается с кодом 2, если есть просроченные",
+		Args: cobra.NoArgs,
+		RunE: handler.due,
+	}
+	dueCmd.Flags().String("within", "14d", "горизонт напоминаний: 14d, 36h")
+	dueCmd.Flags().Bool("fail", false, "код завершения 2 при наличии просроченных")
+	rootCmd.AddCommand(dueCmd)
+
+	// Audit
+	var auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Audit: audit [--min-score <0-4>] [--max-age <period>] [--card-within <period>]",
+		Long: "Audit проверяет данные входа и карты на клиенте: слабые пароли (оценка zxcvbn от 0 до 4), " +
+			"пароли, повторяющиеся в нескольких единицах данных, пароли, не менявшиеся дольше --max-age, " +
+			"и карты с истекающим сроком действия. Секреты и результаты проверки на сервер не передаются",
+		Args: cobra.NoArgs,
+		RunE: handler.audit,
+	}
+	auditCmd.Flags().Int("min-score", audit.DefaultMinScore, "минимальная допустимая оценка пароля")
+	auditCmd.Flags().String("max-age", "365d", "допустимый срок без смены пароля; 0 - не проверять")
+	auditCmd.Flags().String("card-within", "60d", "горизонт проверки срока действия карт")
+	var breachesCmd = &cobra.Command{
+		Use:   "breaches",
+		Short: "Breaches: audit breaches [--file <path>]",
+		Long: "Breaches проверяет пароли данных входа по локальной копии Pwned Passwords: " +
+			"файлу строк HASH:COUNT, упорядоченному по SHA-1, или каталогу файлов диапазонов PREFIX.txt. " +
+			"Путь задается --file или параметром audit.pwned_file. Внешние сервисы не используются",
+		Args: cobra.NoArgs,
+		RunE: handler.breaches,
+	}
+	breachesCmd.Flags().String("file", "", "файл или каталог Pwned Passwords")
+	auditCmd.AddCommand(breachesCmd)
+	rootCmd.AddCommand(auditCmd)
+
+	// Sync
+	var syncCmd = &cobra.Command{
+		Use:   "sync",
+		Short: "Sync",
+		Long:  "Sync загружает в кэш изменения с сервера после последней синхронизации. Выполняется также автоматически при входе и просмотре списка",
+		Args:  cobra.NoArgs,
+		RunE:  handler.sync,
+	}
+	rootCmd.AddCommand(syncCmd)
+
+	// Watch
+	var watchCmd = &cobra.Command{
+		Use:   "watch",
+		Short: "Watch",
+		Long:  "Watch поддерживает кэш в актуальном состоянии по событиям сервера и выводит изменения. Работает до прерывания (Ctrl+C)",
+		Args:  cobra.NoArgs,
+		RunE:  handler.watch,
+	}
+	rootCmd.AddCommand(watchCmd)
+
+	// Trash
+	var trashCmd = &cobra.Command{
+		Use:   "trash",
+		Short: "Trash",
+		Long:  "Trash выводит содержимое корзины: номер, дата удаления, тип и имя",
+		Args:  cobra.NoArgs,
+		RunE:  handler.trash,
+	}
+	rootCmd.AddCommand(trashCmd)
+
+	// Restore
+	var restoreCmd = &cobra.Command{
+		Use:   "restore",
+		Short: "Restore: restore <id>",
+		Long:  "Restore возвращает единицу данных из корзины. Имя не должно быть занято. Формат ввода: restore <id>",
+		Args:  cobra.ExactArgs(1),
+		RunE:  handler.restore,
+	}
+	rootCmd.AddCommand(restoreCmd)
+
+	// Purge
+	var purgeCmd = &cobra.Command{
+		Use:   "purge",
+		Short: "Purge: purge <id>... | purge --all",
+		Long:  "Purge окончательно удаляет единицы данных из корзины. Формат ввода: purge <id>... или purge --all",
+		RunE:  handler.purge,
 	}
+	purgeCmd.Flags().Bool("all", false, "очистить корзину целиком")
+	rootCmd.AddCommand(purgeCmd)
+
+	// Outbox
+	var outboxCmd = &cobra.Command{
+		Use:   "outbox",
+		Short: "Outbox",
+		Long:  "Outbox выводит изменения, сделанные без связи с сервером: номер, дата, операция, имя и состояние. Очередь отправляется при синхронизации",
+		Args:  cobra.NoArgs,
+		RunE:  handler.outbox,
+	}
+	rootCmd.AddCommand(outboxCmd)
+
+	// Resolve
+	var resolveCmd = &cobra.Command{
+		Use:   "resolve",
+		Short: "Resolve: resolve <id> mine|theirs|both",
+		Long:  "Resolve разрешает конфликт изменения из очереди: mine - применить свою версию, theirs - оставить серверную, both - сохранить свою версию под новым именем. Формат ввода: resolve <id> mine|theirs|both",
+		Args:  cobra.ExactArgs(2),
+		RunE:  handler.resolve,
+	}
+	rootCmd.AddCommand(resolveCmd)
+
+	// Reindex
+	var reindexCmd = &cobra.Command{
+		Use:   "reindex",
+		Short: "Reindex",
+		Long:  "Reindex шифрует имена и теги данных, записанных до введения слепого индекса. Выполняется также автоматически при входе",
+		Args:  cobra.NoArgs,
+		RunE:  handler.reindex,
+	}
+	rootCmd.AddCommand(reindexCmd)
+
+	// UI
+	var uiCmd = &cobra.Command{
+		Use:   "ui",
+		Short: "UI",
+		Long:  "UI запускает полноэкранный интерфейс: список данных с поиском (/), просмотр по типам, создание (n), изменение (e) и удаление (d), синхронизация (s)",
+		Args:  cobra.NoArgs,
+		RunE:  handler.ui,
+	}
+	rootCmd.AddCommand(uiCmd)
+
+	// Profile
+	addProfileCommands(rootCmd, handler)
+
+	// Config
+	addConfigCommands(rootCmd, handler)
+
+	// Gen
+	addGenCommands(rootCmd, handler)
+	addImportCommands(rootCmd, handler)
+	addExportCommands(rootCmd, handler)
+	addRunCommands(rootCmd, handler)
+	addInjectCommands(rootCmd, handler)
+	addCredentialCommands(rootCmd, handler)
+
+	usageArgs(rootCmd)
+	return rootCmd
 }
 
 // cliHandler обработчик CLI команд
 type cliHandler struct {
-	service service.Service
+	cfg      *config.Config
+	settings *settings.Set
+	profiles *profile.Store
+	start    Starter
+	profile  profile.Profile
+	service  service.Service
+	// Формат вывода
+	output outputFormat
+	// Стандартный ввод: ответы на запросы и команды интерактивного режима
+	in *bufio.Reader
+}
+
+// startService загружает конфигурацию и профили и создает сервис выбранного профиля.
+// Команды управления профилями и конфигурацией выполняются без сервиса, справка - без конфигурации
+func (h *cliHandler) startService(cmd *cobra.Command, args []string) error {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "help" || c.Name() == "completion" {
+			return nil
+		}
+	}
+	err := h.cfg.Load(h.settings, cmd.Flags())
+	if err != nil {
+		return err
+	}
+	h.profiles, err = profile.NewStore(h.cfg.Profile)
+	if err != nil {
+		return err
+	}
+
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[annotationNoService] != "" {
+			return nil
+		}
+	}
+	if !cmd.HasParent() {
+		return nil
+	}
+
+	name, _ := cmd.Flags().GetString("profile")
+	p, err := h.profiles.Get(name)
+	if err != nil {
+		return err
+	}
+	h.profile = p
+	h.service, err = h.start(*h.cfg, p)
+	if err != nil {
+		return err
+	}
+	return h.unlockPrompt(cmd)
+}
+
+// annotationNoUnlock - команда разблокирует кэш сама (паролем учетной записи)
+const annotationNoUnlock = "nounlock"
+
+// unlockPrompt запрашивает мастер-пароль со скрытым вводом, если кэш заблокирован
+// и ввод - терминал. Без терминала кэш разблокируется переменными окружения или входом
+func (h *cliHandler) unlockPrompt(cmd *cobra.Command) error {
+	if h.service == nil || cmd.Annotations[annotationNoUnlock] != "" ||
+		!h.service.Locked() || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil
+	}
+	password, err := h.ask("Master password: ", true)
+	if err != nil {
+		return err
+	}
+	return h.service.Unlock(password)
+}
+
+// credentials возвращает логин и пароль из аргументов: [[login] <password>].
+// Недостающие значения запрашиваются у пользователя
+func (h *cliHandler) credentials(args []string) (string, string, error) {
+	switch len(args) {
+	case 2:
+		return args[0], args[1], nil
+	case 1:
+		if h.profile.Login == "" {
+			return "", "", fmt.Errorf("profile %s has no login, specify it", h.profile.Name)
+		}
+		return h.profile.Login, args[0], nil
+	}
+
+	login := h.profile.Login
+	if login == "" {
+		var err error
+		login, err = h.ask("Login: ", false)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	password, err := h.ask("Password: ", true)
+	if err != nil {
+		return "", "", err
+	}
+	return login, password, nil
+}
+
+// ask запрашивает значение у пользователя. secret - ввод с терминала не отображается
+func (h *cliHandler) ask(prompt string, secret bool) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	fd := int(os.Stdin.Fd())
+	if secret && term.IsTerminal(fd) {
+		value, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(value), err
+	}
+	line, err := h.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
 }
 
 // Register
-func (h cliHandler) register(cmd *cobra.Command, args []string) {
-	err := h.service.Register(args[0], args[1])
+func (h *cliHandler) register(cmd *cobra.Command, args []string) error {
+	login, password, err := h.credentials(args)
+	if err != nil {
+		return err
+	}
+	err = h.resetCache(cmd, login, password, h.service.Register(login, password))
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		return
+		return err
 	}
-	fmt.Fprintln(os.Stdout, "OK")
+	h.rememberLogin(login)
+	return h.done(nil)
 }
 
 // Login
-func (h cliHandler) login(cmd *cobra.Command, args []string) {
-	err := h.service.Login(args[0], args[1])
+func (h *cliHandler) login(cmd *cobra.Command, args []string) error {
+	login, password, err := h.credentials(args)
+	if err != nil {
+		return err
+	}
+	err = h.resetCache(cmd, login, password, h.service.Login(login, password))
+	if err != nil {
+		return err
+	}
+	h.rememberLogin(login)
+	return h.done(nil)
+}
+
+// resetCache при --reset-cache удаляет кэш, к которому не подошел пароль
+// учетной записи, и повторяет вход. Учетная запись к этому моменту проверена сервером
+func (h *cliHandler) resetCache(cmd *cobra.Command, login string, password string, err error) error {
+	reset, _ := cmd.Flags().GetBool("reset-cache")
+	if !reset || !errors.Is(err, cache.ErrWrongPassword) {
+		return err
+	}
+	err = h.service.ResetCache(password)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		return
+		return err
+	}
+	return h.service.Login(login, password)
+}
+
+// done выводит итог изменения. Изменение, поставленное в очередь, выводится как queued
+func (h *cliHandler) done(err error) error {
+	switch {
+	case err == nil:
+		h.print(statusResult{Status: statusOK})
+	case errors.Is(err, service.ErrQueued):
+		// Офлайн. Изменение будет отправлено при синхронизации
+		h.print(statusResult{Status: statusQueued})
 	}
-	fmt.Fprintln(os.Stdout, "OK")
+	return err
+}
+
+// usable проверяет, что результат можно вывести: без ошибки или из кэша без связи с сервером
+func usable(err error) bool {
+	return err == nil || errors.Is(err, service.ErrOffline)
 }
 
 // List
-func (h cliHandler) list(cmd *cobra.Command, args []string) {
-	list, err := h.service.List()
-	if err != nil {
-		switch err {
-		case service.ErrOffline:
-			// Офлайн. Выводим результат с предупреждением
-			fmt.Fprintln(os.Stdout, err.Error())
-		default:
-			// Ошибка
-			fmt.Fprintln(os.Stderr, err.Error())
-			return
-		}
+func (h *cliHandler) list(cmd *cobra.Command, args []string) error {
+	var folder string
+	if len(args) > 0 {
+		folder = args[0]
+	}
+	recursive, _ := cmd.Flags().GetBool("recursive")
+
+	list, folders, err := h.service.List(folder, recursive)
+	if !usable(err) {
+		return err
+	}
+	// Офлайн. Выводим результат, предупреждение - в stderr
+	h.print(newListResult(list, folders))
+	return err
+}
+
+// Tree
+func (h *cliHandler) tree(cmd *cobra.Command, args []string) error {
+	var folder string
+	if len(args) > 0 {
+		folder = args[0]
+	}
+
+	list, folders, err := h.service.List(folder, true)
+	if !usable(err) {
+		return err
 	}
-	fmt.Fprintln(os.Stdout, list)
+	folder, _ = unitpath.CleanFolder(folder)
+	h.print(treeResult{Folder: folder, listResult: newListResult(list, folders)})
+	return err
 }
 
 // Read
-func (h cliHandler) read(cmd *cobra.Command, args []string) {
+func (h *cliHandler) read(cmd *cobra.Command, args []string) error {
+	field, _ := cmd.Flags().GetString("field")
+
 	unit, err := h.service.Read(args[0])
-	if err != nil {
-		switch err {
-		case service.ErrOffline:
-			// Офлайн. Выводим результат с предупреждением
-			fmt.Fprintln(os.Stdout, err.Error())
-		default:
-			// Ошибка
-			fmt.Fprintln(os.Stderr, err.Error())
-			return
-		}
+	if !usable(err) {
+		return err
+	}
+	if field == "" {
+		h.print(newUnitView(unit, true))
+		return err
 	}
-	fmt.Fprintln(os.Stdout, unit)
+	value, fieldErr := unitField(unit, field)
+	if fieldErr != nil {
+		return fieldErr
+	}
+	h.print(valueResult{Value: value})
+	return err
 }
 
 // Write
-func (h cliHandler) write(cmd *cobra.Command, args []string) {
+func (h *cliHandler) write(cmd *cobra.Command, args []string) error {
 	// Формирование dataunit
 	unittype, err := strconv.Atoi(args[1])
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		return
+		return usage(err)
+	}
+	var data string
+	if gen, _ := cmd.Flags().GetBool("generate"); gen {
+		if len(args) == 3 {
+			return usage(errors.New("data and --generate are mutually exclusive"))
+		}
+		data, err = h.generateData(cmd, unittype)
+		if err != nil {
+			return err
+		}
+	} else if len(args) == 3 {
+		data = args[2]
+	} else {
+		data, err = h.ask("Data: ", true)
+		if err != nil {
+			return err
+		}
+	}
+	unit := model.Unit{Name: args[0], Body: model.UnitBody{Meta: model.UnitMeta{Type: unittype}, Data: []byte(data)}}
+
+	// Метаданные
+	unit.Body.Meta.Description, _ = cmd.Flags().GetString("desc")
+	unit.Body.Meta.Tags, _ = cmd.Flags().GetStringSlice("tag")
+	fields, _ := cmd.Flags().GetStringArray("field")
+	unit.Body.Meta.Fields, err = parseFields(fields, false, true)
+	if err != nil {
+		return usage(err)
+	}
+	secretFields, _ := cmd.Flags().GetStringArray("secret-field")
+	sensitive, err := parseFields(secretFields, true, true)
+	if err != nil {
+		return usage(err)
+	}
+	unit.Body.Meta.Fields = append(unit.Body.Meta.Fields, sensitive...)
+	if expires, _ := cmd.Flags().GetString("expires"); expires != "" {
+		unit.Body.Meta.ExpiresAt, err = parseDate(expires)
+		if err != nil {
+			return usage(err)
+		}
+	}
+	if rotateEvery, _ := cmd.Flags().GetString("rotate-every"); rotateEvery != "" {
+		unit.Body.Meta.RotateEvery, err = parsePeriod(rotateEvery)
+		if err != nil {
+			return usage(err)
+		}
 	}
-	unit := model.Unit{Name: args[0], Body: model.UnitBody{Meta: model.UnitMeta{Type: unittype}, Data: []byte(args[2])}}
 
 	// Запись
-	err = h.service.Write(unit)
+	return h.done(h.service.Write(unit))
+}
+
+// generateData генерирует данные единицы: пароль входа или текст
+func (h *cliHandler) generateData(cmd *cobra.Command, unittype int) (string, error) {
+	if unittype != model.UnitTypeLogin && unittype != model.UnitTypeText {
+		return "", usage(fmt.Errorf("--generate is supported for %s and %s units",
+			model.TypeName(model.UnitTypeLogin), model.TypeName(model.UnitTypeText)))
+	}
+	result, err := generate(cmd.Flags())
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		return
+		return "", err
 	}
-	fmt.Fprintln(os.Stdout, "OK")
+	fmt.Fprintln(os.Stderr, entropyNote(result.Entropy))
+	if unittype == model.UnitTypeLogin {
+		return string(model.LoginData{Password: result.Value}.Bytes()), nil
+	}
+	return result.Value, nil
 }
 
 // Delete
-func (h cliHandler) delete(cmd *cobra.Command, args []string) {
-	err := h.service.Delete(args[0])
+func (h *cliHandler) delete(cmd *cobra.Command, args []string) error {
+	return h.done(h.service.Delete(args[0]))
+}
+
+// Move
+func (h *cliHandler) move(cmd *cobra.Command, args []string) error {
+	return h.done(h.service.Move(args[0], args[1]))
+}
+
+// Rename
+func (h *cliHandler) rename(cmd *cobra.Command, args []string) error {
+	return h.done(h.service.Rename(args[0], args[1]))
+}
+
+// Mkdir
+func (h *cliHandler) mkdir(cmd *cobra.Command, args []string) error {
+	return h.done(h.service.Mkdir(args[0]))
+}
+
+// Find
+func (h *cliHandler) find(cmd *cobra.Command, args []string) error {
+	var query model.SearchQuery
+	query.Name, _ = cmd.Flags().GetString("name")
+	query.Type, _ = cmd.Flags().GetInt("type")
+	query.Tags, _ = cmd.Flags().GetStringSlice("tag")
+	fields, _ := cmd.Flags().GetStringArray("field")
+	var err error
+	query.Fields, err = parseFields(fields, false, false)
+	if err != nil {
+		return usage(err)
+	}
+
+	units, err := h.service.Search(query)
+	if !usable(err) {
+		return err
+	}
+	views := unitsResult{}
+	for _, unit := range units {
+		views = append(views, newUnitView(unit, false))
+	}
+	h.print(views)
+	return err
+}
+
+// Due
+func (h *cliHandler) due(cmd *cobra.Command, args []string) error {
+	withinFlag, _ := cmd.Flags().GetString("within")
+	within, err := parsePeriod(withinFlag)
+	if err != nil {
+		return usage(err)
+	}
+	fail, _ := cmd.Flags().GetBool("fail")
+
+	units, err := h.service.Due(within)
+	if !usable(err) {
+		return err
+	}
+
+	now := time.Now()
+	overdue := false
+	items := dueResult{}
+	for _, unit := range units {
+		dueAt, reason := unit.Body.Meta.DueAt()
+		state := stateDue
+		if !dueAt.After(now) {
+			state = stateOverdue
+			overdue = true
+		}
+		items = append(items, dueView{
+			Name:   unit.Name,
+			Type:   model.TypeName(unit.Body.Meta.Type),
+			DueAt:  dueAt,
+			Reason: reason,
+			State:  state,
+		})
+	}
+	h.print(items)
+	if fail && overdue {
+		return ErrOverdue
+	}
+	return err
+}
+
+// Audit
+func (h *cliHandler) audit(cmd *cobra.Command, args []string) error {
+	opts := audit.DefaultOptions()
+	opts.MinScore, _ = cmd.Flags().GetInt("min-score")
+	if opts.MinScore < 0 || opts.MinScore > 4 {
+		return usage(fmt.Errorf("invalid score %d, expected 0-4", opts.MinScore))
+	}
+	maxAge, _ := cmd.Flags().GetString("max-age")
+	var err error
+	if opts.MaxAge, err = parsePeriod(maxAge); err != nil {
+		return usage(err)
+	}
+	cardWithin, _ := cmd.Flags().GetString("card-within")
+	if opts.CardWithin, err = parsePeriod(cardWithin); err != nil {
+		return usage(err)
+	}
+
+	units, err := h.service.Units()
+	if !usable(err) {
+		return err
+	}
+	h.print(auditResult{audit.Run(units, opts)})
+	return err
+}
+
+// Breaches
+func (h *cliHandler) breaches(cmd *cobra.Command, args []string) error {
+	file, _ := cmd.Flags().GetString("file")
+	if file == "" {
+		file = h.cfg.Audit.PwnedFile
+	}
+	if file == "" {
+		return usage(errors.New("pwned passwords file is not set, use --file or audit.pwned_file"))
+	}
+	store, err := pwned.Open(file)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	units, err := h.service.Units()
+	if !usable(err) {
+		return err
+	}
+	report, breachErr := audit.Breaches(units, store)
+	if breachErr != nil {
+		return breachErr
+	}
+	h.print(breachesResult{report})
+	return err
+}
+
+// parsePeriod разбирает период в днях (90d) или в формате time.ParseDuration
+func parsePeriod(value string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		count, err := strconv.Atoi(days)
+		if err != nil || count < 0 {
+			return 0, fmt.Errorf("invalid period %q", value)
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+	period, err := time.ParseDuration(value)
+	if err != nil || period < 0 {
+		return 0, fmt.Errorf("invalid period %q", value)
+	}
+	return period, nil
+}
+
+// parseDate разбирает дату в формате YYYY-MM-DD (в местном времени) или RFC3339
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return date, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
+
+// Sync
+func (h *cliHandler) sync(cmd *cobra.Command, args []string) error {
+	result, err := h.service.Sync()
+	if err != nil {
+		return err
+	}
+	h.print(syncResult(result))
+	if result.Conflicts > 0 {
+		return fmt.Errorf("%w: %d", ErrConflicts, result.Conflicts)
+	}
+	return nil
+}
+
+// Outbox
+func (h *cliHandler) outbox(cmd *cobra.Command, args []string) error {
+	ops := outboxResult{}
+	for _, op := range h.service.Outbox() {
+		ops = append(ops, newOpView(op))
+	}
+	h.print(ops)
+	return nil
+}
+
+// Resolve
+func (h *cliHandler) resolve(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return usage(err)
+	}
+	return h.done(h.service.Resolve(id, args[1]))
+}
+
+// Watch
+func (h *cliHandler) watch(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return h.service.Watch(ctx, func(event model.Event) {
+		view := eventView{Time: time.Now(), Kind: event.Kind.String(), Name: event.Name}
+		if event.Kind == model.EventDisconnected {
+			view.Error, view.Retry = event.Err.Error(), event.Retry.String()
+			if h.output == outputPlain || h.output == outputTable {
+				fmt.Fprintf(os.Stderr, "%s: %s, retry in %s\n", view.Kind, view.Error, view.Retry)
+				return
+			}
+		}
+		h.stream(view)
+	})
+}
+
+// Trash
+func (h *cliHandler) trash(cmd *cobra.Command, args []string) error {
+	items, err := h.service.ListTrash()
+	if err != nil {
+		return err
+	}
+	views := trashResult{}
+	for _, item := range items {
+		views = append(views, trashView{
+			ID:        item.ID,
+			DeletedAt: item.DeletedAt,
+			Type:      model.TypeName(item.Type),
+			Name:      item.Name,
+			unitType:  item.Type,
+		})
+	}
+	h.print(views)
+	return nil
+}
+
+// Restore
+func (h *cliHandler) restore(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return usage(err)
+	}
+	return h.done(h.service.Restore(id))
+}
+
+// Purge
+func (h *cliHandler) purge(cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+	if all == (len(args) > 0) {
+		return usage(errors.New("specify trash ids or --all"))
+	}
+	var ids []int64
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return usage(err)
+		}
+		ids = append(ids, id)
+	}
+	count, err := h.service.Purge(ids)
+	if err != nil {
+		return err
+	}
+	h.print(countResult(count))
+	return nil
+}
+
+// parseFields разбирает поля формата key=value.
+// Без valueRequired допускается только ключ
+func parseFields(values []string, sensitive bool, valueRequired bool) ([]model.Field, error) {
+	var fields []model.Field
+	for _, value := range values {
+		key, val, found := strings.Cut(value, "=")
+		if key == "" || (valueRequired && !found) {
+			return nil, fmt.Errorf("invalid field %q, expected key=value", value)
+		}
+		fields = append(fields, model.Field{Key: key, Value: val, Sensitive: sensitive})
+	}
+	return fields, nil
+}
+
+// UI
+func (h *cliHandler) ui(cmd *cobra.Command, args []string) error {
+	return tui.Run(h.service, h.profile.Name)
+}
+
+// Reindex
+func (h *cliHandler) reindex(cmd *cobra.Command, args []string) error {
+	count, err := h.service.Reindex()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		return
+		return err
 	}
-	fmt.Fprintln(os.Stdout, "OK")
+	h.print(countResult(int64(count)))
+	return nil
 }
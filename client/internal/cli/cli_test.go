@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iurnickita/gophkeeper/client/internal/export"
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/client/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+// trashService - сервис, запоминающий запросы очистки корзины
+type trashService struct {
+	service.Service
+	calls [][]int64
+}
+
+// Purge
+func (s *trashService) Purge(ids []int64) (int64, error) {
+	s.calls = append(s.calls, ids)
+	return int64(len(ids)), nil
+}
+
+func TestPurge(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want [][]int64
+		err  bool
+	}{
+		{name: "ids", args: []string{"purge", "3", "5"}, want: [][]int64{{3, 5}}},
+		{name: "all", args: []string{"purge", "--all"}, want: [][]int64{nil}},
+		{name: "nothing", args: []string{"purge"}, err: true},
+		{name: "ids and all", args: []string{"purge", "--all", "3"}, err: true},
+		{name: "invalid id", args: []string{"purge", "bank/card"}, err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &trashService{}
+			rootCmd := newRootCmd(&cliHandler{service: fake})
+			rootCmd.SetArgs(test.args)
+			err := rootCmd.Execute()
+			if test.err {
+				code, _ := exitCode(err)
+				require.Equal(t, exitUsage, code)
+				require.Empty(t, fake.calls)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.want, fake.calls)
+		})
+	}
+}
+
+// importService - сервис с существующими единицами данных, запись которых может завершаться ошибкой
+type importService struct {
+	service.Service
+	units []string
+	// Ошибки записи по имени
+	errs map[string]error
+}
+
+// List
+func (s *importService) List(folder string, recursive bool) ([]string, []string, error) {
+	return s.units, nil, nil
+}
+
+// WriteUnits прерывает запись на заблокированном кэше
+func (s *importService) WriteUnits(units []model.Unit) ([]error, error) {
+	var results []error
+	for _, unit := range units {
+		err := s.errs[unit.Name]
+		results = append(results, err)
+		if errors.Is(err, service.ErrLocked) {
+			return results, err
+		}
+		if err == nil {
+			s.units = append(s.units, unit.Name)
+		}
+	}
+	return results, nil
+}
+
+func TestImport(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "passwords.csv")
+	require.NoError(t, os.WriteFile(file, []byte("name,url,username,password\na,https://a,u,1\nb,https://b,u,2\nc,https://c,u,3\n"), 0600))
+	run := func(fake *importService, args ...string) error {
+		rootCmd := newRootCmd(&cliHandler{service: fake})
+		rootCmd.SetArgs(append([]string{"import", file}, args...))
+		return rootCmd.Execute()
+	}
+
+	// Ошибка записи одной единицы данных не прерывает импорт
+	fake := &importService{errs: map[string]error{"b": errors.New("boom")}}
+	require.ErrorIs(t, run(fake), ErrImportFailed)
+	require.Equal(t, []string{"a", "c"}, fake.units)
+
+	// Повторный запуск дописывает оставшиеся
+	delete(fake.errs, "b")
+	require.NoError(t, run(fake, "--on-duplicate", "skip"))
+	require.Equal(t, []string{"a", "c", "b"}, fake.units)
+
+	// Без входа импорт прерывается после первой ошибки
+	fake = &importService{errs: map[string]error{"a": service.ErrLocked, "b": service.ErrLocked}}
+	err := run(fake)
+	require.ErrorIs(t, err, service.ErrLocked)
+	code, _ := exitCode(err)
+	require.Equal(t, exitUnauthenticated, code)
+	require.Empty(t, fake.units)
+}
+
+// exportService - сервис со снимком сервера и кэшем
+type exportService struct {
+	service.Service
+	server []model.Unit
+	cached []model.Unit
+	// Сервер недоступен
+	offline bool
+}
+
+// Snapshot
+func (s *exportService) Snapshot() ([]model.Unit, int, error) {
+	if s.offline {
+		return nil, 0, service.ErrOffline
+	}
+	return s.server, 1, nil
+}
+
+// Units
+func (s *exportService) Units() ([]model.Unit, error) {
+	if s.offline {
+		return s.cached, service.ErrOffline
+	}
+	return s.server, nil
+}
+
+func TestExport(t *testing.T) {
+	server := []model.Unit{{Name: "a", Body: model.UnitBody{Data: []byte("1")}}, {Name: "b", Body: model.UnitBody{Data: []byte("2")}}}
+	cached := server[:1]
+	tests := []struct {
+		name    string
+		args    []string
+		offline bool
+		want    []model.Unit
+		err     error
+	}{
+		{name: "server", want: server},
+		{name: "offline", offline: true, err: service.ErrOffline},
+		{name: "offline cache", args: []string{"--offline"}, offline: true, want: cached, err: service.ErrOffline},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "vault.gkx")
+			fake := &exportService{server: server, cached: cached, offline: test.offline}
+			rootCmd := newRootCmd(&cliHandler{service: fake, in: bufio.NewReader(strings.NewReader("pw\npw\n"))})
+			rootCmd.SetArgs(append([]string{"export", file}, test.args...))
+			err := rootCmd.Execute()
+			if test.err != nil {
+				require.ErrorIs(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+			}
+			data, readErr := os.ReadFile(file)
+			if test.want == nil {
+				require.ErrorIs(t, readErr, os.ErrNotExist)
+				return
+			}
+			require.NoError(t, readErr)
+			archive, err := export.Read(data, "pw")
+			require.NoError(t, err)
+			require.Len(t, archive.UnitList(), len(test.want))
+		})
+	}
+}
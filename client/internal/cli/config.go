@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// addConfigCommands добавляет команды конфигурации
+func addConfigCommands(rootCmd *cobra.Command, handler *cliHandler) {
+	var configCmd = &cobra.Command{
+		Use:         "config",
+		Short:       "Config: config show",
+		Long:        "Config работает с конфигурацией клиента. Приоритет значений: флаги, переменные среды, файл конфигурации, значения по умолчанию",
+		Annotations: map[string]string{annotationNoService: "true"},
+	}
+	rootCmd.AddCommand(configCmd)
+
+	// Show
+	var showCmd = &cobra.Command{
+		Use:   "show",
+		Short: "Show",
+		Long:  "Show выводит действующую конфигурацию и источник каждого значения. Секреты скрыты",
+		Args:  cobra.NoArgs,
+		RunE:  handler.configShow,
+	}
+	configCmd.AddCommand(showCmd)
+}
+
+// Config show
+func (h *cliHandler) configShow(cmd *cobra.Command, args []string) error {
+	h.print(configResult{File: h.settings.File(), Settings: h.settings.Values(), set: h.settings})
+	return nil
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/iurnickita/gophkeeper/client/internal/dockercred"
+	"github.com/iurnickita/gophkeeper/client/internal/gitcred"
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/client/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// Имена исполняемых файлов помощников: git-credential-<name>, docker-credential-<name>
+const (
+	gitHelperPrefix    = "git-credential-"
+	dockerHelperPrefix = "docker-credential-"
+)
+
+// annotationStdoutErrors - ошибка команды выводится в stdout: docker читает ее оттуда
+const annotationStdoutErrors = "stdouterrors"
+
+// addCredentialCommands добавляет команды помощников учетных данных git и docker
+func addCredentialCommands(rootCmd *cobra.Command, handler *cliHandler) {
+	var gitCredentialCmd = &cobra.Command{
+		Use:   "git-credential <get|store|erase>",
+		Short: "Git credential helper: git-credential <get|store|erase>",
+		Long: "Git-credential - помощник учетных данных git: запрос key=value из stdin, ответ в stdout. " +
+			"Данные входа ищутся по имени git/<host>/<username>, git/<host> или по полю URL; " +
+			"новые сохраняются в git/<host>/<username>. Без связи с сервером используется кэш. " +
+			"Подключение: git config --global credential.helper '!gophkpr git-credential' " +
+			"или ссылка git-credential-gophkpr на gophkpr в PATH и git config --global credential.helper gophkpr",
+		Args: cobra.ExactArgs(1),
+		RunE: handler.gitCredential,
+	}
+	rootCmd.AddCommand(gitCredentialCmd)
+
+	var dockerCredentialCmd = &cobra.Command{
+		Use:   "docker-credential <store|get|erase|list>",
+		Short: "Docker credential helper: docker-credential <store|get|erase|list>",
+		Long: "Docker-credential - помощник учетных данных docker: store читает из stdin JSON " +
+			"{\"ServerURL\",\"Username\",\"Secret\"}, get и erase - адрес реестра, get и list отвечают JSON в stdout. " +
+			"Данные входа хранятся в docker/<host>[/<path>], адрес реестра - в поле URL. " +
+			"Без связи с сервером используется кэш. Ошибки выводятся в stdout. " +
+			"Подключение: docker-credential-gophkeeper в PATH и \"credsStore\": \"gophkeeper\" в ~/.docker/config.json",
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{annotationStdoutErrors: "true"},
+		RunE:        handler.dockerCredential,
+	}
+	rootCmd.AddCommand(dockerCredentialCmd)
+}
+
+// credentialArgs возвращает аргументы команды при запуске как
+// git-credential-<name> <action> или docker-credential-<name> <action>
+func credentialArgs(program string, args []string) ([]string, bool) {
+	switch base := filepath.Base(program); {
+	case strings.HasPrefix(base, gitHelperPrefix):
+		return append([]string{"git-credential"}, args...), true
+	case strings.HasPrefix(base, dockerHelperPrefix):
+		return append([]string{"docker-credential"}, args...), true
+	}
+	return nil, false
+}
+
+// credentialVault - хранилище для помощников учетных данных.
+// Без связи с сервером данные берутся из кэша, изменения ставятся в очередь
+type credentialVault struct {
+	service service.Service
+}
+
+// Units
+func (v credentialVault) Units() ([]model.Unit, error) {
+	units, err := v.service.Units()
+	if errors.Is(err, service.ErrOffline) {
+		return units, nil
+	}
+	return units, err
+}
+
+// Write
+func (v credentialVault) Write(unit model.Unit) error {
+	if err := v.service.Write(unit); !errors.Is(err, service.ErrQueued) {
+		return err
+	}
+	return nil
+}
+
+// Delete
+func (v credentialVault) Delete(unitname string) error {
+	if err := v.service.Delete(unitname); !errors.Is(err, service.ErrQueued) {
+		return err
+	}
+	return nil
+}
+
+// Git credential
+func (h *cliHandler) gitCredential(cmd *cobra.Command, args []string) error {
+	return gitcred.Serve(credentialVault{service: h.service}, args[0], h.in, os.Stdout)
+}
+
+// Docker credential
+func (h *cliHandler) dockerCredential(cmd *cobra.Command, args []string) error {
+	err := dockercred.Serve(credentialVault{service: h.service}, args[0], h.in, os.Stdout)
+	if errors.Is(err, dockercred.ErrAction) {
+		return usage(err)
+	}
+	return err
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialArgs(t *testing.T) {
+	tests := []struct {
+		program string
+		args    []string
+		want    []string
+	}{
+		{program: "/usr/local/bin/git-credential-gophkpr", args: []string{"get"}, want: []string{"git-credential", "get"}},
+		{program: "docker-credential-gophkeeper", args: []string{"list"}, want: []string{"docker-credential", "list"}},
+		{program: "/usr/local/bin/gophkpr", args: []string{"ls"}},
+	}
+	for _, tt := range tests {
+		args, ok := credentialArgs(tt.program, tt.args)
+		require.Equal(t, tt.want != nil, ok, tt.program)
+		require.Equal(t, tt.want, args, tt.program)
+	}
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/iurnickita/gophkeeper/client/internal/export"
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/client/internal/service"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrPasswordMismatch = errors.New("passwords do not match")
+	ErrEmptyPassword    = errors.New("export password must not be empty")
+)
+
+// addExportCommands добавляет команду выгрузки
+func addExportCommands(rootCmd *cobra.Command, handler *cliHandler) {
+	var exportCmd = &cobra.Command{
+		Use:   "export <file>",
+		Short: "Export: export <file> [--force] [--offline]",
+		Long: "Export выгружает все данные пользователя в один зашифрованный архив: единицы данных всех типов " +
+			"с описанием, тегами, полями и сроками, бинарные данные целиком. Данные берутся полным снимком с сервера; " +
+			"без связи выгрузка не выполняется, --offline выгружает действительные данные кэша. " +
+			"Корзина не выгружается (восстановите нужное командой restore), истории версий сервер не хранит. " +
+			"Архив шифруется AES-256-GCM ключом из пароля выгрузки (Argon2id) и содержит SHA-256 каждой единицы данных. " +
+			"Восстановление на этот или другой сервер - import --from-export <file>",
+		Args: cobra.ExactArgs(1),
+		RunE: handler.exportFile,
+	}
+	exportCmd.Flags().Bool("force", false, "перезаписать существующий файл")
+	exportCmd.Flags().Bool("offline", false, "без связи с сервером выгрузить кэш")
+	rootCmd.AddCommand(exportCmd)
+}
+
+// exportResult - итог выгрузки
+type exportResult struct {
+	File  string `json:"file"`
+	Units int    `json:"units"`
+	// Выгружен кэш без связи с сервером
+	Offline bool `json:"offline,omitempty"`
+	// Изменения из очереди, не вошедшие в выгрузку
+	Unsent int `json:"unsent,omitempty"`
+}
+
+// plain
+func (r exportResult) plain(w io.Writer) {
+	fmt.Fprintf(w, "exported %d units to %s", r.Units, r.File)
+	if r.Offline {
+		fmt.Fprint(w, " from the offline cache")
+	}
+	if r.Unsent > 0 {
+		fmt.Fprintf(w, ", %d queued changes not included", r.Unsent)
+	}
+	fmt.Fprintln(w)
+}
+
+// Export
+func (h *cliHandler) exportFile(cmd *cobra.Command, args []string) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force, _ := cmd.Flags().GetBool("force"); force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	units, unsent, err := h.service.Snapshot()
+	offline, _ := cmd.Flags().GetBool("offline")
+	switch {
+	case errors.Is(err, service.ErrOffline) && offline:
+		// Явно запрошенная выгрузка кэша; изменения из очереди в нем уже применены
+		units, err = h.service.Units()
+		if !usable(err) {
+			return err
+		}
+	case errors.Is(err, service.ErrOffline):
+		return fmt.Errorf("%w: export needs the server, use --offline to export the cache", err)
+	case err != nil:
+		return err
+	}
+	password, askErr := h.newPassword("Export password: ")
+	if askErr != nil {
+		return askErr
+	}
+	archive := export.NewArchive(units)
+	archive.Login, archive.Server = h.profile.Login, h.profile.Server
+
+	file, openErr := os.OpenFile(args[0], flags, 0600)
+	if errors.Is(openErr, os.ErrExist) {
+		return usage(fmt.Errorf("%s already exists, use --force to overwrite", args[0]))
+	}
+	if openErr != nil {
+		return openErr
+	}
+	if writeErr := export.Write(file, archive, password); writeErr != nil {
+		file.Close()
+		os.Remove(args[0])
+		return writeErr
+	}
+	if closeErr := file.Close(); closeErr != nil {
+		return closeErr
+	}
+	h.print(exportResult{File: args[0], Units: len(units), Offline: err != nil, Unsent: unsent})
+	return err
+}
+
+// newPassword запрашивает новый пароль с подтверждением
+func (h *cliHandler) newPassword(prompt string) (string, error) {
+	password, err := h.ask(prompt, true)
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", usage(ErrEmptyPassword)
+	}
+	repeat, err := h.ask("Repeat "+strings.ToLower(prompt[:1])+prompt[1:], true)
+	if err != nil {
+		return "", err
+	}
+	if repeat != password {
+		return "", ErrPasswordMismatch
+	}
+	return password, nil
+}
+
+// readExport расшифровывает архив выгрузки и помещает единицы данных в папку
+func (h *cliHandler) readExport(data []byte, folder string) ([]model.Unit, error) {
+	folder, err := unitpath.CleanFolder(folder)
+	if err != nil {
+		return nil, usage(err)
+	}
+	password, err := h.ask("Export password: ", true)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := export.Read(data, password)
+	if err != nil {
+		return nil, err
+	}
+	units := archive.UnitList()
+	for i := range units {
+		units[i].Name = unitpath.Join(folder, units[i].Name)
+	}
+	return units, nil
+}
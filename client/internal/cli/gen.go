@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/iurnickita/gophkeeper/client/internal/generator"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// addGenCommands добавляет команду генерации паролей
+func addGenCommands(rootCmd *cobra.Command, handler *cliHandler) {
+	var genCmd = &cobra.Command{
+		Use:   "gen",
+		Short: "Gen: gen [--length <n>] [--no-symbols] [--no-ambiguous] | gen --passphrase [--words <n>]",
+		Long: "Gen генерирует пароль или парольную фразу из списка слов EFF и выводит энтропию результата. " +
+			"По умолчанию пароль из 20 символов, содержащий строчные и заглавные буквы, цифры и символы",
+		Args:        cobra.NoArgs,
+		RunE:        handler.gen,
+		Annotations: map[string]string{annotationNoService: "true"},
+	}
+	addGenerateFlags(genCmd.Flags())
+	rootCmd.AddCommand(genCmd)
+}
+
+// addGenerateFlags добавляет параметры генерации
+func addGenerateFlags(flags *pflag.FlagSet) {
+	flags.Int("length", generator.DefaultLength, "длина пароля")
+	flags.Bool("no-lower", false, "без строчных букв")
+	flags.Bool("no-upper", false, "без заглавных букв")
+	flags.Bool("no-digits", false, "без цифр")
+	flags.Bool("no-symbols", false, "без символов")
+	flags.Bool("no-ambiguous", false, "без неоднозначных символов "+generator.Ambiguous)
+	flags.String("exclude", "", "исключить символы")
+	flags.Bool("any-class", false, "не требовать символ каждого класса")
+	flags.Bool("passphrase", false, "парольная фраза из слов")
+	flags.Int("words", generator.DefaultWords, "количество слов парольной фразы")
+	flags.String("separator", "-", "разделитель слов парольной фразы")
+	flags.Bool("capitalize", false, "слова парольной фразы с заглавной буквы")
+}
+
+// generate генерирует пароль или парольную фразу по флагам
+func generate(flags *pflag.FlagSet) (generator.Result, error) {
+	if passphrase, _ := flags.GetBool("passphrase"); passphrase {
+		opts := generator.DefaultPassphraseOptions()
+		opts.Words, _ = flags.GetInt("words")
+		opts.Separator, _ = flags.GetString("separator")
+		opts.Capitalize, _ = flags.GetBool("capitalize")
+		result, err := generator.Passphrase(opts)
+		return result, usage(err)
+	}
+
+	opts := generator.DefaultPasswordOptions()
+	opts.Length, _ = flags.GetInt("length")
+	noLower, _ := flags.GetBool("no-lower")
+	noUpper, _ := flags.GetBool("no-upper")
+	noDigits, _ := flags.GetBool("no-digits")
+	noSymbols, _ := flags.GetBool("no-symbols")
+	opts.Lower, opts.Upper, opts.Digits, opts.Symbols = !noLower, !noUpper, !noDigits, !noSymbols
+	opts.NoAmbiguous, _ = flags.GetBool("no-ambiguous")
+	opts.Exclude, _ = flags.GetString("exclude")
+	anyClass, _ := flags.GetBool("any-class")
+	opts.RequireEach = !anyClass
+	result, err := generator.Password(opts)
+	return result, usage(err)
+}
+
+// genResult - сгенерированное значение
+type genResult struct {
+	Value string `json:"value"`
+	// Энтропия в битах
+	Entropy float64 `json:"entropy"`
+}
+
+// plain выводит значение; энтропия выводится в stderr, чтобы не попасть в канал
+func (r genResult) plain(w io.Writer) {
+	fmt.Fprintln(w, r.Value)
+	fmt.Fprintln(os.Stderr, entropyNote(r.Entropy))
+}
+
+// table
+func (r genResult) table() ([]string, [][]string) {
+	return []string{"value", "entropy"}, [][]string{{r.Value, strconv.FormatFloat(r.Entropy, 'f', 1, 64)}}
+}
+
+// entropyNote описывает энтропию результата
+func entropyNote(entropy float64) string {
+	return fmt.Sprintf("entropy: %.1f bits", entropy)
+}
+
+// Gen
+func (h *cliHandler) gen(cmd *cobra.Command, args []string) error {
+	result, err := generate(cmd.Flags())
+	if err != nil {
+		return err
+	}
+	h.print(genResult{Value: result.Value, Entropy: result.Entropy})
+	return nil
+}
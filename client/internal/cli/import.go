@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/iurnickita/gophkeeper/client/internal/importer"
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/client/internal/service"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// statusFailed - единица данных не записана
+	statusFailed = "failed"
+	// statusNotWritten - запись не выполнялась: импорт прерван
+	statusNotWritten = "not written"
+)
+
+var (
+	ErrImportFailed = errors.New("some units were not imported")
+)
+
+// addImportCommands добавляет команду импорта
+func addImportCommands(rootCmd *cobra.Command, handler *cliHandler) {
+	var importCmd = &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import: import <file> [--format <format> | --from-export] [--folder <folder>] [--dry-run] [--on-duplicate skip|overwrite|rename]",
+		Long: "Import загружает данные из файла экспорта другого менеджера паролей. Форматы: " +
+			"keepass-xml, kdbx (пароль базы запрашивается, файл ключа - --key-file), bitwarden (JSON без шифрования), " +
+			"1password-csv, 1pux, lastpass (CSV) и browser (CSV Chrome и Firefox). Формат определяется по файлу, если не задан --format. " +
+			"Записи становятся данными входа, картами, текстом или бинарными данными; группы, папки и хранилища - папками, " +
+			"теги и коллекции - тегами, прочие поля - пользовательскими полями. Повторы имен внутри файла получают номер, " +
+			"совпадения с существующими именами обрабатываются по --on-duplicate. --dry-run выводит план без записи. " +
+			"Итог показывает записанные и незаписанные единицы данных; при потере входа импорт прерывается, " +
+			"повторный запуск с --on-duplicate skip дописывает оставшиеся. " +
+			"--from-export восстанавливает архив команды export с проверкой целостности",
+		Args: cobra.ExactArgs(1),
+		RunE: handler.importFile,
+	}
+	importCmd.Flags().String("format", "", "формат файла: "+strings.Join(importer.Formats, ", "))
+	importCmd.Flags().String("folder", "", "папка для импортированных данных")
+	importCmd.Flags().String("key-file", "", "файл ключа базы KeePass")
+	importCmd.Flags().Bool("from-export", false, "восстановить архив команды export")
+	importCmd.Flags().Bool("dry-run", false, "вывести план импорта без записи")
+	importCmd.Flags().String("on-duplicate", importer.ActionSkip, "совпадение с существующим именем: skip, overwrite или rename")
+	rootCmd.AddCommand(importCmd)
+}
+
+// importView - единица данных в плане и итоге импорта. Данные не выводятся
+type importView struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Action string `json:"action"`
+	// Имя в файле, если единица данных переименована
+	Source string `json:"source,omitempty"`
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// importResult - план или итог импорта
+type importResult struct {
+	DryRun bool         `json:"dry_run"`
+	Units  []importView `json:"units"`
+}
+
+// count возвращает количество единиц данных с действием или итогом
+func (r importResult) count(match func(importView) bool) int {
+	var n int
+	for _, unit := range r.Units {
+		if match(unit) {
+			n++
+		}
+	}
+	return n
+}
+
+// plain выводит план или итог и сводку
+func (r importResult) plain(w io.Writer) {
+	for _, row := range r.rows() {
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(row, "\t"), "\t"))
+	}
+	action := func(action string) func(importView) bool {
+		return func(v importView) bool { return v.Action == action }
+	}
+	status := func(status string) func(importView) bool {
+		return func(v importView) bool { return v.Status == status }
+	}
+	if r.DryRun {
+		fmt.Fprintf(w, "dry run: %d to create, %d to overwrite, %d to rename, %d to skip\n",
+			r.count(action(importer.ActionCreate)), r.count(action(importer.ActionOverwrite)),
+			r.count(action(importer.ActionRename)), r.count(action(importer.ActionSkip)))
+		return
+	}
+	failed, notWritten := r.count(status(statusFailed)), r.count(status(statusNotWritten))
+	fmt.Fprintf(w, "imported %d, queued %d, skipped %d, failed %d, not written %d\n", r.count(status(statusOK)),
+		r.count(status(statusQueued)), r.count(action(importer.ActionSkip)), failed, notWritten)
+	if failed+notWritten > 0 {
+		fmt.Fprintln(w, "to import the rest, repeat the command with --on-duplicate skip: written units are skipped")
+	}
+}
+
+// rows
+func (r importResult) rows() [][]string {
+	rows := make([][]string, 0, len(r.Units))
+	for _, unit := range r.Units {
+		name := unit.Name
+		if unit.Source != "" {
+			name += " <- " + unit.Source
+		}
+		state := unit.Status
+		if unit.Error != "" {
+			state += ": " + unit.Error
+		}
+		rows = append(rows, []string{unit.Action, unit.Type, name, state})
+	}
+	return rows
+}
+
+// table
+func (r importResult) table() ([]string, [][]string) {
+	return []string{"action", "type", "name", "status"}, r.rows()
+}
+
+// parseImport разбирает файл экспорта другого менеджера паролей
+func (h *cliHandler) parseImport(cmd *cobra.Command, filename string, data []byte, folder string) ([]model.Unit, error) {
+	var err error
+	format, _ := cmd.Flags().GetString("format")
+	if format == "" {
+		if format, err = importer.Detect(filename, data); err != nil {
+			return nil, usage(err)
+		}
+	}
+	opts := importer.Options{Folder: folder}
+	if format == importer.FormatKDBX {
+		if keyFile, _ := cmd.Flags().GetString("key-file"); keyFile != "" {
+			if opts.KeyFile, err = os.ReadFile(keyFile); err != nil {
+				return nil, err
+			}
+		}
+		if opts.Password, err = h.ask("Database password: ", true); err != nil {
+			return nil, err
+		}
+	}
+	units, err := importer.Parse(format, data, opts)
+	if errors.Is(err, importer.ErrFormat) {
+		return nil, usage(err)
+	}
+	return units, err
+}
+
+// Import
+func (h *cliHandler) importFile(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	onDuplicate, _ := cmd.Flags().GetString("on-duplicate")
+	folder, _ := cmd.Flags().GetString("folder")
+	var units []model.Unit
+	if fromExport, _ := cmd.Flags().GetBool("from-export"); fromExport {
+		if cmd.Flags().Changed("format") {
+			return usage(errors.New("--format and --from-export are mutually exclusive"))
+		}
+		units, err = h.readExport(data, folder)
+	} else {
+		units, err = h.parseImport(cmd, args[0], data, folder)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Существующие имена: с сервера или, без связи, из кэша
+	existing, _, listErr := h.service.List("", true)
+	if !usable(listErr) {
+		return listErr
+	}
+	items, err := importer.Plan(units, existing, onDuplicate)
+	if err != nil {
+		return usage(err)
+	}
+
+	result := importResult{Units: make([]importView, 0, len(items))}
+	result.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	var writes []model.Unit
+	for _, item := range items {
+		if !result.DryRun && item.Action != importer.ActionSkip {
+			writes = append(writes, item.Unit)
+		}
+	}
+	// Запись одним проходом; abortErr - ошибка, после которой остальные записи не выполнялись
+	var results []error
+	var abortErr error
+	if len(writes) > 0 {
+		results, abortErr = h.service.WriteUnits(writes)
+	}
+
+	var queued, failed bool
+	var written int
+	for _, item := range items {
+		view := importView{Name: item.Unit.Name, Type: model.TypeName(item.Unit.Body.Meta.Type), Action: item.Action, Source: item.Source}
+		if !result.DryRun && item.Action != importer.ActionSkip {
+			switch {
+			case written >= len(results):
+				view.Status = statusNotWritten
+			case results[written] == nil:
+				view.Status = statusOK
+			case errors.Is(results[written], service.ErrQueued):
+				view.Status, queued = statusQueued, true
+			default:
+				view.Status, view.Error, failed = statusFailed, results[written].Error(), true
+			}
+			written++
+		}
+		result.Units = append(result.Units, view)
+	}
+	h.print(result)
+
+	switch {
+	case abortErr != nil:
+		return fmt.Errorf("%w: %w", ErrImportFailed, abortErr)
+	case failed:
+		return ErrImportFailed
+	case queued:
+		return service.ErrQueued
+	}
+	return listErr
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"text/template"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/client/internal/secretref"
+	"github.com/iurnickita/gophkeeper/client/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// addInjectCommands добавляет команду формирования файлов по шаблонам
+func addInjectCommands(rootCmd *cobra.Command, handler *cliHandler) {
+	var injectCmd = &cobra.Command{
+		Use:   "inject <template> [file]",
+		Short: "Inject: inject <template> [file] [--mode <octal>] [--watch]",
+		Long: "Inject формирует файл конфигурации по шаблону text/template с секретами: " +
+			`{{ secret "infra/db/prod" "password" }}, без поля - пароль, номер карты или данные; ` +
+			`{{ ref "gk://infra/db/prod#login" }} - ссылка как в run. Поля - как в rd --field. ` +
+			"Без file результат выводится в stdout. Файл записывается целиком (через временный файл) с правами --mode " +
+			"и не перезаписывается, если содержимое не изменилось. С --watch файл формируется заново " +
+			"при изменении использованных в шаблоне данных. Без связи с сервером значения берутся из кэша",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: handler.inject,
+	}
+	injectCmd.Flags().String("mode", "0600", "права файла, восьмеричное число")
+	injectCmd.Flags().BoolP("watch", "w", false, "формировать файл заново при изменении данных")
+	rootCmd.AddCommand(injectCmd)
+}
+
+// injectResult - итог формирования файла
+type injectResult struct {
+	File    string `json:"file"`
+	Secrets int    `json:"secrets"`
+	Changed bool   `json:"changed"`
+}
+
+// plain
+func (r injectResult) plain(w io.Writer) {
+	if !r.Changed {
+		fmt.Fprintf(w, "%s is up to date\n", r.File)
+		return
+	}
+	fmt.Fprintf(w, "rendered %s: %d secrets\n", r.File, r.Secrets)
+}
+
+// renderTemplate выполняет шаблон с функциями secret и ref
+func renderTemplate(name, text string, resolver *secretResolver) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"secret": func(unitname string, field ...string) (string, error) {
+			if len(field) > 1 {
+				return "", errors.New("secret expects a name and an optional field")
+			}
+			return resolver.resolve(secretref.Ref{Name: unitname, Field: append(field, "")[0]})
+		},
+		"ref": func(s string) (string, error) {
+			ref, err := secretref.Parse(s)
+			if err != nil {
+				return "", err
+			}
+			return resolver.resolve(ref)
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeSecretFile записывает файл через временный файл в том же каталоге.
+// Файл с тем же содержимым не перезаписывается, права приводятся к mode
+func writeSecretFile(file string, data []byte, mode os.FileMode) (bool, error) {
+	if current, err := os.ReadFile(file); err == nil && bytes.Equal(current, data) {
+		return false, os.Chmod(file, mode)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	return true, os.Rename(tmp.Name(), file)
+}
+
+// Inject
+func (h *cliHandler) inject(cmd *cobra.Command, args []string) error {
+	modeFlag, _ := cmd.Flags().GetString("mode")
+	mode, err := strconv.ParseUint(modeFlag, 8, 32)
+	if err != nil || mode > 0777 {
+		return usage(fmt.Errorf("invalid mode %q, expected octal permissions like 0600", modeFlag))
+	}
+	watch, _ := cmd.Flags().GetBool("watch")
+	if watch && len(args) < 2 {
+		return usage(errors.New("--watch requires an output file"))
+	}
+	text, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(args[0])
+
+	// Формирование файла; used - единицы данных, прочитанные шаблоном
+	var used map[string]model.Unit
+	render := func() (injectResult, error) {
+		resolver := newSecretResolver(h.service)
+		data, err := renderTemplate(name, string(text), resolver)
+		if err != nil {
+			return injectResult{}, err
+		}
+		used = resolver.units
+		if resolver.offline {
+			err = service.ErrOffline
+		}
+		result := injectResult{Secrets: len(resolver.values)}
+		var writeErr error
+		if len(args) < 2 {
+			_, writeErr = os.Stdout.Write(data)
+		} else {
+			result.File = args[1]
+			result.Changed, writeErr = writeSecretFile(args[1], data, os.FileMode(mode))
+		}
+		if writeErr != nil {
+			return injectResult{}, writeErr
+		}
+		return result, err
+	}
+
+	result, err := render()
+	if !usable(err) {
+		return err
+	}
+	if len(args) < 2 {
+		return err
+	}
+	if !watch {
+		h.print(result)
+		return err
+	}
+	h.stream(result)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return h.service.Watch(ctx, func(event model.Event) {
+		switch {
+		case event.Kind == model.EventDisconnected:
+			fmt.Fprintf(os.Stderr, "%s: %s, retry in %s\n", event.Kind, event.Err, event.Retry)
+			return
+		case event.Kind != model.EventSubscribed && event.Name != "":
+			// Изменения данных, не использованных в шаблоне, пропускаются
+			if _, ok := used[event.Name]; !ok {
+				return
+			}
+		}
+		result, err := render()
+		if !usable(err) {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return
+		}
+		if result.Changed {
+			h.stream(result)
+		}
+	})
+}
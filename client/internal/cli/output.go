@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/iurnickita/gophkeeper/client/internal/cache"
+	"github.com/iurnickita/gophkeeper/client/internal/dockercred"
+	"github.com/iurnickita/gophkeeper/client/internal/profile"
+	"github.com/iurnickita/gophkeeper/client/internal/service"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+)
+
+// Форматы вывода
+const (
+	outputPlain = "plain"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputTable = "table"
+)
+
+// Коды завершения
+const (
+	exitOK              = 0
+	exitError           = 1
+	exitOverdue         = 2
+	exitNotFound        = 3
+	exitOffline         = 4
+	exitUnauthenticated = 5
+	exitConflict        = 6
+	exitUsage           = 64
+)
+
+// outputHelp - описание форматов вывода и кодов завершения для справки
+const outputHelp = `Форматы вывода (--output):
+  plain   текст (по умолчанию)
+  json    JSON: единица данных {"name","type","description","tags","fields","login"|"card"|"text"|"binary",
+          "expires_at","rotate_every","created_at","updated_at"}, список {"units","folders"},
+          изменение {"status":"ok"|"queued"}, ошибка в stderr {"error":{"code","message"}}
+  yaml    YAML с теми же ключами, что и JSON
+  table   таблица с заголовками
+
+Коды завершения:
+  0   успешно
+  1   ошибка
+  2   есть просроченные данные (due --fail)
+  3   не найдено
+  4   нет связи с сервером: выведены данные кэша или изменение поставлено в очередь
+  5   требуется вход или разблокировка кэша
+  6   конфликт: имя занято или в очереди есть конфликты
+  64  неверные аргументы или флаги`
+
+var (
+	ErrOutputFormat = errors.New("unknown output format, use plain, json, yaml or table")
+	ErrOverdue      = errors.New("overdue data found")
+	ErrConflicts    = errors.New("outbox has conflicts, see outbox and resolve")
+	ErrNoField      = errors.New("field not found")
+)
+
+// outputFormat - значение флага --output
+type outputFormat string
+
+// Set
+func (f *outputFormat) Set(value string) error {
+	switch value {
+	case outputPlain, outputJSON, outputYAML, outputTable:
+		*f = outputFormat(value)
+		return nil
+	}
+	return ErrOutputFormat
+}
+
+// String
+func (f *outputFormat) String() string { return string(*f) }
+
+// Type
+func (f *outputFormat) Type() string { return "format" }
+
+// result - результат команды
+type result interface {
+	// plain выводит результат текстом
+	plain(w io.Writer)
+}
+
+// tabular - результат, выводимый таблицей. Прочие результаты в формате table выводятся текстом
+type tabular interface {
+	table() (header []string, rows [][]string)
+}
+
+// print выводит результат команды в выбранном формате
+func (h *cliHandler) print(v result) {
+	writeResult(os.Stdout, string(h.output), v)
+}
+
+// stream выводит очередной результат длительной команды: JSON - по строке, YAML - отдельными документами
+func (h *cliHandler) stream(v result) {
+	switch string(h.output) {
+	case outputJSON:
+		data, err := json.Marshal(v)
+		if err == nil {
+			fmt.Fprintln(os.Stdout, string(data))
+		}
+	case outputYAML:
+		fmt.Fprintln(os.Stdout, "---")
+		writeResult(os.Stdout, outputYAML, v)
+	default:
+		writeResult(os.Stdout, outputPlain, v)
+	}
+}
+
+// writeResult выводит результат в формате format
+func writeResult(w io.Writer, format string, v result) {
+	switch format {
+	case outputJSON, outputYAML:
+		data, err := encode(format, v)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return
+		}
+		w.Write(data)
+		return
+	case outputTable:
+		if t, ok := v.(tabular); ok {
+			header, rows := t.table()
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+			for _, row := range rows {
+				fmt.Fprintln(tw, strings.Join(row, "\t"))
+			}
+			tw.Flush()
+			return
+		}
+	}
+	v.plain(w)
+}
+
+// encode кодирует значение в JSON или YAML. YAML получается из JSON, поэтому ключи и их порядок совпадают
+func encode(format string, v any) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if format == outputJSON {
+		return append(data, '\n'), nil
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), enc.Close()
+}
+
+// blockStyle сбрасывает стиль JSON (скобки и кавычки) для вывода в блочном стиле YAML
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// errorResult - ошибка в форматах json и yaml
+type errorResult struct {
+	Error errorView `json:"error"`
+}
+
+// errorView
+type errorView struct {
+	// Вид ошибки: error, usage, overdue, not_found, offline, unauthenticated, conflict
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// report выводит ошибку команды в stderr в выбранном формате и возвращает код завершения
+func (h *cliHandler) report(cmd *cobra.Command, err error) int {
+	if err == nil {
+		return exitOK
+	}
+	// Команда run: ошибку выводит сама запущенная команда
+	var exit exitStatus
+	if errors.As(err, &exit) {
+		return exit.code
+	}
+	code, kind := exitCode(err)
+	w := os.Stderr
+	if cmd != nil && cmd.Annotations[annotationStdoutErrors] != "" {
+		w = os.Stdout
+	}
+	switch string(h.output) {
+	case outputJSON, outputYAML:
+		data, encErr := encode(string(h.output), errorResult{Error: errorView{Code: kind, Message: err.Error()}})
+		if encErr == nil {
+			w.Write(data)
+			return code
+		}
+	}
+	fmt.Fprintln(w, err.Error())
+	if code == exitUsage && cmd != nil {
+		fmt.Fprintf(w, "Run '%s --help' for usage\n", cmd.CommandPath())
+	}
+	return code
+}
+
+// exitCode возвращает код завершения и вид ошибки
+func exitCode(err error) (int, string) {
+	var usageErr usageError
+	switch {
+	case errors.As(err, &usageErr):
+		return exitUsage, "usage"
+	case errors.Is(err, ErrOverdue):
+		return exitOverdue, "overdue"
+	case errors.Is(err, service.ErrNotFound), errors.Is(err, cache.ErrNotFound),
+		errors.Is(err, profile.ErrNotFound), errors.Is(err, ErrNoField), errors.Is(err, dockercred.ErrNotFound):
+		return exitNotFound, "not_found"
+	case errors.Is(err, service.ErrOffline), errors.Is(err, service.ErrQueued):
+		return exitOffline, "offline"
+	case errors.Is(err, service.ErrNoIndexKey), errors.Is(err, service.ErrLocked),
+		errors.Is(err, cache.ErrLocked), errors.Is(err, cache.ErrWrongPassword):
+		return exitUnauthenticated, "unauthenticated"
+	case errors.Is(err, ErrConflicts), errors.Is(err, cache.ErrAlreadyExists), errors.Is(err, service.ErrPendingChanges),
+		errors.Is(err, profile.ErrAlreadyExists):
+		return exitConflict, "conflict"
+	}
+
+	// Ошибки сервера
+	switch status.Code(err) {
+	case codes.NotFound:
+		return exitNotFound, "not_found"
+	case codes.Unavailable:
+		return exitOffline, "offline"
+	case codes.Unauthenticated:
+		return exitUnauthenticated, "unauthenticated"
+	case codes.AlreadyExists:
+		return exitConflict, "conflict"
+	}
+	return exitError, "error"
+}
+
+// exitStatus - код завершения команды, запущенной run
+type exitStatus struct {
+	code int
+}
+
+// Error
+func (e exitStatus) Error() string { return fmt.Sprintf("exit status %d", e.code) }
+
+// usageError - ошибка аргументов или флагов команды
+type usageError struct {
+	err error
+}
+
+// Error
+func (e usageError) Error() string { return e.err.Error() }
+
+// Unwrap
+func (e usageError) Unwrap() error { return e.err }
+
+// usage помечает ошибку как ошибку использования команды
+func usage(err error) error {
+	if err == nil {
+		return nil
+	}
+	return usageError{err: err}
+}
+
+// usageArgs помечает ошибки проверки аргументов команд дерева как ошибки использования
+func usageArgs(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			return usage(validate(cmd, args))
+		}
+	}
+	for _, child := range cmd.Commands() {
+		usageArgs(child)
+	}
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/client/internal/service"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{err: errors.New("boom"), code: exitError},
+		{err: usage(errors.New("bad flag")), code: exitUsage},
+		{err: ErrOverdue, code: exitOverdue},
+		{err: service.ErrNotFound, code: exitNotFound},
+		{err: fmt.Errorf("%w: cvv", ErrNoField), code: exitNotFound},
+		{err: service.ErrQueued, code: exitOffline},
+		{err: service.ErrLocked, code: exitUnauthenticated},
+		{err: fmt.Errorf("%w: 2", ErrConflicts), code: exitConflict},
+		{err: status.Error(codes.AlreadyExists, "exists"), code: exitConflict},
+		{err: status.Error(codes.Unauthenticated, "token"), code: exitUnauthenticated},
+		{err: status.Error(codes.NotFound, "missing"), code: exitNotFound},
+	}
+	for _, tt := range tests {
+		code, _ := exitCode(tt.err)
+		require.Equal(t, tt.code, code, tt.err.Error())
+	}
+}
+
+func TestUnitField(t *testing.T) {
+	unit := model.Unit{Name: "mail", Body: model.UnitBody{
+		Meta: model.UnitMeta{Type: model.UnitTypeLogin, Fields: []model.Field{{Key: "otp", Value: "123", Sensitive: true}}},
+		Data: model.LoginData{Login: "me", Password: "secret"}.Bytes(),
+	}}
+
+	value, err := unitField(unit, "password")
+	require.NoError(t, err)
+	require.Equal(t, "secret", value)
+
+	value, err = unitField(unit, "otp")
+	require.NoError(t, err)
+	require.Equal(t, "123", value)
+
+	_, err = unitField(unit, "cvv")
+	require.ErrorIs(t, err, ErrNoField)
+}
+
+func TestEncodeYAML(t *testing.T) {
+	data, err := encode(outputYAML, newListResult([]string{"infra/db", "123"}, nil))
+	require.NoError(t, err)
+	require.Equal(t, "units:\n  - infra/db\n  - \"123\"\nfolders: []\n", string(data))
+}
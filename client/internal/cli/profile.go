@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/iurnickita/gophkeeper/client/internal/profile"
+	"github.com/spf13/cobra"
+)
+
+// annotationNoService - команда выполняется без подключения к серверу и кэшу
+const annotationNoService = "noservice"
+
+// addProfileCommands добавляет команды управления профилями
+func addProfileCommands(rootCmd *cobra.Command, handler *cliHandler) {
+	var profileCmd = &cobra.Command{
+		Use:         "profile",
+		Short:       "Profile: profile add|use|list|remove",
+		Long:        "Profile управляет профилями: сервер, сертификат, каталог кэша и учетная запись. Токен и кэш каждого профиля хранятся отдельно",
+		Annotations: map[string]string{annotationNoService: "true"},
+	}
+	rootCmd.AddCommand(profileCmd)
+
+	// Add
+	var addCmd = &cobra.Command{
+		Use:   "add",
+		Short: "Add: profile add <name> --server <address> [--ca <file>] [--cache <dir>] [--login <login>]",
+		Long:  "Add добавляет профиль. Первый профиль становится текущим. Формат ввода: profile add <name> --server <address> [--ca <file>] [--cache <dir>] [--login <login>]",
+		Args:  cobra.ExactArgs(1),
+		RunE:  handler.profileAdd,
+	}
+	addCmd.Flags().String("server", "", "адрес сервера host:port")
+	addCmd.Flags().String("ca", "", "сертификат удостоверяющего центра сервера")
+	addCmd.Flags().String("cache", "", "каталог кэша (по умолчанию рядом с файлом конфигурации)")
+	addCmd.Flags().String("login", "", "учетная запись")
+	profileCmd.AddCommand(addCmd)
+
+	// Use
+	var useCmd = &cobra.Command{
+		Use:   "use",
+		Short: "Use: profile use <name>",
+		Long:  "Use делает профиль текущим. Формат ввода: profile use <name>",
+		Args:  cobra.ExactArgs(1),
+		RunE:  handler.profileUse,
+	}
+	profileCmd.AddCommand(useCmd)
+
+	// List
+	var listCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List",
+		Long:    "List выводит профили: текущий отмечен *, имя, сервер, учетная запись и каталог кэша",
+		Args:    cobra.NoArgs,
+		RunE:    handler.profileList,
+	}
+	profileCmd.AddCommand(listCmd)
+
+	// Remove
+	var removeCmd = &cobra.Command{
+		Use:     "remove",
+		Aliases: []string{"rm"},
+		Short:   "Remove: profile remove <name>",
+		Long:    "Remove удаляет профиль из конфигурации. Кэш профиля остается на диске. Формат ввода: profile remove <name>",
+		Args:    cobra.ExactArgs(1),
+		RunE:    handler.profileRemove,
+	}
+	profileCmd.AddCommand(removeCmd)
+}
+
+// Profile add
+func (h *cliHandler) profileAdd(cmd *cobra.Command, args []string) error {
+	p := profile.Profile{Name: args[0]}
+	p.Server, _ = cmd.Flags().GetString("server")
+	p.CA, _ = cmd.Flags().GetString("ca")
+	p.Cache, _ = cmd.Flags().GetString("cache")
+	p.Login, _ = cmd.Flags().GetString("login")
+
+	return h.done(h.profiles.Add(p))
+}
+
+// Profile use
+func (h *cliHandler) profileUse(cmd *cobra.Command, args []string) error {
+	return h.done(h.profiles.Use(args[0]))
+}
+
+// Profile list
+func (h *cliHandler) profileList(cmd *cobra.Command, args []string) error {
+	profiles, current := h.profiles.List()
+	views := profilesResult{}
+	for _, p := range profiles {
+		views = append(views, profileView{
+			Name:    p.Name,
+			Current: p.Name == current,
+			Server:  p.Server,
+			CA:      p.CA,
+			Login:   p.Login,
+			Cache:   p.Cache,
+		})
+	}
+	h.print(views)
+	return nil
+}
+
+// Profile remove
+func (h *cliHandler) profileRemove(cmd *cobra.Command, args []string) error {
+	return h.done(h.profiles.Remove(args[0]))
+}
+
+// rememberLogin запоминает учетную запись профиля после входа
+func (h *cliHandler) rememberLogin(login string) {
+	err := h.profiles.SetLogin(h.profile.Name, login)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+	}
+}
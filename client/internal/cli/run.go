@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/client/internal/secretref"
+	"github.com/iurnickita/gophkeeper/client/internal/service"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
+	"github.com/spf13/cobra"
+)
+
+// addRunCommands добавляет команду запуска процесса с секретами в окружении
+func addRunCommands(rootCmd *cobra.Command, handler *cliHandler) {
+	var runCmd = &cobra.Command{
+		Use:   "run [--env-file <file>]... [--no-masking] [--] <command> [args...]",
+		Short: "Run: run [--env-file <file>]... [--no-masking] [--] <command> [args...]",
+		Long: "Run запускает команду с секретами в переменных среды. Значения вида gk://<name>[#<field>] " +
+			"в текущем окружении и в файлах --env-file (KEY=VALUE, формат .env) заменяются значениями единиц данных; " +
+			"ссылка может быть частью значения: postgres://gk://infra/db#login:gk://infra/db#password@host/db. " +
+			"Поле - как в rd --field, без поля - пароль, номер карты или данные. Пробел в имени - %20. " +
+			"Без связи с сервером значения берутся из кэша. Значения секретов (от 4 символов) в stdout и stderr " +
+			"команды заменяются на *****, если не указан --no-masking. Код завершения - код команды",
+		Args: cobra.MinimumNArgs(1),
+		RunE: handler.run,
+	}
+	runCmd.Flags().StringArray("env-file", nil, "файл переменных среды .env, можно несколько")
+	runCmd.Flags().Bool("no-masking", false, "не маскировать секреты в выводе команды")
+	// Флаги после имени команды относятся к ней
+	runCmd.Flags().SetInterspersed(false)
+	rootCmd.AddCommand(runCmd)
+}
+
+// secretResolver разрешает ссылки gk://. Каждая единица данных читается один раз,
+// без связи с сервером - из кэша. units - прочитанные единицы данных по имени
+type secretResolver struct {
+	service service.Service
+	units   map[string]model.Unit
+	// Полученные значения для маскирования
+	values []string
+	// Значения получены из кэша
+	offline bool
+}
+
+// newSecretResolver
+func newSecretResolver(s service.Service) *secretResolver {
+	return &secretResolver{service: s, units: make(map[string]model.Unit)}
+}
+
+// resolve возвращает значение поля единицы данных по ссылке
+func (r *secretResolver) resolve(ref secretref.Ref) (string, error) {
+	name, err := unitpath.Clean(ref.Name)
+	if err != nil {
+		return "", err
+	}
+	unit, ok := r.units[name]
+	if !ok {
+		unit, err = r.service.Read(name)
+		switch {
+		case errors.Is(err, service.ErrOffline):
+			r.offline = true
+		case err != nil:
+			return "", err
+		}
+		r.units[name] = unit
+	}
+
+	field := ref.Field
+	if field == "" {
+		field = mainField(unit.Body.Meta.Type)
+	}
+	value, err := unitField(unit, field)
+	if err != nil {
+		return "", err
+	}
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	}
+	r.values = append(r.values, s)
+	return s, nil
+}
+
+// mainField возвращает основное поле типа данных
+func mainField(unitType int) string {
+	switch unitType {
+	case model.UnitTypeLogin:
+		return "password"
+	case model.UnitTypeCard:
+		return "number"
+	}
+	return "data"
+}
+
+// runEnv формирует окружение команды: текущее окружение и файлы .env по порядку,
+// ссылки заменяются значениями
+func runEnv(envFiles []string, resolver *secretResolver) ([]string, error) {
+	var vars []secretref.Var
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		vars = append(vars, secretref.Var{Key: key, Value: value})
+	}
+	for _, file := range envFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileVars, err := secretref.ParseEnv(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		vars = append(vars, fileVars...)
+	}
+
+	// Последнее значение переменной замещает предыдущие
+	env := make([]string, 0, len(vars))
+	index := make(map[string]int, len(vars))
+	for _, v := range vars {
+		value, err := secretref.Expand(v.Value, resolver.resolve)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.Key, err)
+		}
+		if i, ok := index[v.Key]; ok {
+			env[i] = v.Key + "=" + value
+			continue
+		}
+		index[v.Key] = len(env)
+		env = append(env, v.Key+"="+value)
+	}
+	return env, nil
+}
+
+// Run
+func (h *cliHandler) run(cmd *cobra.Command, args []string) error {
+	envFiles, _ := cmd.Flags().GetStringArray("env-file")
+	noMasking, _ := cmd.Flags().GetBool("no-masking")
+
+	resolver := newSecretResolver(h.service)
+	env, err := runEnv(envFiles, resolver)
+	if err != nil {
+		return err
+	}
+	if resolver.offline {
+		fmt.Fprintln(os.Stderr, "offline: secrets are taken from the cache")
+	}
+
+	child := exec.Command(args[0], args[1:]...)
+	child.Env = env
+	child.Stdin = os.Stdin
+	child.Stdout, child.Stderr = os.Stdout, os.Stderr
+	var stdout, stderr *secretref.Masker
+	if !noMasking {
+		stdout = secretref.NewMasker(os.Stdout, resolver.values)
+		stderr = secretref.NewMasker(os.Stderr, resolver.values)
+		child.Stdout, child.Stderr = stdout, stderr
+	}
+
+	// SIGTERM и SIGHUP передаются команде. Ctrl+C команда получает от терминала сама
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer func() {
+		signal.Stop(signals)
+		close(signals)
+	}()
+	if err := child.Start(); err != nil {
+		return err
+	}
+	go func() {
+		for sig := range signals {
+			if sig != os.Interrupt {
+				child.Process.Signal(sig)
+			}
+		}
+	}()
+	err = child.Wait()
+	if stdout != nil {
+		stdout.Flush()
+		stderr.Flush()
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			code = 128 + int(ws.Signal())
+		}
+		return exitStatus{code: code}
+	}
+	return err
+}
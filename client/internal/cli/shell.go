@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// historyFileName - файл истории интерактивного режима рядом с файлом конфигурации
+const historyFileName = "shell_history"
+
+// historyLimit - количество сохраняемых команд
+const historyLimit = 500
+
+var (
+	ErrUnterminatedQuote = errors.New("unterminated quote")
+)
+
+// Shell
+func (h *cliHandler) shell(cmd *cobra.Command, args []string) error {
+	// Ctrl+C прерывает команду (watch), а не интерактивный режим
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer func() {
+		signal.Stop(interrupt)
+		close(interrupt)
+	}()
+	go func() {
+		for range interrupt {
+		}
+	}()
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		// Команды из файла или канала
+		for {
+			line, err := h.in.ReadString('\n')
+			if line != "" && !h.execLine(line) {
+				return nil
+			}
+			if err != nil {
+				return nil
+			}
+		}
+	}
+
+	history := newShellHistory(filepath.Join(filepath.Dir(h.cfg.Profile.File), historyFileName))
+	defer history.save()
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, fmt.Sprintf("gophkpr:%s> ", h.profile.Name))
+	t.History = history
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		newLine, newPos, candidates := h.complete(line, pos)
+		if len(candidates) > 1 {
+			// Вывод вариантов после возврата управления терминалу
+			go t.Write([]byte(strings.Join(candidates, "  ") + "\n"))
+		}
+		return newLine, newPos, newLine != line
+	}
+	if width, height, err := term.GetSize(fd); err == nil && width > 0 {
+		t.SetSize(width, height)
+	}
+
+	for {
+		line, err := t.ReadLine()
+		if err != nil {
+			return nil
+		}
+		// Команда выполняется в обычном режиме терминала: вывод, запросы паролей, Ctrl+C
+		term.Restore(fd, state)
+		next := h.execLine(line)
+		state, err = term.MakeRaw(fd)
+		if err != nil || !next {
+			return err
+		}
+	}
+}
+
+// execLine выполняет строку интерактивного режима. Возвращает false для выхода
+func (h *cliHandler) execLine(line string) bool {
+	args, err := splitArgs(line)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return true
+	}
+	if len(args) == 0 {
+		return true
+	}
+	switch args[0] {
+	case "exit", "quit":
+		return false
+	case "shell":
+		fmt.Fprintln(os.Stderr, "already in shell")
+		return true
+	}
+
+	// Сервис и профиль уже выбраны, флаги верхнего уровня не используются.
+	// Ошибка команды выводится, но не завершает интерактивный режим
+	rootCmd := newRootCmd(h)
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Кэш мог заблокироваться по бездействию
+		return h.unlockPrompt(cmd)
+	}
+	rootCmd.SetArgs(args)
+	h.report(rootCmd.ExecuteC())
+	return true
+}
+
+// complete дополняет слово перед курсором: команды и подкоманды, затем имена из кэша.
+// Возвращает новую строку, позицию курсора и варианты дополнения
+func (h *cliHandler) complete(line string, pos int) (string, int, []string) {
+	start := strings.LastIndexAny(line[:pos], " \t") + 1
+	prefix := line[start:pos]
+
+	var words []string
+	before := strings.Fields(line[:start])
+	cmd, rest, err := newRootCmd(h).Find(before)
+	if err == nil && len(rest) == 0 && cmd.HasSubCommands() {
+		if len(before) == 0 {
+			words = append(words, "exit")
+		}
+		for _, c := range cmd.Commands() {
+			words = append(words, c.Name())
+			words = append(words, c.Aliases...)
+		}
+	} else if h.service != nil {
+		list, folders, err := h.service.Cached()
+		if err != nil {
+			return line, pos, nil
+		}
+		words = list
+		for _, folder := range folders {
+			words = append(words, folder+unitpath.Separator)
+		}
+		// Промежуточные папки имен
+		for _, name := range list {
+			for _, folder := range unitpath.Ancestors(name) {
+				words = append(words, folder+unitpath.Separator)
+			}
+		}
+	}
+
+	var candidates []string
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) && !slices.Contains(candidates, word) {
+			candidates = append(candidates, word)
+		}
+	}
+	slices.Sort(candidates)
+	if len(candidates) == 0 {
+		return line, pos, nil
+	}
+
+	completion := candidates[0]
+	if len(candidates) > 1 {
+		completion = commonPrefix(candidates)
+	} else if !strings.HasSuffix(completion, unitpath.Separator) {
+		completion += " "
+	}
+	return line[:start] + completion + line[pos:], start + len(completion), candidates
+}
+
+// commonPrefix возвращает общее начало строк
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// splitArgs разбивает строку на аргументы с учетом кавычек и экранирования
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	var quote rune
+	inArg, escaped := false, false
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, ErrUnterminatedQuote
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// hasSecret проверяет, что в строке передаются секреты: пароль входа, данные или чувствительные поля записи
+func hasSecret(line string) bool {
+	args, err := splitArgs(line)
+	if err != nil {
+		// Строка не выполнялась, но может содержать секрет
+		return true
+	}
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "rg", "register", "lg", "login":
+		return len(args) > 1
+	case "wr", "write":
+		// Флаги разбираются по определению команды: часть флагов без значения
+		cmd, rest, err := newRootCmd(&cliHandler{}).Find(args)
+		if err != nil || cmd.ParseFlags(rest) != nil {
+			return true
+		}
+		secretFields, _ := cmd.Flags().GetStringArray("secret-field")
+		return len(secretFields) > 0 || cmd.Flags().NArg() > 2
+	}
+	return false
+}
+
+// shellHistory - история команд без секретов. Сохраняется в файл при выходе
+type shellHistory struct {
+	file    string
+	entries []string
+}
+
+// newShellHistory читает историю из файла
+func newShellHistory(file string) *shellHistory {
+	history := &shellHistory{file: file}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return history
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		history.Add(line)
+	}
+	return history
+}
+
+// Add добавляет команду, если она не содержит секретов
+func (s *shellHistory) Add(entry string) {
+	if strings.TrimSpace(entry) == "" || hasSecret(entry) {
+		return
+	}
+	if len(s.entries) > 0 && s.entries[len(s.entries)-1] == entry {
+		return
+	}
+	s.entries = append(s.entries, entry)
+	if len(s.entries) > historyLimit {
+		s.entries = s.entries[len(s.entries)-historyLimit:]
+	}
+}
+
+// Len
+func (s *shellHistory) Len() int {
+	return len(s.entries)
+}
+
+// At возвращает команду, 0 - последняя
+func (s *shellHistory) At(idx int) string {
+	return s.entries[len(s.entries)-1-idx]
+}
+
+// save записывает историю
+func (s *shellHistory) save() {
+	if len(s.entries) == 0 {
+		return
+	}
+	_ = os.MkdirAll(filepath.Dir(s.file), 0700)
+	_ = os.WriteFile(s.file, []byte(strings.Join(s.entries, "\n")+"\n"), 0600)
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		args []string
+		err  error
+	}{
+		{line: "  ls  -r infra ", args: []string{"ls", "-r", "infra"}},
+		{line: `wr "my notes/todo list" 1 'a "b" c'`, args: []string{"wr", "my notes/todo list", "1", `a "b" c`}},
+		{line: `rd infra\ db`, args: []string{"rd", "infra db"}},
+		{line: `wr x 1 ""`, args: []string{"wr", "x", "1", ""}},
+		{line: `rd "infra`, err: ErrUnterminatedQuote},
+	}
+	for _, tt := range tests {
+		args, err := splitArgs(tt.line)
+		require.ErrorIs(t, err, tt.err, tt.line)
+		require.Equal(t, tt.args, args, tt.line)
+	}
+}
+
+func TestHasSecret(t *testing.T) {
+	tests := []struct {
+		line   string
+		secret bool
+	}{
+		{line: "lg", secret: false},
+		{line: "lg hunter2", secret: true},
+		{line: "register bob hunter2", secret: true},
+		{line: "wr infra/db 1", secret: false},
+		{line: "wr infra/db 1 -d 'main db' --tag prod", secret: false},
+		{line: "wr infra/db 1 hunter2", secret: true},
+		{line: "wr -t prod infra/db 1 --desc=db hunter2", secret: true},
+		{line: "wr infra/db 1 -s pin=1234", secret: true},
+		{line: "wr infra/db 1 --secret-field=pin=1234", secret: true},
+		{line: "wr --generate infra/db 1 --length 32", secret: false},
+		{line: "wr --capitalize infra/db 1 hunter2", secret: true},
+		{line: "rd infra/db", secret: false},
+		{line: `lg "hunter2`, secret: true},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.secret, hasSecret(tt.line), tt.line)
+	}
+}
@@ -0,0 +1,632 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/client/internal/audit"
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/contract/settings"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
+)
+
+// Итог изменения
+const (
+	statusOK = "ok"
+	// Нет связи с сервером, изменение будет отправлено при синхронизации
+	statusQueued = "queued"
+)
+
+// statusResult - итог команды без данных
+type statusResult struct {
+	Status string `json:"status"`
+	// Количество обработанных единиц данных (purge, reindex)
+	Count *int64 `json:"count,omitempty"`
+}
+
+// plain
+func (r statusResult) plain(w io.Writer) {
+	switch {
+	case r.Status != statusOK:
+		// Причина выводится в stderr
+	case r.Count != nil:
+		fmt.Fprintf(w, "OK: %d\n", *r.Count)
+	default:
+		fmt.Fprintln(w, "OK")
+	}
+}
+
+// countResult
+func countResult(count int64) statusResult {
+	return statusResult{Status: statusOK, Count: &count}
+}
+
+// valueResult - значение одного поля единицы данных
+type valueResult struct {
+	// string или []byte (двоичные данные, в JSON - base64)
+	Value any `json:"value"`
+}
+
+// plain выводит значение без оформления. Двоичные данные выводятся как есть
+func (r valueResult) plain(w io.Writer) {
+	if data, ok := r.Value.([]byte); ok {
+		w.Write(data)
+		return
+	}
+	fmt.Fprintln(w, r.Value)
+}
+
+// listResult - имена единиц данных и папок
+type listResult struct {
+	Units   []string `json:"units"`
+	Folders []string `json:"folders"`
+}
+
+// newListResult. Пустые списки выводятся как [], а не null
+func newListResult(list []string, folders []string) listResult {
+	if list == nil {
+		list = []string{}
+	}
+	if folders == nil {
+		folders = []string{}
+	}
+	return listResult{Units: list, Folders: folders}
+}
+
+// plain
+func (r listResult) plain(w io.Writer) {
+	for _, f := range r.Folders {
+		fmt.Fprintln(w, f+unitpath.Separator)
+	}
+	for _, unitName := range r.Units {
+		fmt.Fprintln(w, unitName)
+	}
+}
+
+// table
+func (r listResult) table() ([]string, [][]string) {
+	var rows [][]string
+	for _, f := range r.Folders {
+		rows = append(rows, []string{"folder", f + unitpath.Separator})
+	}
+	for _, unitName := range r.Units {
+		rows = append(rows, []string{"unit", unitName})
+	}
+	return []string{"kind", "name"}, rows
+}
+
+// treeResult - содержимое папки со всеми вложенными папками
+type treeResult struct {
+	Folder string `json:"folder"`
+	listResult
+}
+
+// plain выводит дерево
+func (r treeResult) plain(w io.Writer) {
+	if r.Folder == "" {
+		fmt.Fprintln(w, ".")
+	} else {
+		fmt.Fprintln(w, r.Folder+unitpath.Separator)
+	}
+	printTree(w, r.Units, r.Folders, r.Folder, "")
+}
+
+// printTree выводит содержимое папки с отступами
+func printTree(w io.Writer, list []string, folders []string, folder string, indent string) {
+	childUnits, childFolders := unitpath.Filter(list, folders, folder, false)
+	count := len(childFolders) + len(childUnits)
+	for i, name := range append(childFolders, childUnits...) {
+		branch, next := "├── ", "│   "
+		if i == count-1 {
+			branch, next = "└── ", "    "
+		}
+		if i < len(childFolders) {
+			fmt.Fprintln(w, indent+branch+unitpath.Base(name)+unitpath.Separator)
+			printTree(w, list, folders, name, indent+next)
+			continue
+		}
+		fmt.Fprintln(w, indent+branch+unitpath.Base(name))
+	}
+}
+
+// unitView - единица данных. Полезные данные выводятся по типу: login, card, text или binary
+type unitView struct {
+	Name        string           `json:"name"`
+	Type        string           `json:"type"`
+	Description string           `json:"description,omitempty"`
+	Tags        []string         `json:"tags,omitempty"`
+	Fields      []model.Field    `json:"fields,omitempty"`
+	Login       *model.LoginData `json:"login,omitempty"`
+	Card        *model.CardData  `json:"card,omitempty"`
+	Text        *string          `json:"text,omitempty"`
+	Binary      *[]byte          `json:"binary,omitempty"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty"`
+	RotateEvery string           `json:"rotate_every,omitempty"`
+	CreatedAt   *time.Time       `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time       `json:"updated_at,omitempty"`
+
+	unitType int
+}
+
+// newUnitView. Без withData - только метаданные без пользовательских полей
+func newUnitView(unit model.Unit, withData bool) unitView {
+	meta := unit.Body.Meta
+	view := unitView{
+		Name:        unit.Name,
+		Type:        model.TypeName(meta.Type),
+		Description: meta.Description,
+		Tags:        meta.Tags,
+		ExpiresAt:   timeRef(meta.ExpiresAt),
+		CreatedAt:   timeRef(meta.CreatedAt),
+		UpdatedAt:   timeRef(meta.UpdatedAt),
+		unitType:    meta.Type,
+	}
+	if meta.RotateEvery > 0 {
+		view.RotateEvery = formatPeriod(meta.RotateEvery)
+	}
+	if !withData {
+		return view
+	}
+
+	view.Fields = meta.Fields
+	switch meta.Type {
+	case model.UnitTypeLogin:
+		login := model.ParseLogin(unit.Body.Data)
+		view.Login = &login
+	case model.UnitTypeCard:
+		card := model.ParseCard(unit.Body.Data)
+		view.Card = &card
+	case model.UnitTypeText:
+		text := string(unit.Body.Data)
+		view.Text = &text
+	default:
+		data := unit.Body.Data
+		view.Binary = &data
+	}
+	return view
+}
+
+// rows возвращает поля единицы данных, кроме текста
+func (v unitView) rows() [][]string {
+	var rows [][]string
+	row := func(key string, value string) {
+		if value != "" {
+			rows = append(rows, []string{key, value})
+		}
+	}
+	row("name", v.Name)
+	row("type", v.Type)
+	if v.Login != nil {
+		row("login", v.Login.Login)
+		row("password", v.Login.Password)
+		row("url", v.Login.URL)
+	}
+	if v.Card != nil {
+		row("number", v.Card.Number)
+		row("holder", v.Card.Holder)
+		row("expiry", v.Card.Expiry)
+		row("cvv", v.Card.CVV)
+	}
+	if v.Binary != nil {
+		row("size", fmt.Sprintf("%d bytes", len(*v.Binary)))
+	}
+	row("description", v.Description)
+	row("tags", strings.Join(v.Tags, ","))
+	for _, field := range v.Fields {
+		row(field.Key, field.Value)
+	}
+	if v.ExpiresAt != nil {
+		row("expires", v.ExpiresAt.Local().Format(time.DateOnly))
+	}
+	row("rotate every", v.RotateEvery)
+	if v.CreatedAt != nil {
+		row("created", v.CreatedAt.Local().Format(time.DateTime))
+	}
+	if v.UpdatedAt != nil {
+		row("updated", v.UpdatedAt.Local().Format(time.DateTime))
+	}
+	return rows
+}
+
+// plain выводит поля, затем текст
+func (v unitView) plain(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	for _, row := range v.rows() {
+		fmt.Fprintf(tw, "%s:\t%s\n", row[0], row[1])
+	}
+	tw.Flush()
+	if v.Text != nil {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, *v.Text)
+	}
+}
+
+// table
+func (v unitView) table() ([]string, [][]string) {
+	rows := v.rows()
+	if v.Text != nil {
+		rows = append(rows, []string{"text", strconv.Quote(*v.Text)})
+	}
+	return []string{"field", "value"}, rows
+}
+
+// unitsResult - найденные единицы данных
+type unitsResult []unitView
+
+// plain
+func (r unitsResult) plain(w io.Writer) {
+	for _, unit := range r {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", unit.Name, unit.unitType, strings.Join(unit.Tags, ","), unit.Description)
+	}
+}
+
+// table
+func (r unitsResult) table() ([]string, [][]string) {
+	var rows [][]string
+	for _, unit := range r {
+		rows = append(rows, []string{unit.Name, unit.Type, strings.Join(unit.Tags, ","), unit.Description})
+	}
+	return []string{"name", "type", "tags", "description"}, rows
+}
+
+// unitField возвращает значение поля единицы данных: поля данных по типу,
+// data, name, type, description, tags или пользовательское поле
+func unitField(unit model.Unit, key string) (any, error) {
+	meta := unit.Body.Meta
+	switch key {
+	case "name":
+		return unit.Name, nil
+	case "type":
+		return model.TypeName(meta.Type), nil
+	case "description":
+		return meta.Description, nil
+	case "tags":
+		return strings.Join(meta.Tags, ","), nil
+	case "data", "text":
+		if meta.Type == model.UnitTypeBinary {
+			return unit.Body.Data, nil
+		}
+		return string(unit.Body.Data), nil
+	}
+
+	switch meta.Type {
+	case model.UnitTypeLogin:
+		login := model.ParseLogin(unit.Body.Data)
+		switch key {
+		case "login":
+			return login.Login, nil
+		case "password":
+			return login.Password, nil
+		case "url":
+			return login.URL, nil
+		}
+	case model.UnitTypeCard:
+		card := model.ParseCard(unit.Body.Data)
+		switch key {
+		case "number":
+			return card.Number, nil
+		case "holder":
+			return card.Holder, nil
+		case "expiry":
+			return card.Expiry, nil
+		case "cvv":
+			return card.CVV, nil
+		}
+	}
+
+	for _, field := range meta.Fields {
+		if field.Key == key {
+			return field.Value, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoField, key)
+}
+
+// Состояния напоминания
+const (
+	stateDue     = "due"
+	stateOverdue = "overdue"
+)
+
+// dueView - напоминание о сроке действия или смене
+type dueView struct {
+	Name   string    `json:"name"`
+	Type   string    `json:"type"`
+	DueAt  time.Time `json:"due_at"`
+	Reason string    `json:"reason"`
+	State  string    `json:"state"`
+}
+
+// dueResult
+type dueResult []dueView
+
+// plain
+func (r dueResult) plain(w io.Writer) {
+	for _, item := range r {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.DueAt.Local().Format(time.DateOnly), item.State, item.Reason, item.Name)
+	}
+}
+
+// table
+func (r dueResult) table() ([]string, [][]string) {
+	var rows [][]string
+	for _, item := range r {
+		rows = append(rows, []string{item.DueAt.Local().Format(time.DateOnly), item.State, item.Reason, item.Name})
+	}
+	return []string{"due", "state", "reason", "name"}, rows
+}
+
+// auditResult - отчет о качестве секретов
+type auditResult struct {
+	audit.Report
+}
+
+// rows возвращает найденные проблемы: вид, имя и подробности
+func (r auditResult) rows() [][]string {
+	var rows [][]string
+	for _, weak := range r.Weak {
+		rows = append(rows, []string{"weak", weak.Name, fmt.Sprintf("score %d/4, crack time %s", weak.Score, weak.CrackTime)})
+	}
+	for _, reused := range r.Reused {
+		rows = append(rows, []string{"reused", strings.Join(reused.Names, ", "), fmt.Sprintf("same password in %d units", len(reused.Names))})
+	}
+	for _, old := range r.Old {
+		rows = append(rows, []string{"old", old.Name, fmt.Sprintf("not changed for %d days", old.Days)})
+	}
+	for _, card := range r.Expiring {
+		state := "expires"
+		if card.Expired {
+			state = "expired"
+		}
+		rows = append(rows, []string{state, card.Name, card.ExpiresAt.Local().Format(time.DateOnly)})
+	}
+	return rows
+}
+
+// plain выводит проблемы и итог
+func (r auditResult) plain(w io.Writer) {
+	for _, row := range r.rows() {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	fmt.Fprintf(w, "checked %d logins, %d cards: %d issues\n", r.Logins, r.Cards, r.Issues())
+}
+
+// table
+func (r auditResult) table() ([]string, [][]string) {
+	return []string{"issue", "name", "details"}, r.rows()
+}
+
+// breachesResult - пароли, найденные в утечках
+type breachesResult struct {
+	audit.BreachReport
+}
+
+// plain выводит найденные пароли и итог
+func (r breachesResult) plain(w io.Writer) {
+	for _, breach := range r.Breached {
+		fmt.Fprintf(w, "%s\tseen %d times\n", breach.Name, breach.Count)
+	}
+	fmt.Fprintf(w, "checked %d logins: %d breached\n", r.Logins, len(r.Breached))
+}
+
+// table
+func (r breachesResult) table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(r.Breached))
+	for _, breach := range r.Breached {
+		rows = append(rows, []string{breach.Name, strconv.Itoa(breach.Count)})
+	}
+	return []string{"name", "count"}, rows
+}
+
+// syncResult - итог синхронизации
+type syncResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Deleted   int `json:"deleted"`
+	Replayed  int `json:"replayed"`
+	Conflicts int `json:"conflicts"`
+}
+
+// plain
+func (r syncResult) plain(w io.Writer) {
+	fmt.Fprintf(w, "OK: created %d, updated %d, deleted %d\n", r.Created, r.Updated, r.Deleted)
+	if r.Replayed > 0 {
+		fmt.Fprintf(w, "outbox: sent %d\n", r.Replayed)
+	}
+}
+
+// Состояния изменения в очереди
+const (
+	opPending  = "pending"
+	opConflict = "conflict"
+)
+
+// opView - изменение в очереди
+type opView struct {
+	ID       int64     `json:"id"`
+	QueuedAt time.Time `json:"queued_at"`
+	Kind     string    `json:"kind"`
+	Name     string    `json:"name"`
+	State    string    `json:"state"`
+	// Конфликт: единица данных удалена или изменена на сервере
+	ServerDeleted   bool       `json:"server_deleted,omitempty"`
+	ServerUpdatedAt *time.Time `json:"server_updated_at,omitempty"`
+}
+
+// newOpView
+func newOpView(op model.Op) opView {
+	view := opView{ID: op.ID, QueuedAt: op.QueuedAt, Kind: op.Kind, Name: op.Unit.Name, State: opPending}
+	if op.Conflict != nil {
+		view.State = opConflict
+		view.ServerDeleted = op.Conflict.ServerDeleted
+		if !op.Conflict.ServerDeleted {
+			view.ServerUpdatedAt = timeRef(op.Conflict.ServerUpdatedAt)
+		}
+	}
+	return view
+}
+
+// describe описывает состояние изменения
+func (v opView) describe() string {
+	switch {
+	case v.State == opPending:
+		return opPending
+	case v.ServerDeleted:
+		return "conflict: deleted on server"
+	case v.ServerUpdatedAt != nil:
+		return "conflict: updated on server " + v.ServerUpdatedAt.Local().Format(time.DateTime)
+	default:
+		return opConflict
+	}
+}
+
+// outboxResult
+type outboxResult []opView
+
+// plain
+func (r outboxResult) plain(w io.Writer) {
+	for _, op := range r {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", op.ID, op.QueuedAt.Local().Format(time.DateTime), op.Kind, op.Name, op.describe())
+	}
+}
+
+// table
+func (r outboxResult) table() ([]string, [][]string) {
+	var rows [][]string
+	for _, op := range r {
+		rows = append(rows, []string{strconv.FormatInt(op.ID, 10), op.QueuedAt.Local().Format(time.DateTime), op.Kind, op.Name, op.describe()})
+	}
+	return []string{"id", "queued", "kind", "name", "state"}, rows
+}
+
+// trashView - единица данных в корзине
+type trashView struct {
+	ID        int64     `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+	Type      string    `json:"type"`
+	Name      string    `json:"name"`
+
+	unitType int
+}
+
+// trashResult
+type trashResult []trashView
+
+// plain
+func (r trashResult) plain(w io.Writer) {
+	for _, item := range r {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\n", item.ID, item.DeletedAt.Local().Format(time.DateTime), item.unitType, item.Name)
+	}
+}
+
+// table
+func (r trashResult) table() ([]string, [][]string) {
+	var rows [][]string
+	for _, item := range r {
+		rows = append(rows, []string{strconv.FormatInt(item.ID, 10), item.DeletedAt.Local().Format(time.DateTime), item.Type, item.Name})
+	}
+	return []string{"id", "deleted", "type", "name"}, rows
+}
+
+// profileView - профиль
+type profileView struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+	Server  string `json:"server"`
+	CA      string `json:"ca,omitempty"`
+	Login   string `json:"login,omitempty"`
+	Cache   string `json:"cache,omitempty"`
+}
+
+// profilesResult
+type profilesResult []profileView
+
+// plain. Текущий профиль отмечен *
+func (r profilesResult) plain(w io.Writer) {
+	for _, p := range r {
+		mark := " "
+		if p.Current {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\n", mark, p.Name, p.Server, p.Login, p.Cache)
+	}
+}
+
+// table
+func (r profilesResult) table() ([]string, [][]string) {
+	var rows [][]string
+	for _, p := range r {
+		mark := ""
+		if p.Current {
+			mark = "*"
+		}
+		rows = append(rows, []string{mark, p.Name, p.Server, p.Login, p.Cache})
+	}
+	return []string{"current", "name", "server", "login", "cache"}, rows
+}
+
+// configResult - действующая конфигурация
+type configResult struct {
+	File     string           `json:"file,omitempty"`
+	Settings []settings.Value `json:"settings"`
+
+	set *settings.Set
+}
+
+// plain
+func (r configResult) plain(w io.Writer) {
+	r.set.Show(w)
+}
+
+// table
+func (r configResult) table() ([]string, [][]string) {
+	var rows [][]string
+	for _, value := range r.Settings {
+		rows = append(rows, []string{value.Key, value.Value, value.Source})
+	}
+	return []string{"key", "value", "source"}, rows
+}
+
+// eventView - событие наблюдения за изменениями
+type eventView struct {
+	Time  time.Time `json:"time"`
+	Kind  string    `json:"kind"`
+	Name  string    `json:"name,omitempty"`
+	Error string    `json:"error,omitempty"`
+	Retry string    `json:"retry,omitempty"`
+}
+
+// plain
+func (v eventView) plain(w io.Writer) {
+	switch {
+	case v.Kind == model.EventSubscribed.String():
+		fmt.Fprintln(w, v.Kind)
+	default:
+		name := v.Name
+		if name == "" {
+			name = "?"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", v.Time.Format(time.DateTime), v.Kind, name)
+	}
+}
+
+// timeRef возвращает ссылку на время; нулевое время не выводится
+func timeRef(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// formatPeriod выводит период в формате parsePeriod: целые дни - 90d
+func formatPeriod(period time.Duration) string {
+	day := 24 * time.Hour
+	if period%day == 0 {
+		return strconv.Itoa(int(period/day)) + "d"
+	}
+	return period.String()
+}
@@ -1,11 +1,19 @@
-// Пакет config. Конфигурация с помощью флагов/переменных среды/значений по умолчанию
+// Пакет config. Конфигурация из файла/переменных среды/флагов/значений по умолчанию
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"time"
+
+	auditConfig "github.com/iurnickita/gophkeeper/client/internal/audit/config"
 	cacheConfig "github.com/iurnickita/gophkeeper/client/internal/cache/config"
 	grpcClientConig "github.com/iurnickita/gophkeeper/client/internal/grpc_client/client/config"
 	loggerConfig "github.com/iurnickita/gophkeeper/client/internal/logger/config"
+	profileConfig "github.com/iurnickita/gophkeeper/client/internal/profile/config"
 	serviceConfig "github.com/iurnickita/gophkeeper/client/internal/service/config"
+	"github.com/iurnickita/gophkeeper/contract/settings"
+	"github.com/spf13/pflag"
 )
 
 // Config - общая конфигурация
@@ -14,16 +22,63 @@ type Config struct {
 	GRPCClient grpcClientConig.Config
 	Service    serviceConfig.Config
 	Cache      cacheConfig.Config
+	Profile    profileConfig.Config
+	Audit      auditConfig.Config
 }
 
-// GetConfig собирает конфигурацию сервиса
-func GetConfig() Config {
-	cfg := Config{}
+// GetConfig задает значения по умолчанию и регистрирует параметры во флагах.
+// Файл и переменные среды применяются вызовом Load после разбора флагов.
+// Файл конфигурации общий с профилями
+func GetConfig(flags *pflag.FlagSet) (*Config, *settings.Set) {
+	cfg := &Config{}
 
-	// По умолчанию на момент разработки
-	cfg.Cache.FileRepo = "data/"
+	// По умолчанию
+	cfg.Profile.DefaultServer = "localhost:3200"
+	cfg.Profile.DefaultCA = "cert/ca-cert.pem"
+	cfg.Profile.DefaultCache = "data/"
+	cfg.Profile.File = "gophkeeper.yaml"
+	if dir, err := os.UserConfigDir(); err == nil {
+		cfg.Profile.File = filepath.Join(dir, "gophkeeper", "config.yaml")
+	}
 	cfg.Cache.ValidPeriod = 1
-	cfg.Logger.LogLevel = "debug"
+	cfg.Cache.LockTimeout = 15 * time.Minute
+	cfg.Logger.LogLevel = "info"
+
+	flags.StringVar(&cfg.Profile.File, "config", cfg.Profile.File, "файл конфигурации (GOPHKEEPER_CONFIG)")
+	set := settings.New(flags,
+		settings.Setting{Key: "server.address", Env: "GOPHKEEPER_SERVER",
+			Value: settings.String(&cfg.Profile.DefaultServer)},
+		settings.Setting{Key: "server.ca", Env: "GOPHKEEPER_CA",
+			Value: settings.String(&cfg.Profile.DefaultCA)},
+		settings.Setting{Key: "cache.dir", Env: "GOPHKEEPER_CACHE_DIR",
+			Value: settings.String(&cfg.Profile.DefaultCache)},
+		settings.Setting{Key: "cache.lock_timeout", Env: "GOPHKEEPER_LOCK_TIMEOUT", Flag: "lock-timeout", Usage: "блокировка кэша по бездействию, 0 - без блокировки",
+			Value: settings.Duration(&cfg.Cache.LockTimeout)},
+		settings.Setting{Key: "cache.key_file", Env: "GOPHKEEPER_KEY_FILE", Flag: "key-file", Usage: "файл ключа кэша вместо мастер-пароля",
+			Value: settings.String(&cfg.Cache.KeyFile)},
+		settings.Setting{Key: "cache.master_password", Env: "GOPHKEEPER_MASTER_PASSWORD",
+			Secret: true, Value: settings.String(&cfg.Cache.MasterPassword)},
+		settings.Setting{Key: "audit.pwned_file", Env: "GOPHKEEPER_PWNED_FILE",
+			Value: settings.String(&cfg.Audit.PwnedFile)},
+		settings.Setting{Key: "logger.level", Env: "GOPHKEEPER_LOG_LEVEL", Flag: "log-level", Usage: "уровень журнала",
+			Value: settings.String(&cfg.Logger.LogLevel)},
+	)
+	return cfg, set
+}
+
+// Load применяет файл конфигурации и переменные среды.
+// Файл может отсутствовать: он создается при добавлении профиля
+func (cfg *Config) Load(set *settings.Set, flags *pflag.FlagSet) error {
+	if envconfig := os.Getenv("GOPHKEEPER_CONFIG"); envconfig != "" && !flags.Changed("config") {
+		cfg.Profile.File = envconfig
+	}
+	if err := set.Load(cfg.Profile.File, false); err != nil {
+		return err
+	}
 
-	return cfg
+	// Профиль по умолчанию
+	cfg.GRPCClient.Address = cfg.Profile.DefaultServer
+	cfg.GRPCClient.CAFile = cfg.Profile.DefaultCA
+	cfg.Cache.FileRepo = cfg.Profile.DefaultCache
+	return nil
 }
@@ -0,0 +1,200 @@
+// Пакет dockercred. Помощник учетных данных docker (docker credential helper):
+// протокол store/get/erase/list через stdin/stdout. Данные входа реестра хранятся
+// в docker/<host>[/<path>], адрес реестра - в поле URL
+package dockercred
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
+)
+
+// Prefix - папка данных входа реестров docker
+const Prefix = "docker"
+
+// Действия помощника
+const (
+	ActionStore = "store"
+	ActionGet   = "get"
+	ActionErase = "erase"
+	ActionList  = "list"
+)
+
+var (
+	// Текст ошибки проверяется docker
+	ErrNotFound  = errors.New("credentials not found in native keychain")
+	ErrServerURL = errors.New("no valid server URL")
+	ErrAction    = errors.New("unknown docker credential action")
+)
+
+// Credentials - учетные данные реестра в протоколе docker.
+// Для токена доступа Username - "<token>"
+type Credentials struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// Name возвращает имя данных входа реестра: хост в нижнем регистре и путь,
+// схема не учитывается
+func Name(serverURL string) (string, error) {
+	raw := strings.TrimSpace(serverURL)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("%w: %q", ErrServerURL, serverURL)
+	}
+	segments := []string{Prefix, strings.ToLower(u.Host)}
+	for _, segment := range strings.Split(u.Path, unitpath.Separator) {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	name, err := unitpath.Clean(strings.Join(segments, unitpath.Separator))
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrServerURL, serverURL)
+	}
+	return name, nil
+}
+
+// Serve выполняет действие помощника: запрос читается из in, ответ выводится в out
+func Serve(vault model.Vault, action string, in io.Reader, out io.Writer) error {
+	switch action {
+	case ActionStore:
+		var c Credentials
+		if err := json.NewDecoder(in).Decode(&c); err != nil {
+			return fmt.Errorf("invalid docker credential input: %w", err)
+		}
+		return Store(vault, c)
+	case ActionGet:
+		serverURL, err := readServerURL(in)
+		if err != nil {
+			return err
+		}
+		c, err := Get(vault, serverURL)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(out).Encode(c)
+	case ActionErase:
+		serverURL, err := readServerURL(in)
+		if err != nil {
+			return err
+		}
+		return Erase(vault, serverURL)
+	case ActionList:
+		list, err := List(vault)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(out).Encode(list)
+	}
+	return fmt.Errorf("%w: %q", ErrAction, action)
+}
+
+// readServerURL читает адрес реестра: весь ввод без пробелов по краям
+func readServerURL(in io.Reader) (string, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Store сохраняет учетные данные реестра. Данные без изменений не записываются
+func Store(vault model.Vault, c Credentials) error {
+	name, err := Name(c.ServerURL)
+	if err != nil {
+		return err
+	}
+	unit, ok, err := find(vault, name)
+	if err != nil {
+		return err
+	}
+	login := model.LoginData{Login: c.Username, Password: c.Secret, URL: c.ServerURL}
+	if ok {
+		if model.ParseLogin(unit.Body.Data) == login {
+			return nil
+		}
+	} else {
+		unit = model.Unit{Name: name, Body: model.UnitBody{Meta: model.UnitMeta{Type: model.UnitTypeLogin}}}
+	}
+	unit.Body.Data = login.Bytes()
+	return vault.Write(unit)
+}
+
+// Get возвращает учетные данные реестра
+func Get(vault model.Vault, serverURL string) (Credentials, error) {
+	name, err := Name(serverURL)
+	if err != nil {
+		return Credentials{}, err
+	}
+	unit, ok, err := find(vault, name)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if !ok {
+		return Credentials{}, ErrNotFound
+	}
+	login := model.ParseLogin(unit.Body.Data)
+	return Credentials{ServerURL: serverURL, Username: login.Login, Secret: login.Password}, nil
+}
+
+// Erase удаляет учетные данные реестра
+func Erase(vault model.Vault, serverURL string) error {
+	name, err := Name(serverURL)
+	if err != nil {
+		return err
+	}
+	_, ok, err := find(vault, name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return vault.Delete(name)
+}
+
+// List возвращает пользователей по адресам реестров
+func List(vault model.Vault) (map[string]string, error) {
+	units, err := vault.Units()
+	if err != nil {
+		return nil, err
+	}
+	list := make(map[string]string)
+	for _, unit := range units {
+		if unit.Body.Meta.Type != model.UnitTypeLogin || !unitpath.InFolder(unit.Name, Prefix, true) {
+			continue
+		}
+		login := model.ParseLogin(unit.Body.Data)
+		serverURL := login.URL
+		if serverURL == "" {
+			serverURL = strings.TrimPrefix(unit.Name, unitpath.Prefix(Prefix))
+		}
+		list[serverURL] = login.Login
+	}
+	return list, nil
+}
+
+// find находит данные входа по имени
+func find(vault model.Vault, name string) (model.Unit, bool, error) {
+	units, err := vault.Units()
+	if err != nil {
+		return model.Unit{}, false, err
+	}
+	for _, unit := range units {
+		if unit.Name == name && unit.Body.Meta.Type == model.UnitTypeLogin {
+			return unit, true, nil
+		}
+	}
+	return model.Unit{}, false, nil
+}
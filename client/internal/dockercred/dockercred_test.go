@@ -0,0 +1,81 @@
+package dockercred
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/client/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// serve выполняет действие как docker
+func serve(vault model.Vault, action string, input string) (string, error) {
+	var out bytes.Buffer
+	err := Serve(vault, action, bytes.NewBufferString(input), &out)
+	return out.String(), err
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		serverURL string
+		want      string
+		err       error
+	}{
+		{serverURL: "https://index.docker.io/v1/", want: "docker/index.docker.io/v1"},
+		{serverURL: "Registry.Example.com:5000", want: "docker/registry.example.com:5000"},
+		{serverURL: "http://registry.example.com//team/", want: "docker/registry.example.com/team"},
+		{serverURL: "", err: ErrServerURL},
+		{serverURL: "https://registry.example.com/a/../b", err: ErrServerURL},
+	}
+	for _, test := range tests {
+		t.Run(test.serverURL, func(t *testing.T) {
+			name, err := Name(test.serverURL)
+			if test.err != nil {
+				require.ErrorIs(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.want, name)
+		})
+	}
+}
+
+func TestServe(t *testing.T) {
+	vault := &testutil.MemVault{Stored: []model.Unit{
+		{Name: "work/registry", Body: model.UnitBody{Meta: model.UnitMeta{Type: model.UnitTypeLogin}, Data: model.LoginData{Login: "bob"}.Bytes()}},
+	}}
+
+	// Новые данные входа
+	out, err := serve(vault, ActionStore, `{"ServerURL":"https://index.docker.io/v1/","Username":"alice","Secret":"dckr_1"}`)
+	require.NoError(t, err)
+	require.Empty(t, out)
+	require.Equal(t, "docker/index.docker.io/v1", vault.Stored[1].Name)
+	require.Equal(t, model.LoginData{Login: "alice", Password: "dckr_1", URL: "https://index.docker.io/v1/"}, model.ParseLogin(vault.Stored[1].Body.Data))
+
+	// Повторное сохранение тех же данных не записывается
+	_, err = serve(vault, ActionStore, `{"ServerURL":"https://index.docker.io/v1/","Username":"alice","Secret":"dckr_1"}`)
+	require.NoError(t, err)
+	require.Equal(t, 1, vault.Writes)
+
+	out, err = serve(vault, ActionGet, "index.docker.io/v1\n")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"ServerURL":"index.docker.io/v1","Username":"alice","Secret":"dckr_1"}`, out)
+
+	out, err = serve(vault, ActionList, "")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"https://index.docker.io/v1/":"alice"}`, out)
+
+	_, err = serve(vault, ActionGet, "registry.example.com\n")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Equal(t, "credentials not found in native keychain", err.Error())
+
+	_, err = serve(vault, ActionErase, "https://index.docker.io/v1/\n")
+	require.NoError(t, err)
+	require.Len(t, vault.Stored, 1)
+	_, err = serve(vault, ActionErase, "https://index.docker.io/v1/\n")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = serve(vault, "version", "")
+	require.ErrorIs(t, err, ErrAction)
+}
@@ -0,0 +1,181 @@
+// Пакет export. Зашифрованный архив всех данных пользователя для переноса и восстановления.
+// Архив: заголовок (сигнатура, версия, параметры Argon2id, соль, nonce) и AES-256-GCM
+// от сжатого JSON с единицами данных. Заголовок входит в проверку подлинности,
+// для каждой единицы данных дополнительно хранится SHA-256
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"golang.org/x/crypto/argon2"
+)
+
+// Version - версия формата архива
+const Version = 1
+
+// magic - сигнатура архива
+var magic = []byte("GKEXPORT")
+
+// Параметры Argon2id по умолчанию
+const (
+	DefaultTime    = 3
+	DefaultMemory  = 64 * 1024
+	DefaultThreads = 4
+)
+
+const (
+	saltLen   = 16
+	nonceLen  = 12
+	headerLen = 8 + 1 + 4 + 4 + 1 + saltLen + nonceLen
+	// Ограничения параметров из заголовка: не более 1 ГиБ памяти и 100 проходов
+	maxMemory = 1024 * 1024
+	maxTime   = 100
+)
+
+var (
+	ErrFormat    = errors.New("not a gophkeeper export")
+	ErrVersion   = errors.New("unsupported export version")
+	ErrPassword  = errors.New("wrong export password or archive is damaged")
+	ErrIntegrity = errors.New("export integrity check failed")
+)
+
+// Archive - содержимое архива
+type Archive struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Профиль и сервер, с которых выполнена выгрузка
+	Login  string  `json:"login,omitempty"`
+	Server string  `json:"server,omitempty"`
+	Units  []Entry `json:"units"`
+}
+
+// Entry - единица данных архива с контрольной суммой
+type Entry struct {
+	Unit model.Unit `json:"unit"`
+	// SHA-256 данных
+	SHA256 string `json:"sha256"`
+}
+
+// NewArchive формирует архив из единиц данных
+func NewArchive(units []model.Unit) Archive {
+	archive := Archive{Version: Version, CreatedAt: time.Now(), Units: make([]Entry, 0, len(units))}
+	for _, unit := range units {
+		archive.Units = append(archive.Units, Entry{Unit: unit, SHA256: checksum(unit.Body.Data)})
+	}
+	return archive
+}
+
+// UnitList возвращает единицы данных архива
+func (a Archive) UnitList() []model.Unit {
+	units := make([]model.Unit, 0, len(a.Units))
+	for _, entry := range a.Units {
+		units = append(units, entry.Unit)
+	}
+	return units
+}
+
+// Write шифрует архив паролем
+func Write(w io.Writer, archive Archive, password string) error {
+	var payload bytes.Buffer
+	gz := gzip.NewWriter(&payload)
+	if err := json.NewEncoder(gz).Encode(archive); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	header := make([]byte, 0, headerLen)
+	header = append(header, magic...)
+	header = append(header, Version)
+	header = binary.BigEndian.AppendUint32(header, DefaultTime)
+	header = binary.BigEndian.AppendUint32(header, DefaultMemory)
+	header = append(header, DefaultThreads)
+	random := make([]byte, saltLen+nonceLen)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+	header = append(header, random...)
+
+	aead, err := newAEAD(password, header)
+	if err != nil {
+		return err
+	}
+	nonce := header[headerLen-nonceLen:]
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err = w.Write(aead.Seal(nil, nonce, payload.Bytes(), header))
+	return err
+}
+
+// Read расшифровывает архив и проверяет контрольные суммы
+func Read(data []byte, password string) (Archive, error) {
+	if len(data) < headerLen || !bytes.HasPrefix(data, magic) {
+		return Archive{}, ErrFormat
+	}
+	if version := data[len(magic)]; version != Version {
+		return Archive{}, fmt.Errorf("%w %d", ErrVersion, version)
+	}
+	header := data[:headerLen]
+	aead, err := newAEAD(password, header)
+	if err != nil {
+		return Archive{}, err
+	}
+	payload, err := aead.Open(nil, header[headerLen-nonceLen:], data[headerLen:], header)
+	if err != nil {
+		return Archive{}, ErrPassword
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return Archive{}, err
+	}
+	var archive Archive
+	if err := json.NewDecoder(gz).Decode(&archive); err != nil {
+		return Archive{}, err
+	}
+	for _, entry := range archive.Units {
+		if checksum(entry.Unit.Body.Data) != entry.SHA256 {
+			return Archive{}, fmt.Errorf("%w: %s", ErrIntegrity, entry.Unit.Name)
+		}
+	}
+	return archive, nil
+}
+
+// newAEAD формирует ключ по паролю и параметрам заголовка
+func newAEAD(password string, header []byte) (cipher.AEAD, error) {
+	params := header[len(magic)+1:]
+	passes := binary.BigEndian.Uint32(params[0:4])
+	memory := binary.BigEndian.Uint32(params[4:8])
+	threads := params[8]
+	salt := params[9 : 9+saltLen]
+	if passes == 0 || passes > maxTime || memory > maxMemory || threads == 0 {
+		return nil, ErrFormat
+	}
+	key := argon2.IDKey([]byte(password), salt, passes, memory, threads, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// checksum
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
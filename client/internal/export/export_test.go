@@ -0,0 +1,47 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRead(t *testing.T) {
+	units := []model.Unit{
+		{Name: "mail/google", Body: model.UnitBody{
+			Meta: model.UnitMeta{Type: model.UnitTypeLogin, Tags: []string{"work"}, Fields: []model.Field{{Key: "pin", Value: "1234", Sensitive: true}}},
+			Data: model.LoginData{Login: "bob", Password: "secret"}.Bytes(),
+		}},
+		{Name: "keys/id_rsa", Body: model.UnitBody{Meta: model.UnitMeta{Type: model.UnitTypeBinary}, Data: []byte{0, 1, 2, 255}}},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, NewArchive(units), "pass"))
+	data := buf.Bytes()
+	require.NotContains(t, string(data), "secret")
+
+	archive, err := Read(data, "pass")
+	require.NoError(t, err)
+	require.Equal(t, Version, archive.Version)
+	require.Equal(t, units, archive.UnitList())
+
+	_, err = Read(data, "wrong")
+	require.ErrorIs(t, err, ErrPassword)
+
+	// Заголовок входит в проверку подлинности
+	tampered := bytes.Clone(data)
+	tampered[len(magic)+4]++
+	_, err = Read(tampered, "pass")
+	require.ErrorIs(t, err, ErrPassword)
+
+	_, err = Read([]byte("not an export"), "pass")
+	require.ErrorIs(t, err, ErrFormat)
+
+	broken := NewArchive(units)
+	broken.Units[1].SHA256 = checksum([]byte("other"))
+	buf.Reset()
+	require.NoError(t, Write(&buf, broken, "pass"))
+	_, err = Read(buf.Bytes(), "pass")
+	require.ErrorIs(t, err, ErrIntegrity)
+}
@@ -0,0 +1,247 @@
+// Пакет generator. Генерация паролей и парольных фраз на основе crypto/rand
+package generator
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Классы символов пароля
+const (
+	Lower   = "abcdefghijklmnopqrstuvwxyz"
+	Upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	Digits  = "0123456789"
+	Symbols = "!#$%&()*+,-./:;<=>?@[]^_{}~"
+)
+
+// Ambiguous - символы, которые легко спутать при чтении и вводе
+const Ambiguous = "Il1O0o"
+
+// Значения по умолчанию
+const (
+	DefaultLength = 20
+	DefaultWords  = 6
+)
+
+var (
+	ErrNoClasses = errors.New("no character classes left, enable more classes or exclude fewer characters")
+	ErrLength    = errors.New("password is too short to contain every class")
+	ErrWords     = errors.New("passphrase needs at least one word")
+)
+
+// wordlistFile - список слов EFF (eff_large_wordlist.txt, CC BY 3.0):
+// https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases
+//
+//go:embed eff_large_wordlist.txt
+var wordlistFile string
+
+// wordlist разбирает список слов: номер броска кубиков и слово
+var wordlist = sync.OnceValue(func() []string {
+	var words []string
+	for _, line := range strings.Split(wordlistFile, "\n") {
+		if _, word, found := strings.Cut(line, "\t"); found {
+			words = append(words, strings.TrimSpace(word))
+		}
+	}
+	return words
+})
+
+// Result - сгенерированное значение и его энтропия в битах
+type Result struct {
+	Value   string
+	Entropy float64
+}
+
+// PasswordOptions - параметры пароля
+type PasswordOptions struct {
+	Length  int
+	Lower   bool
+	Upper   bool
+	Digits  bool
+	Symbols bool
+	// Исключить неоднозначные символы (Ambiguous)
+	NoAmbiguous bool
+	// Исключить перечисленные символы
+	Exclude string
+	// Хотя бы один символ каждого выбранного класса
+	RequireEach bool
+}
+
+// DefaultPasswordOptions - все классы символов, каждый класс обязателен
+func DefaultPasswordOptions() PasswordOptions {
+	return PasswordOptions{
+		Length:      DefaultLength,
+		Lower:       true,
+		Upper:       true,
+		Digits:      true,
+		Symbols:     true,
+		RequireEach: true,
+	}
+}
+
+// PassphraseOptions - параметры парольной фразы
+type PassphraseOptions struct {
+	Words     int
+	Separator string
+	// Слова с заглавной буквы. На энтропию не влияет
+	Capitalize bool
+}
+
+// DefaultPassphraseOptions
+func DefaultPassphraseOptions() PassphraseOptions {
+	return PassphraseOptions{Words: DefaultWords, Separator: "-"}
+}
+
+// Password генерирует пароль. Символы выбираются равновероятно; при RequireEach
+// пароли без какого-либо класса отбрасываются, поэтому распределение по допустимым
+// паролям остается равномерным
+func Password(opts PasswordOptions) (Result, error) {
+	classes := opts.classes()
+	if len(classes) == 0 {
+		return Result{}, ErrNoClasses
+	}
+	if opts.Length < 1 || (opts.RequireEach && opts.Length < len(classes)) {
+		return Result{}, ErrLength
+	}
+	alphabet := []rune(strings.Join(classes, ""))
+
+	password := make([]rune, opts.Length)
+	for {
+		for i := range password {
+			n, err := randInt(len(alphabet))
+			if err != nil {
+				return Result{}, err
+			}
+			password[i] = alphabet[n]
+		}
+		if !opts.RequireEach || containsEach(string(password), classes) {
+			break
+		}
+	}
+	return Result{Value: string(password), Entropy: passwordEntropy(classes, opts.Length, opts.RequireEach)}, nil
+}
+
+// Passphrase генерирует парольную фразу из слов списка EFF
+func Passphrase(opts PassphraseOptions) (Result, error) {
+	if opts.Words < 1 {
+		return Result{}, ErrWords
+	}
+	list := wordlist()
+	words := make([]string, opts.Words)
+	for i := range words {
+		n, err := randInt(len(list))
+		if err != nil {
+			return Result{}, err
+		}
+		words[i] = list[n]
+		if opts.Capitalize {
+			runes := []rune(words[i])
+			runes[0] = unicode.ToUpper(runes[0])
+			words[i] = string(runes)
+		}
+	}
+	return Result{
+		Value:   strings.Join(words, opts.Separator),
+		Entropy: float64(opts.Words) * math.Log2(float64(len(list))),
+	}, nil
+}
+
+// classes возвращает выбранные классы без исключенных символов. Пустые классы не учитываются
+func (opts PasswordOptions) classes() []string {
+	exclude := opts.Exclude
+	if opts.NoAmbiguous {
+		exclude += Ambiguous
+	}
+	var classes []string
+	for _, class := range []struct {
+		enabled bool
+		chars   string
+	}{
+		{opts.Lower, Lower},
+		{opts.Upper, Upper},
+		{opts.Digits, Digits},
+		{opts.Symbols, Symbols},
+	} {
+		if !class.enabled {
+			continue
+		}
+		chars := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(exclude, r) {
+				return -1
+			}
+			return r
+		}, class.chars)
+		if chars != "" {
+			classes = append(classes, chars)
+		}
+	}
+	return classes
+}
+
+// containsEach проверяет, что в пароле есть символ каждого класса
+func containsEach(password string, classes []string) bool {
+	for _, class := range classes {
+		if !strings.ContainsAny(password, class) {
+			return false
+		}
+	}
+	return true
+}
+
+// passwordEntropy - log2 количества допустимых паролей. С requireEach количество
+// считается по формуле включений-исключений по отсутствующим классам
+func passwordEntropy(classes []string, length int, requireEach bool) float64 {
+	total := 0
+	for _, class := range classes {
+		total += len([]rune(class))
+	}
+	if !requireEach {
+		return float64(length) * math.Log2(float64(total))
+	}
+
+	count := new(big.Int)
+	power := new(big.Int)
+	exp := big.NewInt(int64(length))
+	for mask := 0; mask < 1<<len(classes); mask++ {
+		size, missing := total, 0
+		for i, class := range classes {
+			if mask&(1<<i) != 0 {
+				size -= len([]rune(class))
+				missing++
+			}
+		}
+		power.Exp(big.NewInt(int64(size)), exp, nil)
+		if missing%2 == 0 {
+			count.Add(count, power)
+		} else {
+			count.Sub(count, power)
+		}
+	}
+	return log2(count)
+}
+
+// log2 для больших чисел
+func log2(n *big.Int) float64 {
+	if n.Sign() <= 0 {
+		return 0
+	}
+	mant := new(big.Float).SetInt(n)
+	exp := mant.MantExp(mant)
+	m, _ := mant.Float64()
+	return float64(exp) + math.Log2(m)
+}
+
+// randInt возвращает равномерно распределенное число [0, n)
+func randInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPassword(t *testing.T) {
+	opts := DefaultPasswordOptions()
+	opts.Length = 8
+	opts.NoAmbiguous = true
+	opts.Exclude = "#$"
+	for range 100 {
+		result, err := Password(opts)
+		require.NoError(t, err)
+		require.Len(t, result.Value, 8)
+		require.False(t, strings.ContainsAny(result.Value, Ambiguous+"#$"), result.Value)
+		for _, class := range []string{Lower, Upper, Digits, Symbols} {
+			require.True(t, strings.ContainsAny(result.Value, class), result.Value)
+		}
+	}
+
+	_, err := Password(PasswordOptions{Length: 10})
+	require.ErrorIs(t, err, ErrNoClasses)
+	_, err = Password(PasswordOptions{Length: 1, Lower: true, Digits: true, RequireEach: true})
+	require.ErrorIs(t, err, ErrLength)
+}
+
+func TestPasswordEntropy(t *testing.T) {
+	// Без обязательных классов: length * log2(alphabet)
+	require.InDelta(t, 10*math.Log2(36), passwordEntropy([]string{Lower, Digits}, 10, false), 1e-9)
+	// 2 символа, обязательны буква и цифра: 2 * 26 * 10 = 520 паролей
+	require.InDelta(t, math.Log2(520), passwordEntropy([]string{Lower, Digits}, 2, true), 1e-9)
+}
+
+func TestPassphrase(t *testing.T) {
+	require.Len(t, wordlist(), 7776)
+
+	result, err := Passphrase(PassphraseOptions{Words: 5, Separator: " ", Capitalize: true})
+	require.NoError(t, err)
+	words := strings.Split(result.Value, " ")
+	require.Len(t, words, 5)
+	for _, word := range words {
+		require.Equal(t, strings.ToUpper(word[:1]), word[:1])
+	}
+	require.InDelta(t, 5*math.Log2(7776), result.Entropy, 1e-9)
+
+	_, err = Passphrase(PassphraseOptions{})
+	require.ErrorIs(t, err, ErrWords)
+}
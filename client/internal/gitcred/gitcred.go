@@ -0,0 +1,257 @@
+// Пакет gitcred. Помощник учетных данных git (git credential helper): протокол
+// key=value через stdin/stdout и сопоставление запросов с данными входа.
+// Данные входа находятся по имени git/<host>/<username> или git/<host>
+// либо по полю URL (протокол, хост и, при credential.useHttpPath, путь)
+package gitcred
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
+)
+
+// Prefix - папка данных входа git
+const Prefix = "git"
+
+// Действия помощника
+const (
+	ActionGet   = "get"
+	ActionStore = "store"
+	ActionErase = "erase"
+)
+
+var (
+	ErrProtocol = errors.New("invalid git credential input")
+)
+
+// Credential - описание учетных данных в протоколе git
+type Credential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+	// Срок действия пароля (password_expiry_utc)
+	PasswordExpiry time.Time
+}
+
+// ReadCredential читает строки key=value до пустой строки или конца ввода.
+// Неизвестные ключи пропускаются
+func ReadCredential(r io.Reader) (Credential, error) {
+	var c Credential
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return Credential{}, fmt.Errorf("%w: %q", ErrProtocol, line)
+		}
+		switch key {
+		case "protocol":
+			c.Protocol = value
+		case "host":
+			c.Host = value
+		case "path":
+			c.Path = value
+		case "username":
+			c.Username = value
+		case "password":
+			c.Password = value
+		case "password_expiry_utc":
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return Credential{}, fmt.Errorf("%w: %q", ErrProtocol, line)
+			}
+			c.PasswordExpiry = time.Unix(seconds, 0)
+		case "url":
+			u, err := url.Parse(value)
+			if err != nil {
+				return Credential{}, fmt.Errorf("%w: %q", ErrProtocol, line)
+			}
+			c.Protocol, c.Host, c.Path = u.Scheme, u.Host, strings.TrimPrefix(u.Path, "/")
+			if u.User != nil {
+				c.Username = u.User.Username()
+			}
+		}
+	}
+	return c, scanner.Err()
+}
+
+// WriteTo выводит ответ на запрос get
+func (c Credential) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, kv := range [][2]string{{"protocol", c.Protocol}, {"host", c.Host}, {"username", c.Username}, {"password", c.Password}} {
+		if kv[1] != "" {
+			fmt.Fprintf(&b, "%s=%s\n", kv[0], kv[1])
+		}
+	}
+	if !c.PasswordExpiry.IsZero() {
+		fmt.Fprintf(&b, "password_expiry_utc=%d\n", c.PasswordExpiry.Unix())
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// name возвращает имя данных входа по соглашению
+func (c Credential) name() string {
+	if c.Username == "" {
+		return unitpath.Join(Prefix, c.Host)
+	}
+	return unitpath.Join(Prefix, c.Host+unitpath.Separator+strings.ReplaceAll(c.Username, unitpath.Separator, "-"))
+}
+
+// url возвращает URL для поля данных входа
+func (c Credential) url() string {
+	u := url.URL{Scheme: c.Protocol, Host: c.Host}
+	if c.Path != "" {
+		u.Path = "/" + c.Path
+	}
+	return u.String()
+}
+
+// Serve выполняет действие помощника: запрос читается из in, ответ get выводится в out.
+// Неизвестные действия пропускаются, как требует протокол
+func Serve(vault model.Vault, action string, in io.Reader, out io.Writer) error {
+	c, err := ReadCredential(in)
+	if err != nil {
+		return err
+	}
+	switch action {
+	case ActionGet:
+		found, ok, err := Get(vault, c)
+		if err != nil || !ok {
+			return err
+		}
+		_, err = found.WriteTo(out)
+		return err
+	case ActionStore:
+		return Store(vault, c)
+	case ActionErase:
+		return Erase(vault, c)
+	}
+	return nil
+}
+
+// Get находит учетные данные для запроса
+func Get(vault model.Vault, c Credential) (Credential, bool, error) {
+	unit, ok, err := find(vault, c)
+	if err != nil || !ok {
+		return Credential{}, false, err
+	}
+	login := model.ParseLogin(unit.Body.Data)
+	found := Credential{
+		Protocol:       c.Protocol,
+		Host:           c.Host,
+		Username:       login.Login,
+		Password:       login.Password,
+		PasswordExpiry: unit.Body.Meta.ExpiresAt,
+	}
+	if found.Username == "" {
+		found.Username = c.Username
+	}
+	return found, true, nil
+}
+
+// Store сохраняет учетные данные, принятые сервером git. Данные без изменений не записываются
+func Store(vault model.Vault, c Credential) error {
+	if c.Protocol == "" || c.Host == "" || c.Username == "" || c.Password == "" {
+		return nil
+	}
+	unit, ok, err := find(vault, c)
+	if err != nil {
+		return err
+	}
+	login := model.LoginData{Login: c.Username, Password: c.Password, URL: c.url()}
+	if ok {
+		current := model.ParseLogin(unit.Body.Data)
+		if current.Login == c.Username && current.Password == c.Password &&
+			unit.Body.Meta.ExpiresAt.Equal(c.PasswordExpiry) {
+			return nil
+		}
+		if current.URL != "" {
+			login.URL = current.URL
+		}
+	} else {
+		unit = model.Unit{Name: c.name(), Body: model.UnitBody{Meta: model.UnitMeta{Type: model.UnitTypeLogin}}}
+	}
+	unit.Body.Data = login.Bytes()
+	unit.Body.Meta.ExpiresAt = c.PasswordExpiry
+	return vault.Write(unit)
+}
+
+// Erase удаляет учетные данные, отклоненные сервером git.
+// Если указан пароль, удаляются только данные с этим паролем
+func Erase(vault model.Vault, c Credential) error {
+	unit, ok, err := find(vault, c)
+	if err != nil || !ok {
+		return err
+	}
+	if c.Password != "" && model.ParseLogin(unit.Body.Data).Password != c.Password {
+		return nil
+	}
+	return vault.Delete(unit.Name)
+}
+
+// find находит данные входа: сначала по имени, затем по полю URL
+func find(vault model.Vault, c Credential) (model.Unit, bool, error) {
+	if c.Protocol == "" || c.Host == "" {
+		return model.Unit{}, false, nil
+	}
+	units, err := vault.Units()
+	if err != nil {
+		return model.Unit{}, false, err
+	}
+	units = slices.DeleteFunc(units, func(unit model.Unit) bool {
+		if unit.Body.Meta.Type != model.UnitTypeLogin {
+			return true
+		}
+		login := model.ParseLogin(unit.Body.Data).Login
+		return c.Username != "" && login != "" && login != c.Username
+	})
+	slices.SortFunc(units, func(a, b model.Unit) int { return strings.Compare(a.Name, b.Name) })
+
+	names := []string{c.name()}
+	if c.Username != "" {
+		names = append(names, Credential{Host: c.Host}.name())
+	}
+	for _, name := range names {
+		if i := slices.IndexFunc(units, func(unit model.Unit) bool { return unit.Name == name }); i >= 0 {
+			return units[i], true, nil
+		}
+	}
+	for _, unit := range units {
+		if matchURL(model.ParseLogin(unit.Body.Data).URL, c) {
+			return unit, true, nil
+		}
+	}
+	return model.Unit{}, false, nil
+}
+
+// matchURL сравнивает поле URL с запросом. Путь сравнивается, если указан в обоих
+func matchURL(raw string, c Credential) bool {
+	if raw == "" {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if u.Scheme != c.Protocol || !strings.EqualFold(u.Host, c.Host) {
+		return false
+	}
+	path := strings.Trim(u.Path, "/")
+	want := strings.Trim(c.Path, "/")
+	return path == "" || want == "" || strings.TrimSuffix(path, ".git") == strings.TrimSuffix(want, ".git")
+}
@@ -0,0 +1,125 @@
+package gitcred
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/client/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// loginUnit
+func loginUnit(name string, login model.LoginData) model.Unit {
+	return model.Unit{Name: name, Body: model.UnitBody{Meta: model.UnitMeta{Type: model.UnitTypeLogin}, Data: login.Bytes()}}
+}
+
+// helper выполняет действие как git: запрос пишется в канал ввода, который
+// остается открытым до получения ответа, ответ читается из канала вывода
+func helper(t *testing.T, vault model.Vault, action string, input string) string {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	errc := make(chan error, 1)
+	go func() {
+		err := Serve(vault, action, inR, outW)
+		outW.Close()
+		errc <- err
+	}()
+	_, err := io.WriteString(inW, input+"\n")
+	require.NoError(t, err)
+	output, err := io.ReadAll(outR)
+	require.NoError(t, err)
+	require.NoError(t, <-errc)
+	inW.Close()
+	return string(output)
+}
+
+func TestServe_Get(t *testing.T) {
+	vault := &testutil.MemVault{Stored: []model.Unit{
+		loginUnit("git/github.com/alice", model.LoginData{Login: "alice", Password: "ghp_alice"}),
+		loginUnit("git/github.com", model.LoginData{Password: "ghp_default"}),
+		loginUnit("work/gitlab", model.LoginData{Login: "bob", Password: "glpat_bob", URL: "https://gitlab.example.com:8443/team/app.git"}),
+		loginUnit("work/notes", model.LoginData{Login: "bob", Password: "other"}),
+	}}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "by name with username",
+			input: "protocol=https\nhost=github.com\nusername=alice\n",
+			want:  "protocol=https\nhost=github.com\nusername=alice\npassword=ghp_alice\n",
+		},
+		{
+			name:  "by host name",
+			input: "protocol=https\nhost=github.com\nusername=carol\n",
+			want:  "protocol=https\nhost=github.com\nusername=carol\npassword=ghp_default\n",
+		},
+		{
+			name:  "by url field",
+			input: "protocol=https\nhost=gitlab.example.com:8443\n",
+			want:  "protocol=https\nhost=gitlab.example.com:8443\nusername=bob\npassword=glpat_bob\n",
+		},
+		{
+			name:  "by url with path",
+			input: "url=https://bob@gitlab.example.com:8443/team/app\ncapability[]=authtype\n",
+			want:  "protocol=https\nhost=gitlab.example.com:8443\nusername=bob\npassword=glpat_bob\n",
+		},
+		{name: "other path", input: "protocol=https\nhost=gitlab.example.com:8443\npath=team/lib.git\n"},
+		{name: "other protocol", input: "protocol=http\nhost=gitlab.example.com:8443\n"},
+		{name: "other user", input: "protocol=https\nhost=gitlab.example.com:8443\nusername=alice\n"},
+		{name: "unknown host", input: "protocol=https\nhost=example.org\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, helper(t, vault, ActionGet, test.input))
+		})
+	}
+}
+
+func TestServe_StoreErase(t *testing.T) {
+	vault := &testutil.MemVault{}
+	expiry := time.Unix(1893456000, 0)
+	request := "protocol=https\nhost=github.com\nusername=alice\npassword=ghp_1\npassword_expiry_utc=1893456000\n"
+
+	// Новые данные входа
+	require.Empty(t, helper(t, vault, ActionStore, request))
+	require.Len(t, vault.Stored, 1)
+	require.Equal(t, "git/github.com/alice", vault.Stored[0].Name)
+	require.Equal(t, model.LoginData{Login: "alice", Password: "ghp_1", URL: "https://github.com"}, model.ParseLogin(vault.Stored[0].Body.Data))
+	require.True(t, expiry.Equal(vault.Stored[0].Body.Meta.ExpiresAt))
+
+	// Повторное сохранение тех же данных не записывается
+	helper(t, vault, ActionStore, request)
+	require.Equal(t, 1, vault.Writes)
+
+	require.Equal(t, "protocol=https\nhost=github.com\nusername=alice\npassword=ghp_1\npassword_expiry_utc=1893456000\n",
+		helper(t, vault, ActionGet, "protocol=https\nhost=github.com\n"))
+
+	// Новый пароль
+	helper(t, vault, ActionStore, "protocol=https\nhost=github.com\nusername=alice\npassword=ghp_2\n")
+	require.Len(t, vault.Stored, 1)
+	require.Equal(t, "ghp_2", model.ParseLogin(vault.Stored[0].Body.Data).Password)
+
+	// Отклонен старый пароль: данные не удаляются
+	helper(t, vault, ActionErase, "protocol=https\nhost=github.com\nusername=alice\npassword=ghp_1\n")
+	require.Len(t, vault.Stored, 1)
+	helper(t, vault, ActionErase, "protocol=https\nhost=github.com\nusername=alice\npassword=ghp_2\n")
+	require.Empty(t, vault.Stored)
+
+	// Неизвестное действие пропускается
+	require.Empty(t, helper(t, vault, "capabilities", request))
+}
+
+func TestReadCredential(t *testing.T) {
+	c, err := ReadCredential(bytes.NewBufferString("protocol=https\r\nhost=github.com\r\npassword=a=b\n\nusername=ignored\n"))
+	require.NoError(t, err)
+	require.Equal(t, Credential{Protocol: "https", Host: "github.com", Password: "a=b"}, c)
+
+	_, err = ReadCredential(bytes.NewBufferString("protocol\n"))
+	require.ErrorIs(t, err, ErrProtocol)
+}
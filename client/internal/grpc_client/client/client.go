@@ -3,13 +3,18 @@ package grpcclient
 
 import (
 	"context"
+	"time"
 
+	"github.com/iurnickita/gophkeeper/client/internal/blindindex"
 	"github.com/iurnickita/gophkeeper/client/internal/grpc_client/client/config"
 	"github.com/iurnickita/gophkeeper/client/internal/model"
 	gophTLS "github.com/iurnickita/gophkeeper/client/internal/tls"
 	pb "github.com/iurnickita/gophkeeper/contract/proto"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Client
@@ -42,47 +47,125 @@ func (c Client) Authenticate(login string, password string) (string, error) {
 	return resp.Token, nil
 }
 
-// List возвращает список единиц данных, хранящихся на сервере
-func (c Client) List(token string) ([]string, error) {
+// List возвращает список единиц данных и папок, хранящихся на сервере в папке folder.
+// Имена расшифровываются ключом индекса; данные без слепого индекса пропускаются
+func (c Client) List(token string, idx blindindex.Indexer, folder string, recursive bool) ([]string, []string, error) {
 	ctx := c.createContext(token)
 
 	// Запрос
-	resp, err := c.gophkeeper.List(ctx, &pb.Empty{})
+	resp, err := c.gophkeeper.List(ctx, &pb.ListRequest{Prefix: idx.FolderToken(folder), Recursive: recursive})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	// Расшифровка имен
+	units, err := decodeNames(idx, resp.Units)
+	if err != nil {
+		return nil, nil, err
+	}
+	folders, err := decodeNames(idx, resp.Folders)
+	if err != nil {
+		return nil, nil, err
+	}
+	return units, folders, nil
+}
+
+// Legacy возвращает единицы данных и папки, записанные до введения слепого индекса
+// (имя хранится на сервере в открытом виде)
+func (c Client) Legacy(token string) ([]string, []string, error) {
+	ctx := c.createContext(token)
+
+	// Запрос
+	resp, err := c.gophkeeper.List(ctx, &pb.ListRequest{Recursive: true})
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return resp.Unitname, nil
+	return legacyNames(resp.Units), legacyNames(resp.Folders), nil
 }
 
 // Read
-func (c Client) Read(token string, unitname string) (model.Unit, error) {
+func (c Client) Read(token string, idx blindindex.Indexer, unitname string) (model.Unit, error) {
 	ctx := c.createContext(token)
 
 	// Запрос
-	resp, err := c.gophkeeper.Read(ctx, &pb.ReadRequest{Unitname: unitname})
+	resp, err := c.gophkeeper.Read(ctx, &pb.ReadRequest{Unitname: idx.NameToken(unitname)})
 	if err != nil {
 		return model.Unit{}, err
 	}
 
 	// Маппинг
+	unit := unitFromRead(unitname, resp)
+	unit.Body.Meta.Tags, err = idx.DecryptTags(resp.Tags)
+	if err != nil {
+		return model.Unit{}, err
+	}
+
+	return unit, nil
+}
+
+// unitFromRead преобразует ответ на чтение. Теги возвращаются как хранятся на сервере
+func unitFromRead(unitname string, resp *pb.ReadResponse) model.Unit {
 	var unit model.Unit
 	unit.Name = unitname
 	unit.Body.Meta.Type = int(resp.Unittype)
+	unit.Body.Meta.Description = resp.Description
+	unit.Body.Meta.Tags = resp.Tags
+	unit.Body.Meta.Fields = fieldsFromProto(resp.Fields)
+	unit.Body.Meta.CreatedAt = resp.CreatedAt.AsTime()
+	unit.Body.Meta.UpdatedAt = resp.UpdatedAt.AsTime()
+	if resp.ExpiresAt != nil {
+		unit.Body.Meta.ExpiresAt = resp.ExpiresAt.AsTime()
+	}
+	unit.Body.Meta.RotateEvery = resp.RotateEvery.AsDuration()
 	unit.Body.Data = resp.Unitdata
+	return unit
+}
+
+// Write записывает единицу данных. Возвращает ее с датами создания и изменения на сервере
+func (c Client) Write(token string, idx blindindex.Indexer, unit model.Unit) (model.Unit, error) {
+	ctx := c.createContext(token)
+
+	// Слепой индекс
+	index, err := unitIndex(idx, unit.Name, unit.Body.Meta.Tags)
+	if err != nil {
+		return model.Unit{}, err
+	}
+	tags, err := idx.EncryptTags(unit.Body.Meta.Tags)
+	if err != nil {
+		return model.Unit{}, err
+	}
+
+	// Запрос
+	req := &pb.WriteRequest{Unitname: idx.NameToken(unit.Name),
+		Unittype:    int32(unit.Body.Meta.Type),
+		Unitdata:    unit.Body.Data,
+		Description: unit.Body.Meta.Description,
+		Tags:        tags,
+		Fields:      fieldsToProto(unit.Body.Meta.Fields),
+		Index:       index}
+	if !unit.Body.Meta.ExpiresAt.IsZero() {
+		req.ExpiresAt = timestamppb.New(unit.Body.Meta.ExpiresAt)
+	}
+	if unit.Body.Meta.RotateEvery > 0 {
+		req.RotateEvery = durationpb.New(unit.Body.Meta.RotateEvery)
+	}
+	resp, err := c.gophkeeper.Write(ctx, req)
+	if err != nil {
+		return model.Unit{}, err
+	}
+	unit.Body.Meta.CreatedAt = resp.CreatedAt.AsTime()
+	unit.Body.Meta.UpdatedAt = resp.UpdatedAt.AsTime()
 
 	return unit, nil
 }
 
-// Write
-func (c Client) Write(token string, unit model.Unit) error {
+// Delete
+func (c Client) Delete(token string, idx blindindex.Indexer, unitname string) error {
 	ctx := c.createContext(token)
 
 	// Запрос
-	req := &pb.WriteRequest{Unitname: unit.Name,
-		Unittype: int32(unit.Body.Meta.Type),
-		Unitdata: unit.Body.Data}
-	_, err := c.gophkeeper.Write(ctx, req)
+	_, err := c.gophkeeper.Delete(ctx, &pb.DeleteRequest{Unitname: idx.NameToken(unitname)})
 	if err != nil {
 		return err
 	}
@@ -90,19 +173,404 @@ func (c Client) Write(token string, unit model.Unit) error {
 	return nil
 }
 
-// Delete
-func (c Client) Delete(token string, unitname string) error {
+// ListTrash возвращает содержимое корзины.
+// Имена данных без слепого индекса возвращаются как есть
+func (c Client) ListTrash(token string, idx blindindex.Indexer) ([]model.TrashItem, error) {
 	ctx := c.createContext(token)
 
 	// Запрос
-	_, err := c.gophkeeper.Delete(ctx, &pb.DeleteRequest{Unitname: unitname})
+	resp, err := c.gophkeeper.ListTrash(ctx, &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Маппинг
+	var items []model.TrashItem
+	for _, pbItem := range resp.Items {
+		item := model.TrashItem{
+			ID:        pbItem.Id,
+			Name:      pbItem.Name.GetToken(),
+			Type:      int(pbItem.Unittype),
+			DeletedAt: pbItem.DeletedAt.AsTime()}
+		if enc := pbItem.Name.GetEnc(); len(enc) > 0 {
+			item.Name, err = idx.Decrypt(enc)
+			if err != nil {
+				return nil, err
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Restore возвращает единицу данных из корзины
+func (c Client) Restore(token string, id int64) error {
+	ctx := c.createContext(token)
+
+	// Запрос
+	_, err := c.gophkeeper.Restore(ctx, &pb.RestoreRequest{Id: id})
+	return err
+}
+
+// Purge окончательно удаляет единицы данных из корзины. Пустой ids - вся корзина
+func (c Client) Purge(token string, ids []int64) (int64, error) {
+	ctx := c.createContext(token)
+
+	// Запрос
+	resp, err := c.gophkeeper.Purge(ctx, &pb.PurgeRequest{Ids: ids})
+	if err != nil {
+		return 0, err
+	}
+	return resp.Purged, nil
+}
+
+// Move переименовывает единицы данных и папки одной транзакцией.
+// fromFolder - перемещаемая папка (пустая при перемещении единицы данных),
+// units должен включать все ее содержимое
+func (c Client) Move(token string, idx blindindex.Indexer, fromFolder string, units []model.Rename, folders []model.Rename) error {
+	req, err := moveRequest(idx, fromFolder, units, folders)
 	if err != nil {
 		return err
 	}
+	return c.move(token, req)
+}
+
+// Rename переименовывает единицу данных или папку в пределах родительской папки.
+// Параметры - как у Move
+func (c Client) Rename(token string, idx blindindex.Indexer, fromFolder string, units []model.Rename, folders []model.Rename) error {
+	req, err := moveRequest(idx, fromFolder, units, folders)
+	if err != nil {
+		return err
+	}
+	ctx := c.createContext(token)
 
+	// Запрос
+	_, err = c.gophkeeper.Rename(ctx, req)
+	return err
+}
+
+// moveRequest формирует запрос перемещения с новыми слепыми индексами
+func moveRequest(idx blindindex.Indexer, fromFolder string, units []model.Rename, folders []model.Rename) (*pb.MoveRequest, error) {
+	req := &pb.MoveRequest{FromFolder: idx.FolderToken(fromFolder)}
+	for _, unit := range units {
+		rename, err := unitRename(idx, idx.NameToken(unit.From), unit.To)
+		if err != nil {
+			return nil, err
+		}
+		req.Units = append(req.Units, rename)
+	}
+	for _, folder := range folders {
+		rename, err := folderRename(idx, idx.FolderToken(folder.From), folder.To)
+		if err != nil {
+			return nil, err
+		}
+		req.Folders = append(req.Folders, rename)
+	}
+	return req, nil
+}
+
+// Reindex переводит данные, записанные до введения слепого индекса, на токены.
+// Открытые теги перезаписываются в зашифрованном виде
+func (c Client) Reindex(token string, idx blindindex.Indexer, units []string, folders []string) error {
+	for _, unit := range units {
+		// Открытое имя совпадает с хранимым на сервере
+		resp, err := c.gophkeeper.Read(c.createContext(token), &pb.ReadRequest{Unitname: unit})
+		if err != nil {
+			return err
+		}
+		rename, err := unitRename(idx, unit, unit)
+		if err != nil {
+			return err
+		}
+		err = c.move(token, &pb.MoveRequest{Units: []*pb.Rename{rename}})
+		if err != nil {
+			return err
+		}
+		if len(resp.Tags) == 0 {
+			continue
+		}
+		// Метаданные сохраняются целиком, открытые теги шифруются при записи
+		_, err = c.Write(token, idx, unitFromRead(unit, resp))
+		if err != nil {
+			return err
+		}
+	}
+	for _, folder := range folders {
+		rename, err := folderRename(idx, folder, folder)
+		if err != nil {
+			return err
+		}
+		err = c.move(token, &pb.MoveRequest{FromFolder: folder, Folders: []*pb.Rename{rename}})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// move выполняет запрос перемещения
+func (c Client) move(token string, req *pb.MoveRequest) error {
+	ctx := c.createContext(token)
+
+	// Запрос
+	_, err := c.gophkeeper.Move(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Mkdir создает папку
+func (c Client) Mkdir(token string, idx blindindex.Indexer, folder string) error {
+	ctx := c.createContext(token)
+
+	// Слепой индекс
+	enc, err := idx.Encrypt(folder)
+	if err != nil {
+		return err
+	}
+	parents, err := folderRefs(idx, folder)
+	if err != nil {
+		return err
+	}
+
+	// Запрос
+	req := &pb.MkdirRequest{Folder: &pb.NameRef{Token: idx.FolderToken(folder), Enc: enc}, Parents: parents}
+	_, err = c.gophkeeper.Mkdir(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Search возвращает метаданные единиц данных, удовлетворяющих условиям поиска.
+// Имя и теги передаются серверу только в виде токенов; поиск по имени
+// возвращает надмножество, которое уточняется вызывающей стороной
+func (c Client) Search(token string, idx blindindex.Indexer, query model.SearchQuery) ([]model.Unit, error) {
+	ctx := c.createContext(token)
+
+	// Запрос
+	req := &pb.SearchRequest{NameNgrams: idx.NGrams(query.Name),
+		Unittype:  int32(query.Type),
+		TagTokens: idx.TagTokens(query.Tags),
+		Fields:    fieldsToProto(query.Fields)}
+	resp, err := c.gophkeeper.Search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return unitsFromProto(idx, resp.Units)
+}
+
+// Due возвращает метаданные единиц данных, просроченных или требующих смены
+// в течение within
+func (c Client) Due(token string, idx blindindex.Indexer, within time.Duration) ([]model.Unit, error) {
+	ctx := c.createContext(token)
+
+	// Запрос
+	resp, err := c.gophkeeper.Due(ctx, &pb.DueRequest{Within: durationpb.New(within)})
+	if err != nil {
+		return nil, err
+	}
+	return unitsFromProto(idx, resp.Units)
+}
+
+// Sync возвращает изменения на сервере после курсора since.
+// Данные без слепого индекса пропускаются
+func (c Client) Sync(token string, idx blindindex.Indexer, since int64) (model.Changes, error) {
+	ctx := c.createContext(token)
+
+	// Запрос
+	resp, err := c.gophkeeper.Sync(ctx, &pb.SyncRequest{SinceCursor: since})
+	if err != nil {
+		return model.Changes{}, err
+	}
+
+	// Маппинг
+	changes := model.Changes{Cursor: resp.Cursor, Full: resp.Full, Deleted: resp.Deleted}
+	for _, syncUnit := range resp.Units {
+		if len(syncUnit.Info.GetNameEnc()) == 0 {
+			continue
+		}
+		unit, err := unitFromProto(idx, syncUnit.Info)
+		if err != nil {
+			return model.Changes{}, err
+		}
+		unit.Body.Data = syncUnit.Unitdata
+		changes.Units = append(changes.Units, unit)
+	}
+	return changes, nil
+}
+
+// Watch открывает поток событий об изменении данных.
+// Возвращаемая функция блокируется до следующего события; поток закрывается отменой ctx
+func (c Client) Watch(ctx context.Context, token string, idx blindindex.Indexer) (func() (model.Event, error), error) {
+	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("token", token))
+
+	// Запрос
+	stream, err := c.gophkeeper.Watch(ctx, &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	recv := func() (model.Event, error) {
+		pbEvent, err := stream.Recv()
+		if err != nil {
+			return model.Event{}, err
+		}
+		// Маппинг
+		event := model.Event{Kind: model.EventKind(pbEvent.Kind), Token: pbEvent.Unitname}
+		if len(pbEvent.NameEnc) > 0 {
+			event.Name, err = idx.Decrypt(pbEvent.NameEnc)
+			if err != nil {
+				return model.Event{}, err
+			}
+		}
+		return event, nil
+	}
+	return recv, nil
+}
+
+// unitsFromProto маппинг метаданных единиц данных из grpc.
+// Данные без слепого индекса пропускаются
+func unitsFromProto(idx blindindex.Indexer, infos []*pb.UnitInfo) ([]model.Unit, error) {
+	var units []model.Unit
+	for _, info := range infos {
+		if len(info.NameEnc) == 0 {
+			// Данные без слепого индекса
+			continue
+		}
+		unit, err := unitFromProto(idx, info)
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+	return units, nil
+}
+
+// unitFromProto маппинг метаданных единицы данных из grpc с расшифровкой имени и тегов
+func unitFromProto(idx blindindex.Indexer, info *pb.UnitInfo) (model.Unit, error) {
+	var unit model.Unit
+	var err error
+	unit.Name, err = idx.Decrypt(info.NameEnc)
+	if err != nil {
+		return model.Unit{}, err
+	}
+	unit.Body.Meta.Type = int(info.Unittype)
+	unit.Body.Meta.Description = info.Description
+	unit.Body.Meta.Tags, err = idx.DecryptTags(info.Tags)
+	if err != nil {
+		return model.Unit{}, err
+	}
+	unit.Body.Meta.Fields = fieldsFromProto(info.Fields)
+	unit.Body.Meta.CreatedAt = info.CreatedAt.AsTime()
+	unit.Body.Meta.UpdatedAt = info.UpdatedAt.AsTime()
+	if info.ExpiresAt != nil {
+		unit.Body.Meta.ExpiresAt = info.ExpiresAt.AsTime()
+	}
+	unit.Body.Meta.RotateEvery = info.RotateEvery.AsDuration()
+	return unit, nil
+}
+
+// unitIndex рассчитывает слепой индекс единицы данных
+func unitIndex(idx blindindex.Indexer, name string, tags []string) (*pb.BlindIndex, error) {
+	enc, err := idx.Encrypt(name)
+	if err != nil {
+		return nil, err
+	}
+	folders, err := folderRefs(idx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.BlindIndex{NameEnc: enc,
+		NameNgrams: idx.NGrams(name),
+		Folders:    folders,
+		TagTokens:  idx.TagTokens(tags)}, nil
+}
+
+// unitRename формирует переименование единицы данных с новым слепым индексом.
+// Теги при перемещении не меняются
+func unitRename(idx blindindex.Indexer, fromToken string, to string) (*pb.Rename, error) {
+	index, err := unitIndex(idx, to, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Rename{From: fromToken, To: idx.NameToken(to), Index: index}, nil
+}
+
+// folderRename формирует переименование папки с новым слепым индексом
+func folderRename(idx blindindex.Indexer, fromToken string, to string) (*pb.Rename, error) {
+	enc, err := idx.Encrypt(to)
+	if err != nil {
+		return nil, err
+	}
+	parents, err := folderRefs(idx, to)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Rename{From: fromToken, To: idx.FolderToken(to), Index: &pb.BlindIndex{NameEnc: enc, Folders: parents}}, nil
+}
+
+// folderRefs возвращает родительские папки имени в виде имен слепого индекса
+func folderRefs(idx blindindex.Indexer, name string) ([]*pb.NameRef, error) {
+	var refs []*pb.NameRef
+	for _, folder := range unitpath.Ancestors(name) {
+		enc, err := idx.Encrypt(folder)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, &pb.NameRef{Token: idx.FolderToken(folder), Enc: enc})
+	}
+	return refs, nil
+}
+
+// decodeNames расшифровывает имена слепого индекса. Открытые имена пропускаются
+func decodeNames(idx blindindex.Indexer, refs []*pb.NameRef) ([]string, error) {
+	var names []string
+	for _, ref := range refs {
+		if len(ref.Enc) == 0 {
+			continue
+		}
+		name, err := idx.Decrypt(ref.Enc)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// legacyNames возвращает открытые имена (без слепого индекса)
+func legacyNames(refs []*pb.NameRef) []string {
+	var names []string
+	for _, ref := range refs {
+		if len(ref.Enc) == 0 {
+			names = append(names, ref.Token)
+		}
+	}
+	return names
+}
+
+// fieldsToProto маппинг пользовательских полей в grpc
+func fieldsToProto(fields []model.Field) []*pb.Field {
+	var pbFields []*pb.Field
+	for _, field := range fields {
+		pbFields = append(pbFields, &pb.Field{Key: field.Key, Value: field.Value, Sensitive: field.Sensitive})
+	}
+	return pbFields
+}
+
+// fieldsFromProto маппинг пользовательских полей из grpc
+func fieldsFromProto(pbFields []*pb.Field) []model.Field {
+	var fields []model.Field
+	for _, field := range pbFields {
+		fields = append(fields, model.Field{Key: field.Key, Value: field.Value, Sensitive: field.Sensitive})
+	}
+	return fields
+}
+
 // createContext создает контекст с метаданными для запроса к grpc-серверу
 func (c Client) createContext(token string) context.Context {
 	ctx := context.Background()
@@ -118,13 +586,13 @@ func (c Client) Close() {
 
 // NewClient создает grpc-клиент
 func NewClient(cfg config.Config) (Client, error) {
-	tlsCredentials, err := gophTLS.LoadTLSCredentials()
+	tlsCredentials, err := gophTLS.LoadTLSCredentials(cfg.CAFile)
 	if err != nil {
 		return Client{}, err
 	}
 
 	// устанавливаем соединение с сервером
-	conn, err := grpc.NewClient(":3200", grpc.WithTransportCredentials(tlsCredentials))
+	conn, err := grpc.NewClient(cfg.Address, grpc.WithTransportCredentials(tlsCredentials))
 	if err != nil {
 		return Client{}, err
 	}
@@ -134,3 +602,8 @@ func NewClient(cfg config.Config) (Client, error) {
 
 	return Client{conn: conn, gophkeeper: c}, nil
 }
+
+// NewClientConn создает grpc-клиент поверх установленного соединения
+func NewClientConn(conn *grpc.ClientConn) Client {
+	return Client{conn: conn, gophkeeper: pb.NewGophkeeperClient(conn)}
+}
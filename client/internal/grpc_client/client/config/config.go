@@ -1,4 +1,8 @@
 package config
 
 type Config struct {
+	// Адрес сервера
+	Address string
+	// Сертификат удостоверяющего центра сервера
+	CAFile string
 }
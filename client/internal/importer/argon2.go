@@ -0,0 +1,223 @@
+package importer
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Argon2d для баз KeePass: golang.org/x/crypto/argon2 реализует только Argon2i и Argon2id.
+// Однопоточная реализация RFC 9106, версия 0x13
+
+const (
+	argon2Version    = 0x13
+	argon2BlockWords = 128
+	argon2SyncPoints = 4
+)
+
+type argon2Block [argon2BlockWords]uint64
+
+// argon2d вычисляет ключ Argon2d
+func argon2d(password, salt, secret, data []byte, time, memory, threads, keyLen uint32) []byte {
+	h0 := argon2InitHash(password, salt, secret, data, time, memory, threads, keyLen)
+
+	memory = memory / (argon2SyncPoints * threads) * (argon2SyncPoints * threads)
+	if memory < 2*argon2SyncPoints*threads {
+		memory = 2 * argon2SyncPoints * threads
+	}
+	lanes := memory / threads
+	segments := lanes / argon2SyncPoints
+	B := make([]argon2Block, memory)
+
+	// Первые два блока каждой полосы
+	var buf [1024]byte
+	for lane := uint32(0); lane < threads; lane++ {
+		binary.LittleEndian.PutUint32(h0[blake2b.Size+4:], lane)
+		for i := uint32(0); i < 2; i++ {
+			binary.LittleEndian.PutUint32(h0[blake2b.Size:], i)
+			argon2Hash(buf[:], h0[:])
+			for j := range B[lane*lanes+i] {
+				B[lane*lanes+i][j] = binary.LittleEndian.Uint64(buf[j*8:])
+			}
+		}
+	}
+
+	// Сегменты одного среза обращаются только к завершенным срезам других полос,
+	// поэтому полосы обрабатываются последовательно
+	for n := uint32(0); n < time; n++ {
+		for slice := uint32(0); slice < argon2SyncPoints; slice++ {
+			for lane := uint32(0); lane < threads; lane++ {
+				index := uint32(0)
+				if n == 0 && slice == 0 {
+					index = 2
+				}
+				offset := lane*lanes + slice*segments + index
+				for ; index < segments; index, offset = index+1, offset+1 {
+					prev := offset - 1
+					if index == 0 && slice == 0 {
+						prev += lanes
+					}
+					ref := argon2IndexAlpha(B[prev][0], lanes, segments, threads, n, slice, lane, index)
+					argon2Compress(&B[offset], &B[prev], &B[ref], n > 0)
+				}
+			}
+		}
+	}
+
+	// Итоговый блок - XOR последних блоков полос
+	last := B[memory-1]
+	for lane := uint32(0); lane < threads-1; lane++ {
+		for i, v := range B[lane*lanes+lanes-1] {
+			last[i] ^= v
+		}
+	}
+	for i, v := range last {
+		binary.LittleEndian.PutUint64(buf[i*8:], v)
+	}
+	key := make([]byte, keyLen)
+	argon2Hash(key, buf[:])
+	return key
+}
+
+// argon2InitHash - H0 с местом под номер блока и полосы
+func argon2InitHash(password, salt, secret, data []byte, time, memory, threads, keyLen uint32) [blake2b.Size + 8]byte {
+	var h0 [blake2b.Size + 8]byte
+	b2, _ := blake2b.New512(nil)
+	writeUint32 := func(v uint32) {
+		var tmp [4]byte
+		binary.LittleEndian.PutUint32(tmp[:], v)
+		b2.Write(tmp[:])
+	}
+	// Тип 0 - Argon2d
+	for _, v := range []uint32{threads, keyLen, memory, time, argon2Version, 0} {
+		writeUint32(v)
+	}
+	for _, v := range [][]byte{password, salt, secret, data} {
+		writeUint32(uint32(len(v)))
+		b2.Write(v)
+	}
+	b2.Sum(h0[:0])
+	return h0
+}
+
+// argon2Hash - хеш переменной длины H'
+func argon2Hash(out []byte, in []byte) {
+	var b2 hash.Hash
+	if len(out) < blake2b.Size {
+		b2, _ = blake2b.New(len(out), nil)
+	} else {
+		b2, _ = blake2b.New512(nil)
+	}
+	var buf [blake2b.Size]byte
+	binary.LittleEndian.PutUint32(buf[:4], uint32(len(out)))
+	b2.Write(buf[:4])
+	b2.Write(in)
+	if len(out) <= blake2b.Size {
+		b2.Sum(out[:0])
+		return
+	}
+
+	outLen := len(out)
+	b2.Sum(buf[:0])
+	copy(out, buf[:32])
+	out = out[32:]
+	for len(out) > blake2b.Size {
+		b2.Reset()
+		b2.Write(buf[:])
+		b2.Sum(buf[:0])
+		copy(out, buf[:32])
+		out = out[32:]
+	}
+	if outLen%blake2b.Size > 0 {
+		r := (outLen+31)/32 - 2
+		b2, _ = blake2b.New(outLen-32*r, nil)
+	} else {
+		b2.Reset()
+	}
+	b2.Write(buf[:])
+	b2.Sum(out[:0])
+}
+
+// argon2IndexAlpha возвращает индекс опорного блока
+func argon2IndexAlpha(rand uint64, lanes, segments, threads, n, slice, lane, index uint32) uint32 {
+	refLane := uint32(rand>>32) % threads
+	if n == 0 && slice == 0 {
+		refLane = lane
+	}
+	m, s := 3*segments, ((slice+1)%argon2SyncPoints)*segments
+	if lane == refLane {
+		m += index
+	}
+	if n == 0 {
+		m, s = slice*segments, 0
+		if slice == 0 || lane == refLane {
+			m += index
+		}
+	}
+	if index == 0 || lane == refLane {
+		m--
+	}
+	p := rand & 0xFFFFFFFF
+	p = (p * p) >> 32
+	p = (p * uint64(m)) >> 32
+	return refLane*lanes + uint32((uint64(s)+uint64(m)-(p+1))%uint64(lanes))
+}
+
+// argon2Compress - функция сжатия G. При xor результат накладывается на out
+func argon2Compress(out, in1, in2 *argon2Block, xor bool) {
+	var r, t argon2Block
+	for i := range r {
+		r[i] = in1[i] ^ in2[i]
+	}
+	t = r
+	var v [16]uint64
+	// Строки: 8 последовательных пар слов
+	for i := 0; i < argon2BlockWords; i += 16 {
+		copy(v[:], t[i:i+16])
+		argon2Round(&v)
+		copy(t[i:i+16], v[:])
+	}
+	// Столбцы: пары слов с шагом 16
+	for i := 0; i < 16; i += 2 {
+		for j := 0; j < 8; j++ {
+			v[2*j], v[2*j+1] = t[16*j+i], t[16*j+i+1]
+		}
+		argon2Round(&v)
+		for j := 0; j < 8; j++ {
+			t[16*j+i], t[16*j+i+1] = v[2*j], v[2*j+1]
+		}
+	}
+	for i := range t {
+		if xor {
+			out[i] ^= r[i] ^ t[i]
+		} else {
+			out[i] = r[i] ^ t[i]
+		}
+	}
+}
+
+// argon2Round - раунд BLAKE2b с умножением BlaMka
+func argon2Round(v *[16]uint64) {
+	argon2G(v, 0, 4, 8, 12)
+	argon2G(v, 1, 5, 9, 13)
+	argon2G(v, 2, 6, 10, 14)
+	argon2G(v, 3, 7, 11, 15)
+	argon2G(v, 0, 5, 10, 15)
+	argon2G(v, 1, 6, 11, 12)
+	argon2G(v, 2, 7, 8, 13)
+	argon2G(v, 3, 4, 9, 14)
+}
+
+func argon2G(v *[16]uint64, a, b, c, d int) {
+	blamka := func(x, y uint64) uint64 { return x + y + 2*uint64(uint32(x))*uint64(uint32(y)) }
+	v[a] = blamka(v[a], v[b])
+	v[d] = bits.RotateLeft64(v[d]^v[a], -32)
+	v[c] = blamka(v[c], v[d])
+	v[b] = bits.RotateLeft64(v[b]^v[c], -24)
+	v[a] = blamka(v[a], v[b])
+	v[d] = bits.RotateLeft64(v[d]^v[a], -16)
+	v[c] = blamka(v[c], v[d])
+	v[b] = bits.RotateLeft64(v[b]^v[c], -63)
+}
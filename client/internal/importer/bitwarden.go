@@ -0,0 +1,170 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
+)
+
+// Типы записей Bitwarden
+const (
+	bwLogin      = 1
+	bwSecureNote = 2
+	bwCard       = 3
+	bwIdentity   = 4
+)
+
+// Типы пользовательских полей Bitwarden
+const (
+	bwFieldHidden = 1
+)
+
+// bwExport - экспорт Bitwarden JSON без шифрования, личный или организации
+type bwExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Collections []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"collections"`
+	Items []bwItem `json:"items"`
+}
+
+type bwItem struct {
+	Type          int      `json:"type"`
+	Name          string   `json:"name"`
+	Notes         string   `json:"notes"`
+	FolderID      string   `json:"folderId"`
+	CollectionIDs []string `json:"collectionIds"`
+	Fields        []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+		Type  int    `json:"type"`
+	} `json:"fields"`
+	Login *struct {
+		URIs []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+		TOTP     string `json:"totp"`
+	} `json:"login"`
+	Card *struct {
+		CardholderName string `json:"cardholderName"`
+		Brand          string `json:"brand"`
+		Number         string `json:"number"`
+		ExpMonth       string `json:"expMonth"`
+		ExpYear        string `json:"expYear"`
+		Code           string `json:"code"`
+	} `json:"card"`
+	Identity map[string]any `json:"identity"`
+}
+
+// parseBitwarden разбирает экспорт Bitwarden JSON
+func parseBitwarden(data []byte) ([]entry, error) {
+	var export bwExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, err
+	}
+	if export.Encrypted {
+		return nil, ErrEncrypted
+	}
+	// Вложенные папки Bitwarden разделяются "/"
+	folders := make(map[string][]string)
+	for _, folder := range export.Folders {
+		folders[folder.ID] = strings.Split(folder.Name, unitpath.Separator)
+	}
+	// Коллекции организации становятся тегами
+	collections := make(map[string]string)
+	for _, collection := range export.Collections {
+		collections[collection.ID] = collection.Name
+	}
+
+	entries := make([]entry, 0, len(export.Items))
+	for _, item := range export.Items {
+		e := entry{folder: folders[item.FolderID], title: item.Name}
+		for _, id := range item.CollectionIDs {
+			if name, ok := collections[id]; ok {
+				e.meta.Tags = append(e.meta.Tags, name)
+			}
+		}
+		for _, field := range item.Fields {
+			e.meta.Fields = append(e.meta.Fields, model.Field{Key: field.Name, Value: field.Value, Sensitive: field.Type == bwFieldHidden})
+		}
+		e.meta.Description = item.Notes
+
+		switch {
+		case item.Type == bwLogin && item.Login != nil:
+			e.meta.Type = model.UnitTypeLogin
+			var url string
+			for i, uri := range item.Login.URIs {
+				if i == 0 {
+					url = uri.URI
+					continue
+				}
+				e.meta.Fields = append(e.meta.Fields, model.Field{Key: fmt.Sprintf("url%d", i+1), Value: uri.URI})
+			}
+			if item.Login.TOTP != "" {
+				e.meta.Fields = append(e.meta.Fields, model.Field{Key: "totp", Value: item.Login.TOTP, Sensitive: true})
+			}
+			e.data = login(item.Login.Username, item.Login.Password, url)
+		case item.Type == bwCard && item.Card != nil:
+			e.meta.Type = model.UnitTypeCard
+			card := model.CardData{Number: item.Card.Number, Holder: item.Card.CardholderName, CVV: item.Card.Code}
+			card.Expiry = cardExpiry(item.Card.ExpMonth, item.Card.ExpYear)
+			if item.Card.Brand != "" {
+				e.meta.Fields = append(e.meta.Fields, model.Field{Key: "brand", Value: item.Card.Brand})
+			}
+			e.data = card.Bytes()
+		case item.Type == bwIdentity && item.Identity != nil:
+			// Личные данные - текст "ключ: значение"
+			e.meta.Type = model.UnitTypeText
+			e.meta.Description = ""
+			e.data = []byte(identityText(item.Identity, item.Notes))
+		default:
+			e.meta.Type = model.UnitTypeText
+			e.meta.Description = ""
+			e.data = []byte(item.Notes)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// identityText формирует текст личных данных в порядке полей Bitwarden
+func identityText(identity map[string]any, notes string) string {
+	keys := []string{"title", "firstName", "middleName", "lastName", "username", "company",
+		"email", "phone", "address1", "address2", "address3", "city", "state", "postalCode",
+		"country", "ssn", "passportNumber", "licenseNumber"}
+	var lines []string
+	for _, key := range keys {
+		if value, ok := identity[key].(string); ok && value != "" {
+			lines = append(lines, key+": "+value)
+		}
+	}
+	if notes != "" {
+		lines = append(lines, "", notes)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cardExpiry формирует срок действия MM/YY
+func cardExpiry(month string, year string) string {
+	month, year = strings.TrimSpace(month), strings.TrimSpace(year)
+	if month == "" || year == "" {
+		return ""
+	}
+	if len(month) == 1 {
+		month = "0" + month
+	}
+	if len(year) == 4 {
+		year = year[2:]
+	}
+	return month + "/" + year
+}
@@ -0,0 +1,139 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+)
+
+// Колонки CSV: имена колонок разных источников без учета регистра
+var csvColumns = map[string][]string{
+	"title":    {"title", "name"},
+	"url":      {"url", "website", "login_uri", "login url"},
+	"username": {"username", "login_username", "user name"},
+	"password": {"password", "login_password"},
+	"notes":    {"notes", "note", "extra"},
+	"folder":   {"grouping", "folder"},
+	"tags":     {"tags"},
+	"otp":      {"otpauth", "totp", "login_totp"},
+}
+
+// lastPassNoteURL - адрес, которым LastPass помечает защищенные заметки
+const lastPassNoteURL = "http://sn"
+
+// detectCSV определяет источник CSV по заголовку
+func detectCSV(data []byte) (string, error) {
+	header, err := csv.NewReader(bytes.NewReader(data)).Read()
+	if err != nil {
+		return "", ErrDetect
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")))
+	}
+	has := func(column string) bool { return slices.Contains(header, column) }
+	switch {
+	case has("grouping") && has("extra"):
+		return FormatLastPass, nil
+	case has("httprealm") || slices.Equal(header[:min(len(header), 4)], []string{"name", "url", "username", "password"}):
+		// Firefox и Chrome
+		return FormatBrowser, nil
+	case has("title") && (has("url") || has("website")) && has("password"):
+		return Format1PasswordCSV, nil
+	}
+	return "", ErrDetect
+}
+
+// parseCSV разбирает CSV с заголовком. Формат влияет только на особенности LastPass:
+// защищенные заметки, карты и вложенные папки через "\"
+func parseCSV(format string, data []byte) ([]entry, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for column, aliases := range csvColumns {
+			if _, ok := columns[column]; !ok && slices.Contains(aliases, name) {
+				columns[column] = i
+			}
+		}
+	}
+
+	entries := make([]entry, 0, len(records)-1)
+	for _, record := range records[1:] {
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		e := entry{title: value("title"), meta: model.UnitMeta{Tags: splitTags(value("tags"))}}
+		if folder := value("folder"); folder != "" {
+			if format == FormatLastPass {
+				folder = strings.ReplaceAll(folder, `\`, "/")
+			}
+			e.folder = strings.Split(folder, "/")
+		}
+		link, notes := value("url"), value("notes")
+		if e.title == "" {
+			e.title = host(link)
+		}
+
+		switch {
+		case format == FormatLastPass && link == lastPassNoteURL:
+			lastPassNote(&e, notes)
+		default:
+			e.meta.Type = model.UnitTypeLogin
+			e.meta.Description = notes
+			if otp := value("otp"); otp != "" {
+				e.meta.Fields = append(e.meta.Fields, model.Field{Key: "totp", Value: otp, Sensitive: true})
+			}
+			e.data = login(value("username"), value("password"), link)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// lastPassNote разбирает защищенную заметку LastPass: карту или текст
+func lastPassNote(e *entry, notes string) {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(notes, "\n") {
+		if key, value, found := strings.Cut(line, ":"); found {
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+	if fields["NoteType"] != "Credit Card" {
+		e.meta.Type = model.UnitTypeText
+		e.data = []byte(notes)
+		return
+	}
+	e.meta.Type = model.UnitTypeCard
+	card := model.CardData{Number: fields["Number"], Holder: fields["Name on Card"], CVV: fields["Security Code"]}
+	// Срок действия: "January,2027"
+	if expiry, err := time.Parse("January,2006", fields["Expiration Date"]); err == nil {
+		card.Expiry = expiry.Format("01/06")
+	}
+	e.meta.Description = fields["Notes"]
+	e.data = card.Bytes()
+}
+
+// host возвращает имя узла адреса
+func host(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+	return u.Hostname()
+}
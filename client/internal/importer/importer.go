@@ -0,0 +1,221 @@
+// Пакет importer. Импорт данных из файлов экспорта других менеджеров паролей:
+// KeePass (XML и KDBX), Bitwarden (JSON), 1Password (CSV и 1PUX), LastPass (CSV) и браузеров (CSV).
+// Записи преобразуются в единицы данных входа, карт, текста и бинарные с папками, тегами и полями
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
+)
+
+// Форматы файлов экспорта
+const (
+	FormatKeePassXML   = "keepass-xml"
+	FormatKDBX         = "kdbx"
+	FormatBitwarden    = "bitwarden"
+	Format1PasswordCSV = "1password-csv"
+	Format1PUX         = "1pux"
+	FormatLastPass     = "lastpass"
+	FormatBrowser      = "browser"
+)
+
+// Formats - поддерживаемые форматы
+var Formats = []string{FormatKeePassXML, FormatKDBX, FormatBitwarden, Format1PasswordCSV, Format1PUX, FormatLastPass, FormatBrowser}
+
+// Действия с записью при импорте
+const (
+	ActionCreate    = "create"
+	ActionSkip      = "skip"
+	ActionOverwrite = "overwrite"
+	ActionRename    = "rename"
+)
+
+var (
+	ErrFormat    = errors.New("unknown import format")
+	ErrDetect    = errors.New("cannot detect import format, use --format")
+	ErrEncrypted = errors.New("encrypted export is not supported, export without encryption")
+	ErrKey       = errors.New("invalid database key")
+	ErrDuplicate = errors.New("invalid duplicate action")
+)
+
+// Options - параметры импорта
+type Options struct {
+	// Пароль и содержимое файла ключа базы KDBX
+	Password string
+	KeyFile  []byte
+	// Папка, в которую помещаются импортированные данные. Пустая - корень
+	Folder string
+}
+
+// Detect определяет формат по имени и содержимому файла
+func Detect(filename string, data []byte) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".kdbx":
+		return FormatKDBX, nil
+	case ".1pux":
+		return Format1PUX, nil
+	case ".xml":
+		return FormatKeePassXML, nil
+	case ".json":
+		return FormatBitwarden, nil
+	case ".csv":
+		return detectCSV(data)
+	}
+	switch {
+	case bytes.HasPrefix(data, kdbxSignature):
+		return FormatKDBX, nil
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return Format1PUX, nil
+	}
+	return "", ErrDetect
+}
+
+// Parse разбирает файл экспорта
+func Parse(format string, data []byte, opts Options) ([]model.Unit, error) {
+	folder, err := unitpath.CleanFolder(opts.Folder)
+	if err != nil {
+		return nil, err
+	}
+	var entries []entry
+	switch format {
+	case FormatKeePassXML:
+		entries, err = parseKeePassXML(data, nil)
+	case FormatKDBX:
+		entries, err = parseKDBX(data, opts.Password, opts.KeyFile)
+	case FormatBitwarden:
+		entries, err = parseBitwarden(data)
+	case Format1PasswordCSV, FormatLastPass, FormatBrowser:
+		entries, err = parseCSV(format, data)
+	case Format1PUX:
+		entries, err = parse1PUX(data)
+	default:
+		return nil, fmt.Errorf("%w %q, expected one of: %s", ErrFormat, format, strings.Join(Formats, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	units := make([]model.Unit, 0, len(entries))
+	for _, e := range entries {
+		units = append(units, e.unit(folder))
+	}
+	return units, nil
+}
+
+// entry - запись файла экспорта
+type entry struct {
+	// Папка в формате источника: сегменты пути
+	folder []string
+	title  string
+	meta   model.UnitMeta
+	data   []byte
+}
+
+// unit преобразует запись в единицу данных
+func (e entry) unit(folder string) model.Unit {
+	name := folder
+	for _, segment := range e.folder {
+		if segment = cleanSegment(segment); segment != "" {
+			name = unitpath.Join(name, segment)
+		}
+	}
+	title := cleanSegment(e.title)
+	if title == "" {
+		title = "untitled"
+	}
+	name = unitpath.Join(name, title)
+	if len(name) > unitpath.MaxLen {
+		name = strings.TrimRight(name[:unitpath.MaxLen], unitpath.Separator)
+	}
+	return model.Unit{Name: name, Body: model.UnitBody{Meta: e.meta, Data: e.data}}
+}
+
+// cleanSegment приводит название к допустимому сегменту имени:
+// разделитель заменяется, управляющие символы удаляются
+func cleanSegment(s string) string {
+	s = strings.ReplaceAll(s, unitpath.Separator, "-")
+	s = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s))
+	if s == "." || s == ".." {
+		return "_"
+	}
+	return s
+}
+
+// Item - запись плана импорта
+type Item struct {
+	Unit   model.Unit
+	Action string
+	// Имя в источнике, если единица данных переименована
+	Source string
+}
+
+// Plan сопоставляет импортируемые единицы данных с существующими именами.
+// Повторы внутри файла переименовываются; совпадение с существующим именем
+// обрабатывается по onDuplicate: пропуск, перезапись или переименование
+func Plan(units []model.Unit, existing []string, onDuplicate string) ([]Item, error) {
+	switch onDuplicate {
+	case ActionSkip, ActionOverwrite, ActionRename:
+	default:
+		return nil, fmt.Errorf("%w %q, expected %s, %s or %s", ErrDuplicate, onDuplicate, ActionSkip, ActionOverwrite, ActionRename)
+	}
+	exists := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		exists[name] = true
+	}
+	planned := make(map[string]bool, len(units))
+	// free возвращает свободное имя с номером
+	free := func(name string) string {
+		for i := 2; ; i++ {
+			candidate := fmt.Sprintf("%s (%d)", name, i)
+			if !exists[candidate] && !planned[candidate] {
+				return candidate
+			}
+		}
+	}
+
+	items := make([]Item, 0, len(units))
+	for _, unit := range units {
+		item := Item{Unit: unit, Action: ActionCreate}
+		switch {
+		case planned[unit.Name]:
+			item.Action, item.Source, item.Unit.Name = ActionRename, unit.Name, free(unit.Name)
+		case exists[unit.Name]:
+			item.Action = onDuplicate
+			if onDuplicate == ActionRename {
+				item.Source, item.Unit.Name = unit.Name, free(unit.Name)
+			}
+		}
+		if item.Action != ActionSkip {
+			planned[item.Unit.Name] = true
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// login формирует данные входа
+func login(username string, password string, url string) []byte {
+	return model.LoginData{Login: username, Password: password, URL: url}.Bytes()
+}
+
+// splitTags разбирает список тегов через запятую или точку с запятой
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,201 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+// byName - единицы данных по имени
+func byName(t *testing.T, units []model.Unit) map[string]model.Unit {
+	result := make(map[string]model.Unit, len(units))
+	for _, unit := range units {
+		require.NotContains(t, result, unit.Name)
+		result[unit.Name] = unit
+	}
+	return result
+}
+
+func TestParse_Bitwarden(t *testing.T) {
+	data := `{
+		"encrypted": false,
+		"folders": [{"id": "f1", "name": "Work/Mail"}],
+		"items": [
+			{"type": 1, "name": "Google", "folderId": "f1", "notes": "main account",
+			 "fields": [{"name": "pin", "value": "1234", "type": 1}],
+			 "login": {"uris": [{"uri": "https://google.com"}, {"uri": "https://gmail.com"}], "username": "bob", "password": "secret", "totp": "JBSWY3DP"}},
+			{"type": 3, "name": "Visa", "card": {"cardholderName": "Bob", "brand": "Visa", "number": "4111111111111111", "expMonth": "3", "expYear": "2027", "code": "123"}},
+			{"type": 2, "name": "Wi-Fi / home", "notes": "password: qwerty", "secureNote": {"type": 0}}
+		]
+	}`
+	units, err := Parse(FormatBitwarden, []byte(data), Options{})
+	require.NoError(t, err)
+	got := byName(t, units)
+
+	google := got["Work/Mail/Google"]
+	require.Equal(t, model.UnitTypeLogin, google.Body.Meta.Type)
+	require.Equal(t, model.LoginData{Login: "bob", Password: "secret", URL: "https://google.com"}, model.ParseLogin(google.Body.Data))
+	require.Equal(t, "main account", google.Body.Meta.Description)
+	require.Equal(t, []model.Field{
+		{Key: "pin", Value: "1234", Sensitive: true},
+		{Key: "url2", Value: "https://gmail.com"},
+		{Key: "totp", Value: "JBSWY3DP", Sensitive: true},
+	}, google.Body.Meta.Fields)
+
+	visa := got["Visa"]
+	require.Equal(t, model.UnitTypeCard, visa.Body.Meta.Type)
+	require.Equal(t, model.CardData{Number: "4111111111111111", Holder: "Bob", Expiry: "03/27", CVV: "123"}, model.ParseCard(visa.Body.Data))
+
+	note := got["Wi-Fi - home"]
+	require.Equal(t, model.UnitTypeText, note.Body.Meta.Type)
+	require.Equal(t, "password: qwerty", string(note.Body.Data))
+
+	_, err = Parse(FormatBitwarden, []byte(`{"encrypted": true}`), Options{})
+	require.ErrorIs(t, err, ErrEncrypted)
+}
+
+func TestParse_CSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   map[string]model.LoginData
+	}{
+		{
+			name:   "chrome",
+			format: FormatBrowser,
+			data:   "name,url,username,password,note\ngithub.com,https://github.com/login,bob,pa55,\n",
+			want:   map[string]model.LoginData{"github.com": {Login: "bob", Password: "pa55", URL: "https://github.com/login"}},
+		},
+		{
+			name:   "firefox",
+			format: FormatBrowser,
+			data:   "\"url\",\"username\",\"password\",\"httpRealm\",\"formActionOrigin\",\"guid\"\n\"https://example.org\",\"alice\",\"pw\",,\"\",\"{1}\"\n",
+			want:   map[string]model.LoginData{"example.org": {Login: "alice", Password: "pw", URL: "https://example.org"}},
+		},
+		{
+			name:   "1password",
+			format: Format1PasswordCSV,
+			data:   "Title,Url,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes\nDropbox,https://dropbox.com,bob,pw,,false,false,cloud;work,note\n",
+			want:   map[string]model.LoginData{"Dropbox": {Login: "bob", Password: "pw", URL: "https://dropbox.com"}},
+		},
+		{
+			name:   "lastpass",
+			format: FormatLastPass,
+			data: "url,username,password,totp,extra,name,grouping,fav\n" +
+				"https://shop.com,bob,pw,,,Shop,Personal\\Shopping,0\n" +
+				"http://sn,,,,\"NoteType:Credit Card\nName on Card:Bob\nNumber:4111111111111111\nSecurity Code:123\nExpiration Date:January,2027\",Visa,,0\n",
+			want: map[string]model.LoginData{"Personal/Shopping/Shop": {Login: "bob", Password: "pw", URL: "https://shop.com"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := Detect("export.csv", []byte(tt.data))
+			require.NoError(t, err)
+			require.Equal(t, tt.format, format)
+
+			units, err := Parse(format, []byte(tt.data), Options{})
+			require.NoError(t, err)
+			got := byName(t, units)
+			for name, want := range tt.want {
+				require.Contains(t, got, name)
+				require.Equal(t, want, model.ParseLogin(got[name].Body.Data))
+			}
+		})
+	}
+
+	units, err := Parse(FormatLastPass, []byte(tests[3].data), Options{})
+	require.NoError(t, err)
+	visa := byName(t, units)["Visa"]
+	require.Equal(t, model.UnitTypeCard, visa.Body.Meta.Type)
+	require.Equal(t, model.CardData{Number: "4111111111111111", Holder: "Bob", Expiry: "01/27", CVV: "123"}, model.ParseCard(visa.Body.Data))
+}
+
+func TestParse_1PUX(t *testing.T) {
+	export := `{"accounts": [{"vaults": [{"attrs": {"name": "Personal"}, "items": [
+		{"categoryUuid": "001", "state": "active",
+		 "details": {"loginFields": [{"value": "bob", "designation": "username"}, {"value": "pw", "designation": "password"}],
+		             "sections": [{"fields": [{"title": "pin", "id": "p", "value": {"concealed": "0000"}}]}]},
+		 "overview": {"title": "Amazon", "url": "https://amazon.com", "tags": ["shop"]}},
+		{"categoryUuid": "002", "state": "archived",
+		 "details": {"sections": [{"fields": [
+			{"id": "cardholder", "value": {"string": "Bob"}},
+			{"id": "ccnum", "value": {"creditCardNumber": "4111111111111111"}},
+			{"id": "cvv", "value": {"concealed": "123"}},
+			{"id": "expiry", "value": {"monthYear": 202705}}]}]},
+		 "overview": {"title": "Visa"}},
+		{"categoryUuid": "006", "details": {"documentAttributes": {"fileName": "key.pem", "documentId": "d1"}},
+		 "overview": {"title": "SSH key"}}
+	]}]}]}`
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range map[string]string{"export.data": export, "files/d1__key.pem": "PEM"} {
+		w, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+
+	format, err := Detect("export.1pux", buf.Bytes())
+	require.NoError(t, err)
+	units, err := Parse(format, buf.Bytes(), Options{Folder: "1p"})
+	require.NoError(t, err)
+	got := byName(t, units)
+
+	amazon := got["1p/Personal/Amazon"]
+	require.Equal(t, model.LoginData{Login: "bob", Password: "pw", URL: "https://amazon.com"}, model.ParseLogin(amazon.Body.Data))
+	require.Equal(t, []string{"shop"}, amazon.Body.Meta.Tags)
+	require.Equal(t, []model.Field{{Key: "pin", Value: "0000", Sensitive: true}}, amazon.Body.Meta.Fields)
+
+	visa := got["1p/Personal/Visa"]
+	require.Equal(t, model.CardData{Number: "4111111111111111", Holder: "Bob", Expiry: "05/27", CVV: "123"}, model.ParseCard(visa.Body.Data))
+	require.Equal(t, []string{"archived"}, visa.Body.Meta.Tags)
+
+	key := got["1p/Personal/SSH key"]
+	require.Equal(t, model.UnitTypeBinary, key.Body.Meta.Type)
+	require.Equal(t, "PEM", string(key.Body.Data))
+}
+
+func TestPlan(t *testing.T) {
+	unit := func(name string) model.Unit { return model.Unit{Name: name} }
+	units := []model.Unit{unit("a"), unit("b"), unit("b"), unit("c")}
+	existing := []string{"a", "a (2)"}
+
+	tests := []struct {
+		onDuplicate string
+		want        []Item
+	}{
+		{
+			onDuplicate: ActionSkip,
+			want: []Item{
+				{Unit: unit("a"), Action: ActionSkip},
+				{Unit: unit("b"), Action: ActionCreate},
+				{Unit: unit("b (2)"), Action: ActionRename, Source: "b"},
+				{Unit: unit("c"), Action: ActionCreate},
+			},
+		},
+		{
+			onDuplicate: ActionRename,
+			want: []Item{
+				{Unit: unit("a (3)"), Action: ActionRename, Source: "a"},
+				{Unit: unit("b"), Action: ActionCreate},
+				{Unit: unit("b (2)"), Action: ActionRename, Source: "b"},
+				{Unit: unit("c"), Action: ActionCreate},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.onDuplicate, func(t *testing.T) {
+			items, err := Plan(units, existing, tt.onDuplicate)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, items)
+		})
+	}
+
+	_, err := Plan(units, existing, "merge")
+	require.ErrorIs(t, err, ErrDuplicate)
+}
@@ -0,0 +1,583 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/salsa20"
+)
+
+// Формат KDBX 3.1 и 4.x
+
+// kdbxSignature - сигнатуры файла KeePass 2.x
+var kdbxSignature = []byte{0x03, 0xD9, 0xA2, 0x9A, 0x67, 0xFB, 0x4B, 0xB5}
+
+// Идентификаторы шифров и функций формирования ключа
+var (
+	kdbxCipherAES      = mustHex("31c1f2e6bf714350be5805216afc5aff")
+	kdbxCipherChaCha20 = mustHex("d6038a2b8b6f4cb5a524339a31dbb59a")
+	kdbxKdfAES         = mustHex("c9d9f39a628a4460bf740d08c18a4fea")
+	kdbxKdfArgon2d     = mustHex("ef636ddf8c29444b91f7a9a403e30a0c")
+	kdbxKdfArgon2id    = mustHex("9e298b1956db4773b23dfc3ec6f0a1e6")
+)
+
+// Поля внешнего заголовка
+const (
+	kdbxEndOfHeader          = 0
+	kdbxCipherID             = 2
+	kdbxCompressionFlags     = 3
+	kdbxMasterSeed           = 4
+	kdbxTransformSeed        = 5
+	kdbxTransformRounds      = 6
+	kdbxEncryptionIV         = 7
+	kdbxProtectedStreamKey   = 8
+	kdbxStreamStartBytes     = 9
+	kdbxInnerRandomStreamID  = 10
+	kdbxKdfParameters        = 11
+	kdbxInnerEndOfHeader     = 0
+	kdbxInnerRandomStream    = 1
+	kdbxInnerRandomStreamKey = 2
+	kdbxInnerBinary          = 3
+)
+
+// Шифры защищенных значений
+const (
+	kdbxStreamNone     = 0
+	kdbxStreamSalsa20  = 2
+	kdbxStreamChaCha20 = 3
+)
+
+var (
+	ErrKDBX          = errors.New("invalid KDBX file")
+	ErrKDBXCorrupted = errors.New("KDBX file is corrupted")
+	ErrKDBXVersion   = errors.New("unsupported KDBX version")
+	ErrKDBXCipher    = errors.New("unsupported KDBX cipher")
+)
+
+// salsa20Nonce - фиксированный nonce защищенных значений KDBX 3.1
+var salsa20Nonce = []byte{0xE8, 0x30, 0x09, 0x4B, 0x97, 0x20, 0x5D, 0x2A}
+
+// kdbxHeader - поля внешнего заголовка
+type kdbxHeader struct {
+	major       uint16
+	fields      map[byte][]byte
+	kdf         map[string][]byte
+	compression uint32
+	// Заголовок целиком, для проверки HMAC в KDBX 4
+	raw []byte
+}
+
+// parseKDBX расшифровывает базу KeePass и разбирает ее содержимое
+func parseKDBX(data []byte, password string, keyFile []byte) ([]entry, error) {
+	header, payload, err := readKDBXHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	composite, err := compositeKey(password, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	transformed, err := header.transformKey(composite)
+	if err != nil {
+		return nil, err
+	}
+	masterSeed := header.fields[kdbxMasterSeed]
+	if len(masterSeed) != 32 {
+		return nil, ErrKDBX
+	}
+	masterKey := sha256.Sum256(append(append([]byte{}, masterSeed...), transformed...))
+
+	var plain []byte
+	streamID, streamKey := uint32(0), []byte(nil)
+	var binaries [][]byte
+	if header.major == 3 {
+		decrypted, err := header.decrypt(masterKey[:], payload)
+		if err != nil {
+			return nil, err
+		}
+		start := header.fields[kdbxStreamStartBytes]
+		if len(decrypted) < len(start) || !bytes.Equal(decrypted[:len(start)], start) {
+			return nil, ErrKey
+		}
+		if plain, err = readHashedBlocks(decrypted[len(start):]); err != nil {
+			return nil, err
+		}
+		if id := header.fields[kdbxInnerRandomStreamID]; len(id) == 4 {
+			streamID = binary.LittleEndian.Uint32(id)
+		}
+		streamKey = header.fields[kdbxProtectedStreamKey]
+	} else {
+		// SHA-256 и HMAC заголовка
+		if len(payload) < 64 {
+			return nil, ErrKDBX
+		}
+		hash := sha256.Sum256(header.raw)
+		if !bytes.Equal(hash[:], payload[:32]) {
+			return nil, ErrKDBXCorrupted
+		}
+		hmacBase := sha512.Sum512(append(append(append([]byte{}, masterSeed...), transformed...), 1))
+		mac := hmac.New(sha256.New, kdbxBlockKey(hmacBase[:], ^uint64(0)))
+		mac.Write(header.raw)
+		if !hmac.Equal(mac.Sum(nil), payload[32:64]) {
+			return nil, ErrKey
+		}
+		encrypted, err := readHMACBlocks(hmacBase[:], payload[64:])
+		if err != nil {
+			return nil, err
+		}
+		if plain, err = header.decrypt(masterKey[:], encrypted); err != nil {
+			return nil, err
+		}
+	}
+
+	if header.compression == 1 {
+		reader, err := gzip.NewReader(bytes.NewReader(plain))
+		if err != nil {
+			return nil, err
+		}
+		if plain, err = io.ReadAll(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	if header.major == 4 {
+		// Внутренний заголовок: шифр защищенных значений и вложения
+		for {
+			if len(plain) < 5 {
+				return nil, ErrKDBXCorrupted
+			}
+			id, size := plain[0], binary.LittleEndian.Uint32(plain[1:5])
+			if uint64(len(plain)-5) < uint64(size) {
+				return nil, ErrKDBXCorrupted
+			}
+			value := plain[5 : 5+size]
+			plain = plain[5+size:]
+			if id == kdbxInnerEndOfHeader {
+				break
+			}
+			switch id {
+			case kdbxInnerRandomStream:
+				if len(value) == 4 {
+					streamID = binary.LittleEndian.Uint32(value)
+				}
+			case kdbxInnerRandomStreamKey:
+				streamKey = value
+			case kdbxInnerBinary:
+				// Первый байт - флаги
+				if len(value) > 0 {
+					binaries = append(binaries, value[1:])
+				}
+			}
+		}
+		if binaries == nil {
+			binaries = [][]byte{}
+		}
+	}
+
+	document, err := unprotect(plain, streamID, streamKey)
+	if err != nil {
+		return nil, err
+	}
+	return parseKeePassXML(document, binaries)
+}
+
+// readKDBXHeader разбирает внешний заголовок и возвращает остаток файла
+func readKDBXHeader(data []byte) (kdbxHeader, []byte, error) {
+	if len(data) < 12 || !bytes.HasPrefix(data, kdbxSignature) {
+		return kdbxHeader{}, nil, ErrKDBX
+	}
+	header := kdbxHeader{major: binary.LittleEndian.Uint16(data[10:12]), fields: make(map[byte][]byte)}
+	if header.major != 3 && header.major != 4 {
+		return kdbxHeader{}, nil, fmt.Errorf("%w %d", ErrKDBXVersion, header.major)
+	}
+	// Размер поля: 2 байта в KDBX 3.1, 4 байта в KDBX 4
+	sizeLen := 2
+	if header.major == 4 {
+		sizeLen = 4
+	}
+	pos := 12
+	for {
+		if len(data) < pos+1+sizeLen {
+			return kdbxHeader{}, nil, ErrKDBX
+		}
+		id := data[pos]
+		var size int
+		if sizeLen == 2 {
+			size = int(binary.LittleEndian.Uint16(data[pos+1:]))
+		} else {
+			size = int(binary.LittleEndian.Uint32(data[pos+1:]))
+		}
+		pos += 1 + sizeLen
+		if size < 0 || len(data) < pos+size {
+			return kdbxHeader{}, nil, ErrKDBX
+		}
+		header.fields[id] = data[pos : pos+size]
+		pos += size
+		if id == kdbxEndOfHeader {
+			break
+		}
+	}
+	header.raw = data[:pos]
+	if flags := header.fields[kdbxCompressionFlags]; len(flags) == 4 {
+		header.compression = binary.LittleEndian.Uint32(flags)
+	}
+	if header.major == 4 {
+		var err error
+		if header.kdf, err = readVariantDictionary(header.fields[kdbxKdfParameters]); err != nil {
+			return kdbxHeader{}, nil, err
+		}
+	}
+	return header, data[pos:], nil
+}
+
+// readVariantDictionary разбирает параметры функции формирования ключа KDBX 4
+func readVariantDictionary(data []byte) (map[string][]byte, error) {
+	if len(data) < 2 {
+		return nil, ErrKDBX
+	}
+	dict := make(map[string][]byte)
+	pos := 2
+	for pos < len(data) {
+		kind := data[pos]
+		pos++
+		if kind == 0 {
+			return dict, nil
+		}
+		if len(data) < pos+4 {
+			return nil, ErrKDBX
+		}
+		keyLen := int(binary.LittleEndian.Uint32(data[pos:]))
+		pos += 4
+		if keyLen < 0 || len(data) < pos+keyLen+4 {
+			return nil, ErrKDBX
+		}
+		key := string(data[pos : pos+keyLen])
+		pos += keyLen
+		valueLen := int(binary.LittleEndian.Uint32(data[pos:]))
+		pos += 4
+		if valueLen < 0 || len(data) < pos+valueLen {
+			return nil, ErrKDBX
+		}
+		dict[key] = data[pos : pos+valueLen]
+		pos += valueLen
+	}
+	return nil, ErrKDBX
+}
+
+// compositeKey - составной ключ из пароля и файла ключа
+func compositeKey(password string, keyFile []byte) ([]byte, error) {
+	composite := sha256.New()
+	if password != "" || keyFile == nil {
+		sum := sha256.Sum256([]byte(password))
+		composite.Write(sum[:])
+	}
+	if keyFile != nil {
+		key, err := keyFileKey(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		composite.Write(key)
+	}
+	return composite.Sum(nil), nil
+}
+
+// keyFileKey возвращает ключ из файла ключа: XML 1.0 и 2.0, 32 байта, 64 шестнадцатеричных
+// символа или SHA-256 произвольного файла
+func keyFileKey(data []byte) ([]byte, error) {
+	if bytes.Contains(data[:min(len(data), 256)], []byte("<KeyFile")) {
+		var file struct {
+			Version string `xml:"Meta>Version"`
+			Data    string `xml:"Key>Data"`
+		}
+		if err := xml.Unmarshal(data, &file); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(file.Version, "2.") {
+			return hex.DecodeString(strings.Join(strings.Fields(file.Data), ""))
+		}
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(file.Data))
+	}
+	if len(data) == 32 {
+		return data, nil
+	}
+	if len(data) == 64 {
+		if key, err := hex.DecodeString(string(data)); err == nil {
+			return key, nil
+		}
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
+
+// transformKey применяет функцию формирования ключа
+func (h kdbxHeader) transformKey(composite []byte) ([]byte, error) {
+	if h.major == 3 {
+		return aesKDF(composite, h.fields[kdbxTransformSeed], h.fields[kdbxTransformRounds])
+	}
+	uuid := h.kdf["$UUID"]
+	switch {
+	case bytes.Equal(uuid, kdbxKdfAES):
+		return aesKDF(composite, h.kdf["S"], h.kdf["R"])
+	case bytes.Equal(uuid, kdbxKdfArgon2d), bytes.Equal(uuid, kdbxKdfArgon2id):
+		salt := h.kdf["S"]
+		parallelism, memory, iterations, version := h.kdf["P"], h.kdf["M"], h.kdf["I"], h.kdf["V"]
+		if len(parallelism) != 4 || len(memory) != 8 || len(iterations) != 8 || len(version) != 4 {
+			return nil, ErrKDBX
+		}
+		if binary.LittleEndian.Uint32(version) != argon2Version {
+			return nil, fmt.Errorf("%w: argon2 version %#x", ErrKDBXVersion, binary.LittleEndian.Uint32(version))
+		}
+		threads := binary.LittleEndian.Uint32(parallelism)
+		time := uint32(binary.LittleEndian.Uint64(iterations))
+		memoryKiB := uint32(binary.LittleEndian.Uint64(memory) / 1024)
+		if threads < 1 || threads > 255 || time < 1 {
+			return nil, ErrKDBX
+		}
+		if bytes.Equal(uuid, kdbxKdfArgon2id) {
+			return argon2.IDKey(composite, salt, time, memoryKiB, uint8(threads), 32), nil
+		}
+		return argon2d(composite, salt, h.kdf["K"], h.kdf["A"], time, memoryKiB, threads, 32), nil
+	}
+	return nil, fmt.Errorf("%w: key derivation %x", ErrKDBXCipher, uuid)
+}
+
+// aesKDF - преобразование ключа раундами AES-256-ECB
+func aesKDF(composite []byte, seed []byte, rounds []byte) ([]byte, error) {
+	if len(seed) != 32 || len(rounds) != 8 {
+		return nil, ErrKDBX
+	}
+	block, err := aes.NewCipher(seed)
+	if err != nil {
+		return nil, err
+	}
+	key := append([]byte{}, composite...)
+	for i := binary.LittleEndian.Uint64(rounds); i > 0; i-- {
+		block.Encrypt(key[:16], key[:16])
+		block.Encrypt(key[16:], key[16:])
+	}
+	sum := sha256.Sum256(key)
+	return sum[:], nil
+}
+
+// decrypt расшифровывает содержимое базы
+func (h kdbxHeader) decrypt(key []byte, data []byte) ([]byte, error) {
+	iv := h.fields[kdbxEncryptionIV]
+	cipherID := h.fields[kdbxCipherID]
+	switch {
+	case bytes.Equal(cipherID, kdbxCipherAES):
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(iv) != aes.BlockSize || len(data)%aes.BlockSize != 0 || len(data) == 0 {
+			return nil, ErrKDBXCorrupted
+		}
+		plain := make([]byte, len(data))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+		// PKCS#7. Неверное дополнение - признак неверного ключа
+		pad := int(plain[len(plain)-1])
+		if pad == 0 || pad > aes.BlockSize || !bytes.Equal(plain[len(plain)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+			return nil, ErrKey
+		}
+		return plain[:len(plain)-pad], nil
+	case bytes.Equal(cipherID, kdbxCipherChaCha20):
+		stream, err := chacha20.NewUnauthenticatedCipher(key, iv)
+		if err != nil {
+			return nil, err
+		}
+		plain := make([]byte, len(data))
+		stream.XORKeyStream(plain, data)
+		return plain, nil
+	}
+	return nil, fmt.Errorf("%w %x", ErrKDBXCipher, cipherID)
+}
+
+// readHashedBlocks собирает содержимое KDBX 3.1 из блоков с SHA-256
+func readHashedBlocks(data []byte) ([]byte, error) {
+	var out []byte
+	for {
+		if len(data) < 40 {
+			return nil, ErrKDBXCorrupted
+		}
+		hash, size := data[4:36], int(binary.LittleEndian.Uint32(data[36:40]))
+		data = data[40:]
+		if size == 0 {
+			return out, nil
+		}
+		if size < 0 || len(data) < size {
+			return nil, ErrKDBXCorrupted
+		}
+		if sum := sha256.Sum256(data[:size]); !bytes.Equal(sum[:], hash) {
+			return nil, ErrKDBXCorrupted
+		}
+		out = append(out, data[:size]...)
+		data = data[size:]
+	}
+}
+
+// readHMACBlocks собирает содержимое KDBX 4 из блоков с HMAC-SHA-256
+func readHMACBlocks(hmacBase []byte, data []byte) ([]byte, error) {
+	var out []byte
+	for index := uint64(0); ; index++ {
+		if len(data) < 36 {
+			return nil, ErrKDBXCorrupted
+		}
+		mac, size := data[:32], int(binary.LittleEndian.Uint32(data[32:36]))
+		if size < 0 || len(data) < 36+size {
+			return nil, ErrKDBXCorrupted
+		}
+		if !hmac.Equal(kdbxHMAC(hmacBase, index, data[32:36+size]), mac) {
+			return nil, ErrKDBXCorrupted
+		}
+		if size == 0 {
+			return out, nil
+		}
+		out = append(out, data[36:36+size]...)
+		data = data[36+size:]
+	}
+}
+
+// kdbxHMAC - HMAC-SHA-256 блока: номер блока, размер и данные
+func kdbxHMAC(hmacBase []byte, index uint64, data []byte) []byte {
+	mac := hmac.New(sha256.New, kdbxBlockKey(hmacBase, index))
+	mac.Write(binary.LittleEndian.AppendUint64(nil, index))
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// kdbxBlockKey - ключ HMAC блока. Заголовок подписывается ключом блока 2^64-1
+func kdbxBlockKey(hmacBase []byte, index uint64) []byte {
+	key := sha512.Sum512(append(binary.LittleEndian.AppendUint64(nil, index), hmacBase...))
+	return key[:]
+}
+
+// unprotect расшифровывает защищенные значения документа. Гамма общая для всех
+// значений и расходуется в порядке следования в документе, включая историю записей
+func unprotect(document []byte, streamID uint32, streamKey []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	var tokens []xml.Token
+	// Индексы защищенных значений в tokens и их шифротекст
+	var protected []int
+	var ciphertext [][]byte
+	inProtected := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		token = xml.CopyToken(token)
+		switch t := token.(type) {
+		case xml.StartElement:
+			inProtected = t.Name.Local == "Value" && isTrue(attr(t, "Protected"))
+			if inProtected {
+				tokens = append(tokens, t)
+				protected = append(protected, len(tokens))
+				ciphertext = append(ciphertext, nil)
+				tokens = append(tokens, xml.CharData(nil))
+				continue
+			}
+		case xml.CharData:
+			if inProtected {
+				value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(t)))
+				if err != nil {
+					return nil, ErrKDBXCorrupted
+				}
+				last := len(ciphertext) - 1
+				ciphertext[last] = append(ciphertext[last], value...)
+				continue
+			}
+		case xml.EndElement:
+			inProtected = false
+		}
+		tokens = append(tokens, token)
+	}
+	if len(protected) == 0 {
+		return document, nil
+	}
+
+	var total int
+	for _, value := range ciphertext {
+		total += len(value)
+	}
+	keystream, err := innerKeystream(streamID, streamKey, total)
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range ciphertext {
+		for j := range value {
+			value[j] ^= keystream[j]
+		}
+		keystream = keystream[len(value):]
+		tokens[protected[i]] = xml.CharData(value)
+	}
+
+	var out bytes.Buffer
+	encoder := xml.NewEncoder(&out)
+	for _, token := range tokens {
+		if err := encoder.EncodeToken(token); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// innerKeystream возвращает гамму шифра защищенных значений
+func innerKeystream(streamID uint32, key []byte, n int) ([]byte, error) {
+	keystream := make([]byte, n)
+	switch streamID {
+	case kdbxStreamNone:
+	case kdbxStreamSalsa20:
+		salsaKey := sha256.Sum256(key)
+		salsa20.XORKeyStream(keystream, keystream, salsa20Nonce, &salsaKey)
+	case kdbxStreamChaCha20:
+		hash := sha512.Sum512(key)
+		stream, err := chacha20.NewUnauthenticatedCipher(hash[:32], hash[32:44])
+		if err != nil {
+			return nil, err
+		}
+		stream.XORKeyStream(keystream, keystream)
+	default:
+		return nil, fmt.Errorf("%w: inner stream %d", ErrKDBXCipher, streamID)
+	}
+	return keystream, nil
+}
+
+// attr возвращает значение атрибута элемента
+func attr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// mustHex
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
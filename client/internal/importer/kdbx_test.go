@@ -0,0 +1,261 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/chacha20"
+)
+
+// keepassDocument - документ с защищенными значениями {{P0}}..{{P3}} и вложениями {{BIN}}
+const keepassDocument = `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta>
+		<RecycleBinEnabled>True</RecycleBinEnabled>
+		<RecycleBinUUID>bin</RecycleBinUUID>
+		{{BIN}}
+	</Meta>
+	<Root>
+		<Group>
+			<UUID>root</UUID>
+			<Name>Database</Name>
+			<Group>
+				<UUID>mail</UUID>
+				<Name>Mail</Name>
+				<Entry>
+					<Tags>work;mail</Tags>
+					<String><Key>Title</Key><Value>Google</Value></String>
+					<String><Key>UserName</Key><Value>bob</Value></String>
+					<String><Key>Password</Key><Value Protected="True">{{P0}}</Value></String>
+					<String><Key>URL</Key><Value>https://mail.google.com</Value></String>
+					<String><Key>Recovery</Key><Value Protected="True">{{P1}}</Value></String>
+					<History>
+						<Entry><String><Key>Password</Key><Value Protected="True">{{P2}}</Value></String></Entry>
+					</History>
+				</Entry>
+			</Group>
+			<Entry>
+				<String><Key>Title</Key><Value>Bank</Value></String>
+				<String><Key>Password</Key><Value Protected="True">{{P3}}</Value></String>
+			</Entry>
+			<Entry>
+				<String><Key>Title</Key><Value>Notes</Value></String>
+				<String><Key>Notes</Key><Value>door code 1234</Value></String>
+			</Entry>
+			<Entry>
+				<String><Key>Title</Key><Value>Key file</Value></String>
+				<Binary><Key>id_rsa</Key><Value Ref="0"/></Binary>
+			</Entry>
+			<Group>
+				<UUID>bin</UUID>
+				<Name>Recycle Bin</Name>
+				<Entry><String><Key>Title</Key><Value>Deleted</Value></String></Entry>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>`
+
+var keepassSecrets = []string{"g00gle", "recovery-code", "old-password", "b4nk"}
+
+// requireKeePassUnits проверяет единицы данных документа keepassDocument
+func requireKeePassUnits(t *testing.T, units []model.Unit) {
+	got := byName(t, units)
+	require.Len(t, got, 4)
+
+	google := got["Mail/Google"]
+	require.Equal(t, model.LoginData{Login: "bob", Password: "g00gle", URL: "https://mail.google.com"}, model.ParseLogin(google.Body.Data))
+	require.Equal(t, []string{"work", "mail"}, google.Body.Meta.Tags)
+	require.Equal(t, []model.Field{{Key: "Recovery", Value: "recovery-code", Sensitive: true}}, google.Body.Meta.Fields)
+	require.Equal(t, "b4nk", model.ParseLogin(got["Bank"].Body.Data).Password)
+	require.Equal(t, model.UnitTypeText, got["Notes"].Body.Meta.Type)
+	require.Equal(t, "door code 1234", string(got["Notes"].Body.Data))
+	require.Equal(t, model.UnitTypeBinary, got["Key file"].Body.Meta.Type)
+	require.Equal(t, "PRIVATE KEY", string(got["Key file"].Body.Data))
+}
+
+// protectDocument подставляет защищенные значения и вложения в документ
+func protectDocument(t *testing.T, streamID uint32, key []byte, binaries string) string {
+	var total int
+	for _, secret := range keepassSecrets {
+		total += len(secret)
+	}
+	keystream, err := innerKeystream(streamID, key, total)
+	require.NoError(t, err)
+	replacements := []string{"{{BIN}}", binaries}
+	for i, secret := range keepassSecrets {
+		value := []byte(secret)
+		for j := range value {
+			value[j] ^= keystream[j]
+		}
+		keystream = keystream[len(value):]
+		replacements = append(replacements, "{{P"+string(rune('0'+i))+"}}", base64.StdEncoding.EncodeToString(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(keepassDocument)
+}
+
+// kdbxField - поле заголовка
+func kdbxField(buf *bytes.Buffer, major uint16, id byte, value []byte) {
+	buf.WriteByte(id)
+	if major == 3 {
+		binary.Write(buf, binary.LittleEndian, uint16(len(value)))
+	} else {
+		binary.Write(buf, binary.LittleEndian, uint32(len(value)))
+	}
+	buf.Write(value)
+}
+
+func le32(v uint32) []byte { return binary.LittleEndian.AppendUint32(nil, v) }
+func le64(v uint64) []byte { return binary.LittleEndian.AppendUint64(nil, v) }
+
+func random(t *testing.T, n int) []byte {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	return b
+}
+
+// writeKDBX3 - KDBX 3.1: AES-KDF, AES-256-CBC, Salsa20, вложения в метаданных
+func writeKDBX3(t *testing.T, password string) []byte {
+	masterSeed, transformSeed, iv := random(t, 32), random(t, 32), random(t, 16)
+	streamKey, startBytes := random(t, 32), random(t, 32)
+	var header bytes.Buffer
+	header.Write(kdbxSignature)
+	header.Write(le32(0x00030001))
+	kdbxField(&header, 3, kdbxCipherID, kdbxCipherAES)
+	kdbxField(&header, 3, kdbxCompressionFlags, le32(0))
+	kdbxField(&header, 3, kdbxMasterSeed, masterSeed)
+	kdbxField(&header, 3, kdbxTransformSeed, transformSeed)
+	kdbxField(&header, 3, kdbxTransformRounds, le64(1000))
+	kdbxField(&header, 3, kdbxEncryptionIV, iv)
+	kdbxField(&header, 3, kdbxProtectedStreamKey, streamKey)
+	kdbxField(&header, 3, kdbxStreamStartBytes, startBytes)
+	kdbxField(&header, 3, kdbxInnerRandomStreamID, le32(kdbxStreamSalsa20))
+	kdbxField(&header, 3, kdbxEndOfHeader, []byte("\r\n\r\n"))
+
+	binaries := `<Binaries><Binary ID="0">` + base64.StdEncoding.EncodeToString([]byte("PRIVATE KEY")) + `</Binary></Binaries>`
+	document := []byte(protectDocument(t, kdbxStreamSalsa20, streamKey, binaries))
+	hash := sha256.Sum256(document)
+	plain := append([]byte{}, startBytes...)
+	plain = append(append(append(append(plain, le32(0)...), hash[:]...), le32(uint32(len(document)))...), document...)
+	plain = append(append(append(plain, le32(1)...), make([]byte, 32)...), le32(0)...)
+	pad := aes.BlockSize - len(plain)%aes.BlockSize
+	plain = append(plain, bytes.Repeat([]byte{byte(pad)}, pad)...)
+
+	composite, err := compositeKey(password, nil)
+	require.NoError(t, err)
+	transformed, err := aesKDF(composite, transformSeed, le64(1000))
+	require.NoError(t, err)
+	masterKey := sha256.Sum256(append(masterSeed, transformed...))
+	block, err := aes.NewCipher(masterKey[:])
+	require.NoError(t, err)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(plain, plain)
+	return append(header.Bytes(), plain...)
+}
+
+// writeKDBX4 - KDBX 4: Argon2d, ChaCha20, сжатие, вложения во внутреннем заголовке
+func writeKDBX4(t *testing.T, password string) []byte {
+	masterSeed, salt, iv, streamKey := random(t, 32), random(t, 32), random(t, 12), random(t, 64)
+	var kdf bytes.Buffer
+	kdf.Write([]byte{0x00, 0x01})
+	item := func(kind byte, key string, value []byte) {
+		kdf.WriteByte(kind)
+		kdf.Write(le32(uint32(len(key))))
+		kdf.WriteString(key)
+		kdf.Write(le32(uint32(len(value))))
+		kdf.Write(value)
+	}
+	item(0x42, "$UUID", kdbxKdfArgon2d)
+	item(0x42, "S", salt)
+	item(0x04, "P", le32(2))
+	item(0x05, "M", le64(64*1024))
+	item(0x05, "I", le64(2))
+	item(0x04, "V", le32(argon2Version))
+	kdf.WriteByte(0)
+
+	var header bytes.Buffer
+	header.Write(kdbxSignature)
+	header.Write(le32(0x00040001))
+	kdbxField(&header, 4, kdbxCipherID, kdbxCipherChaCha20)
+	kdbxField(&header, 4, kdbxCompressionFlags, le32(1))
+	kdbxField(&header, 4, kdbxMasterSeed, masterSeed)
+	kdbxField(&header, 4, kdbxEncryptionIV, iv)
+	kdbxField(&header, 4, kdbxKdfParameters, kdf.Bytes())
+	kdbxField(&header, 4, kdbxEndOfHeader, []byte("\r\n\r\n"))
+
+	var inner bytes.Buffer
+	kdbxField(&inner, 4, kdbxInnerRandomStream, le32(kdbxStreamChaCha20))
+	kdbxField(&inner, 4, kdbxInnerRandomStreamKey, streamKey)
+	kdbxField(&inner, 4, kdbxInnerBinary, append([]byte{1}, "PRIVATE KEY"...))
+	kdbxField(&inner, 4, kdbxInnerEndOfHeader, nil)
+	inner.WriteString(protectDocument(t, kdbxStreamChaCha20, streamKey, ""))
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write(inner.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	composite, err := compositeKey(password, nil)
+	require.NoError(t, err)
+	transformed := argon2d(composite, salt, nil, nil, 2, 64, 2, 32)
+	masterKey := sha256.Sum256(append(append([]byte{}, masterSeed...), transformed...))
+	stream, err := chacha20.NewUnauthenticatedCipher(masterKey[:], iv)
+	require.NoError(t, err)
+	encrypted := compressed.Bytes()
+	stream.XORKeyStream(encrypted, encrypted)
+
+	hmacBase := sha512.Sum512(append(append(append([]byte{}, masterSeed...), transformed...), 1))
+	out := append([]byte{}, header.Bytes()...)
+	hash := sha256.Sum256(header.Bytes())
+	mac := hmac.New(sha256.New, kdbxBlockKey(hmacBase[:], ^uint64(0)))
+	mac.Write(header.Bytes())
+	out = append(append(out, hash[:]...), mac.Sum(nil)...)
+	for index, data := range [][]byte{encrypted, nil} {
+		sized := append(le32(uint32(len(data))), data...)
+		out = append(append(out, kdbxHMAC(hmacBase[:], uint64(index), sized)...), sized...)
+	}
+	return out
+}
+
+func TestParse_KeePass(t *testing.T) {
+	binaries := `<Binaries><Binary ID="0">` + base64.StdEncoding.EncodeToString([]byte("PRIVATE KEY")) + `</Binary></Binaries>`
+	document := strings.NewReplacer("{{BIN}}", binaries, "{{P0}}", "g00gle", "{{P1}}", "recovery-code",
+		"{{P2}}", "old-password", "{{P3}}", "b4nk", `Protected="True"`, `ProtectInMemory="True"`).Replace(keepassDocument)
+	units, err := Parse(FormatKeePassXML, []byte(document), Options{})
+	require.NoError(t, err)
+	requireKeePassUnits(t, units)
+
+	for name, data := range map[string][]byte{"kdbx3": writeKDBX3(t, "master"), "kdbx4": writeKDBX4(t, "master")} {
+		t.Run(name, func(t *testing.T) {
+			format, err := Detect("db", data)
+			require.NoError(t, err)
+			require.Equal(t, FormatKDBX, format)
+
+			units, err := Parse(FormatKDBX, data, Options{Password: "master"})
+			require.NoError(t, err)
+			requireKeePassUnits(t, units)
+
+			_, err = Parse(FormatKDBX, data, Options{Password: "wrong"})
+			require.ErrorIs(t, err, ErrKey)
+		})
+	}
+}
+
+func TestArgon2d(t *testing.T) {
+	// RFC 9106, 5.1
+	key := argon2d(bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 16),
+		bytes.Repeat([]byte{3}, 8), bytes.Repeat([]byte{4}, 12), 3, 32, 4, 32)
+	require.Equal(t, "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb", hex.EncodeToString(key))
+}
@@ -0,0 +1,190 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+)
+
+// Документ KeePass XML. Значения защищенных строк уже расшифрованы
+type kpFile struct {
+	Meta struct {
+		RecycleBinEnabled string     `xml:"RecycleBinEnabled"`
+		RecycleBinUUID    string     `xml:"RecycleBinUUID"`
+		Binaries          []kpBinary `xml:"Binaries>Binary"`
+	} `xml:"Meta"`
+	Root struct {
+		Group kpGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+// kpBinary - вложение в метаданных (KDBX 3.1 и XML)
+type kpBinary struct {
+	ID         string `xml:"ID,attr"`
+	Compressed string `xml:"Compressed,attr"`
+	Value      string `xml:",chardata"`
+}
+
+type kpGroup struct {
+	UUID    string    `xml:"UUID"`
+	Name    string    `xml:"Name"`
+	Entries []kpEntry `xml:"Entry"`
+	Groups  []kpGroup `xml:"Group"`
+}
+
+// kpEntry - запись. История изменений не импортируется
+type kpEntry struct {
+	Tags  string `xml:"Tags"`
+	Times struct {
+		ExpiryTime string `xml:"ExpiryTime"`
+		Expires    string `xml:"Expires"`
+	} `xml:"Times"`
+	Strings  []kpString `xml:"String"`
+	Binaries []kpRef    `xml:"Binary"`
+}
+
+type kpString struct {
+	Key   string `xml:"Key"`
+	Value struct {
+		Value           string `xml:",chardata"`
+		Protected       string `xml:"Protected,attr"`
+		ProtectInMemory string `xml:"ProtectInMemory,attr"`
+	} `xml:"Value"`
+}
+
+type kpRef struct {
+	Key   string `xml:"Key"`
+	Value struct {
+		Ref string `xml:"Ref,attr"`
+	} `xml:"Value"`
+}
+
+// Стандартные строки записи KeePass
+var kpStandard = map[string]bool{"Title": true, "UserName": true, "Password": true, "URL": true, "Notes": true}
+
+// parseKeePassXML разбирает документ KeePass XML.
+// binaries - вложения из внутреннего заголовка KDBX 4; nil - вложения в метаданных документа
+func parseKeePassXML(data []byte, binaries [][]byte) ([]entry, error) {
+	var file kpFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	attachments := make(map[string][]byte)
+	for i, b := range binaries {
+		attachments[strconv.Itoa(i)] = b
+	}
+	if binaries == nil {
+		for _, b := range file.Meta.Binaries {
+			value, err := kpBinaryValue(b)
+			if err != nil {
+				return nil, err
+			}
+			attachments[b.ID] = value
+		}
+	}
+	recycleBin := ""
+	if isTrue(file.Meta.RecycleBinEnabled) {
+		recycleBin = file.Meta.RecycleBinUUID
+	}
+
+	var entries []entry
+	var walk func(group kpGroup, path []string)
+	walk = func(group kpGroup, path []string) {
+		if recycleBin != "" && group.UUID == recycleBin {
+			return
+		}
+		for _, e := range group.Entries {
+			entries = append(entries, kpEntries(e, path, attachments)...)
+		}
+		for _, child := range group.Groups {
+			walk(child, append(path[:len(path):len(path)], child.Name))
+		}
+	}
+	// Корневая группа - сама база
+	walk(file.Root.Group, nil)
+	return entries, nil
+}
+
+// kpEntries преобразует запись KeePass в единицы данных: основную и вложения
+func kpEntries(e kpEntry, path []string, attachments map[string][]byte) []entry {
+	values := make(map[string]string)
+	main := entry{folder: path, meta: model.UnitMeta{Tags: splitTags(e.Tags)}}
+	for _, s := range e.Strings {
+		if kpStandard[s.Key] {
+			values[s.Key] = s.Value.Value
+			continue
+		}
+		sensitive := isTrue(s.Value.Protected) || isTrue(s.Value.ProtectInMemory)
+		main.meta.Fields = append(main.meta.Fields, model.Field{Key: s.Key, Value: s.Value.Value, Sensitive: sensitive})
+	}
+	main.title = values["Title"]
+	if isTrue(e.Times.Expires) {
+		main.meta.ExpiresAt = kpTime(e.Times.ExpiryTime)
+	}
+
+	var files []entry
+	for _, ref := range e.Binaries {
+		value, ok := attachments[ref.Value.Ref]
+		if !ok {
+			continue
+		}
+		files = append(files, entry{folder: path, title: main.title + " - " + ref.Key,
+			meta: model.UnitMeta{Type: model.UnitTypeBinary, Tags: main.meta.Tags}, data: value})
+	}
+
+	switch {
+	case values["UserName"] != "" || values["Password"] != "" || values["URL"] != "":
+		main.meta.Type = model.UnitTypeLogin
+		main.meta.Description = values["Notes"]
+		main.data = login(values["UserName"], values["Password"], values["URL"])
+	case len(files) == 1 && values["Notes"] == "" && len(main.meta.Fields) == 0:
+		// Запись только с вложением - бинарная единица данных
+		files[0].title = main.title
+		files[0].meta.ExpiresAt = main.meta.ExpiresAt
+		return files
+	default:
+		main.meta.Type = model.UnitTypeText
+		main.data = []byte(values["Notes"])
+	}
+	return append([]entry{main}, files...)
+}
+
+// kpBinaryValue декодирует вложение из метаданных
+func kpBinaryValue(b kpBinary) ([]byte, error) {
+	value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(b.Value))
+	if err != nil || !isTrue(b.Compressed) {
+		return value, err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(value))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+// kpTime разбирает время: ISO 8601 или, в KDBX 4, секунды от 0001-01-01 в base64
+func kpTime(s string) time.Time {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(raw) != 8 {
+		return time.Time{}
+	}
+	// Секунды между 0001-01-01 и 1970-01-01
+	const unixOffset = 62135596800
+	return time.Unix(int64(binary.LittleEndian.Uint64(raw))-unixOffset, 0).UTC()
+}
+
+// isTrue
+func isTrue(s string) bool {
+	return strings.EqualFold(s, "true")
+}
@@ -0,0 +1,236 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+)
+
+// Категории записей 1Password
+const (
+	opLogin      = "001"
+	opCreditCard = "002"
+	opPassword   = "005"
+	opDocument   = "006"
+)
+
+// opArchived - состояние записи в архиве 1Password
+const opArchived = "archived"
+
+var (
+	Err1PUX = errors.New("invalid 1PUX file: export.data not found")
+)
+
+// opExport - содержимое export.data
+type opExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Attrs struct {
+				Name string `json:"name"`
+			} `json:"attrs"`
+			Items []opItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+type opItem struct {
+	State        string `json:"state"`
+	CategoryUUID string `json:"categoryUuid"`
+	Details      struct {
+		LoginFields []struct {
+			Value       string `json:"value"`
+			Designation string `json:"designation"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		Password   string `json:"password"`
+		Sections   []struct {
+			Fields []struct {
+				Title string                     `json:"title"`
+				ID    string                     `json:"id"`
+				Value map[string]json.RawMessage `json:"value"`
+			} `json:"fields"`
+		} `json:"sections"`
+		DocumentAttributes *struct {
+			FileName   string `json:"fileName"`
+			DocumentID string `json:"documentId"`
+		} `json:"documentAttributes"`
+	} `json:"details"`
+	Overview struct {
+		Title string   `json:"title"`
+		URL   string   `json:"url"`
+		Tags  []string `json:"tags"`
+	} `json:"overview"`
+}
+
+// parse1PUX разбирает архив 1Password 1PUX. Хранилища становятся папками
+func parse1PUX(data []byte) ([]entry, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+	exportFile, ok := files["export.data"]
+	if !ok {
+		return nil, Err1PUX
+	}
+	exportData, err := readZipFile(exportFile)
+	if err != nil {
+		return nil, err
+	}
+	var export opExport
+	if err := json.Unmarshal(exportData, &export); err != nil {
+		return nil, err
+	}
+
+	var entries []entry
+	for _, account := range export.Accounts {
+		for _, vault := range account.Vaults {
+			for _, item := range vault.Items {
+				e, err := opEntry(item, files)
+				if err != nil {
+					return nil, err
+				}
+				e.folder = []string{vault.Attrs.Name}
+				entries = append(entries, e)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// opEntry преобразует запись 1Password
+func opEntry(item opItem, files map[string]*zip.File) (entry, error) {
+	e := entry{title: item.Overview.Title, meta: model.UnitMeta{Tags: item.Overview.Tags}}
+	if item.State == opArchived {
+		e.meta.Tags = append(e.meta.Tags, opArchived)
+	}
+	var card model.CardData
+	for _, section := range item.Details.Sections {
+		for _, field := range section.Fields {
+			kind, value := opValue(field.Value)
+			if value == "" {
+				continue
+			}
+			if item.CategoryUUID == opCreditCard {
+				// Поля карты переносятся в данные
+				switch field.ID {
+				case "ccnum":
+					card.Number = value
+					continue
+				case "cvv":
+					card.CVV = value
+					continue
+				case "cardholder":
+					card.Holder = value
+					continue
+				case "expiry":
+					card.Expiry = value
+					continue
+				}
+			}
+			key := field.Title
+			if key == "" {
+				key = field.ID
+			}
+			sensitive := kind == "concealed" || kind == "totp" || kind == "creditCardNumber"
+			e.meta.Fields = append(e.meta.Fields, model.Field{Key: key, Value: value, Sensitive: sensitive})
+		}
+	}
+
+	switch item.CategoryUUID {
+	case opLogin, opPassword:
+		e.meta.Type = model.UnitTypeLogin
+		e.meta.Description = item.Details.NotesPlain
+		var username string
+		password := item.Details.Password
+		for _, field := range item.Details.LoginFields {
+			switch field.Designation {
+			case "username":
+				username = field.Value
+			case "password":
+				password = field.Value
+			}
+		}
+		e.data = login(username, password, item.Overview.URL)
+	case opCreditCard:
+		e.meta.Type = model.UnitTypeCard
+		e.meta.Description = item.Details.NotesPlain
+		e.data = card.Bytes()
+	case opDocument:
+		attrs := item.Details.DocumentAttributes
+		if attrs == nil {
+			return entry{}, fmt.Errorf("%w: document %q without attributes", Err1PUX, item.Overview.Title)
+		}
+		file, ok := files["files/"+attrs.DocumentID+"__"+attrs.FileName]
+		if !ok {
+			return entry{}, fmt.Errorf("%w: document %q not found", Err1PUX, attrs.FileName)
+		}
+		content, err := readZipFile(file)
+		if err != nil {
+			return entry{}, err
+		}
+		e.meta.Type = model.UnitTypeBinary
+		e.meta.Description = item.Details.NotesPlain
+		e.data = content
+	default:
+		// Заметки и прочие категории - текст, поля разделов - пользовательские поля
+		e.meta.Type = model.UnitTypeText
+		e.data = []byte(item.Details.NotesPlain)
+	}
+	return e, nil
+}
+
+// opValue возвращает вид и строковое значение поля 1Password
+func opValue(value map[string]json.RawMessage) (string, string) {
+	for kind, raw := range value {
+		switch kind {
+		case "monthYear":
+			// YYYYMM
+			var n int
+			if json.Unmarshal(raw, &n) != nil || n < 100 {
+				return kind, ""
+			}
+			return kind, fmt.Sprintf("%02d/%02d", n%100, n/100%100)
+		case "date":
+			var n int64
+			if json.Unmarshal(raw, &n) != nil {
+				return kind, ""
+			}
+			return kind, time.Unix(n, 0).UTC().Format(time.DateOnly)
+		case "email":
+			var email struct {
+				Address string `json:"email_address"`
+			}
+			if json.Unmarshal(raw, &email) == nil {
+				return kind, email.Address
+			}
+		}
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			return kind, s
+		}
+		// Составные значения (адрес, ключ SSH) сохраняются как JSON
+		return kind, strings.TrimSpace(string(raw))
+	}
+	return "", ""
+}
+
+// readZipFile
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// LoginData - полезные данные единицы типа UnitTypeLogin
+type LoginData struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+	URL      string `json:"url,omitempty"`
+}
+
+// CardData - полезные данные единицы типа UnitTypeCard
+type CardData struct {
+	Number string `json:"number"`
+	Holder string `json:"holder,omitempty"`
+	// Срок действия MM/YY
+	Expiry string `json:"expiry,omitempty"`
+	CVV    string `json:"cvv,omitempty"`
+}
+
+// ParseLogin разбирает данные входа. Данные, записанные строкой, считаются паролем
+func ParseLogin(data []byte) LoginData {
+	var login LoginData
+	if err := json.Unmarshal(data, &login); err != nil {
+		return LoginData{Password: string(data)}
+	}
+	return login
+}
+
+// Bytes
+func (l LoginData) Bytes() []byte {
+	data, _ := json.Marshal(l)
+	return data
+}
+
+// ParseCard разбирает данные карты. Данные, записанные строкой, считаются номером
+func ParseCard(data []byte) CardData {
+	var card CardData
+	if err := json.Unmarshal(data, &card); err != nil {
+		return CardData{Number: string(data)}
+	}
+	return card
+}
+
+// Bytes
+func (c CardData) Bytes() []byte {
+	data, _ := json.Marshal(c)
+	return data
+}
+
+// MaskedNumber возвращает номер карты, в котором видны только последние 4 цифры
+func (c CardData) MaskedNumber() string {
+	digits := strings.ReplaceAll(c.Number, " ", "")
+	if len(digits) <= 4 {
+		return digits
+	}
+	return strings.Repeat("•", len(digits)-4) + digits[len(digits)-4:]
+}
+
+// TypeName возвращает название типа единицы данных
+func TypeName(unitType int) string {
+	switch unitType {
+	case UnitTypeLogin:
+		return "login"
+	case UnitTypeText:
+		return "text"
+	case UnitTypeBinary:
+		return "binary"
+	case UnitTypeCard:
+		return "card"
+	default:
+		return "unknown"
+	}
+}
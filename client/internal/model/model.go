@@ -1,6 +1,8 @@
 package model
 
 import (
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -18,8 +20,198 @@ type UnitBody struct {
 
 // UnitMeta - метаданные единицы данных
 type UnitMeta struct {
-	Type       int       `json:"type"`
-	ValidUntil time.Time `json:"validuntil"`
+	Type        int       `json:"type"`
+	ValidUntil  time.Time `json:"validuntil"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Fields      []Field   `json:"fields,omitempty"`
+	CreatedAt   time.Time `json:"createdat"`
+	UpdatedAt   time.Time `json:"updatedat"`
+	// Срок действия. Нулевое значение - бессрочно
+	ExpiresAt time.Time `json:"expiresat"`
+	// Периодичность смены от даты последнего изменения. 0 - без напоминаний
+	RotateEvery time.Duration `json:"rotateevery,omitempty"`
+}
+
+// Причины, по которым единица данных требует внимания
+const (
+	DueExpires = "expires"
+	DueRotate  = "rotate"
+)
+
+// DueAt возвращает ближайшую дату истечения срока действия или плановой смены
+// и ее причину. Нулевая дата - напоминаний нет
+func (m UnitMeta) DueAt() (time.Time, string) {
+	var dueAt time.Time
+	var reason string
+	if !m.ExpiresAt.IsZero() {
+		dueAt, reason = m.ExpiresAt, DueExpires
+	}
+	if m.RotateEvery > 0 {
+		rotateAt := m.UpdatedAt.Add(m.RotateEvery)
+		if dueAt.IsZero() || rotateAt.Before(dueAt) {
+			dueAt, reason = rotateAt, DueRotate
+		}
+	}
+	return dueAt, reason
+}
+
+// Field - пользовательское поле единицы данных.
+// Чувствительные поля шифруются на сервере
+type Field struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+}
+
+// Rename - переименование единицы данных или папки
+type Rename struct {
+	From string
+	To   string
+}
+
+// Changes - изменения на сервере после курсора синхронизации
+type Changes struct {
+	// Новый курсор
+	Cursor int64
+	// Полный снимок: Units содержит все данные
+	Full bool
+	// Созданные и измененные единицы данных
+	Units []Unit
+	// Токены имен удаленных единиц данных
+	Deleted []string
+}
+
+// SyncResult - итог синхронизации кэша
+type SyncResult struct {
+	Created int
+	Updated int
+	Deleted int
+	// Отправлено изменений из очереди
+	Replayed int
+	// Изменений в очереди, ожидающих разрешения конфликта
+	Conflicts int
+}
+
+// EventKind - вид события наблюдения за изменениями
+type EventKind int
+
+const (
+	EventDisconnected EventKind = -1
+	EventSubscribed   EventKind = 0
+	EventCreated      EventKind = 1
+	EventUpdated      EventKind = 2
+	EventDeleted      EventKind = 3
+)
+
+// String
+func (k EventKind) String() string {
+	switch k {
+	case EventDisconnected:
+		return "disconnected"
+	case EventSubscribed:
+		return "subscribed"
+	case EventCreated:
+		return "created"
+	case EventUpdated:
+		return "updated"
+	case EventDeleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// Event - событие об изменении данных на сервере
+type Event struct {
+	Kind EventKind
+	// Открытое имя (пусто, если неизвестно)
+	Name string
+	// Токен имени
+	Token string
+	// Причина отключения и задержка до повторного подключения (EventDisconnected)
+	Err   error
+	Retry time.Duration
+}
+
+// Виды отложенных операций
+const (
+	OpWrite  = "write"
+	OpDelete = "delete"
+)
+
+// Способы разрешения конфликта отложенной операции
+const (
+	// Применить свою версию поверх серверной
+	ResolveMine = "mine"
+	// Отказаться от своей версии
+	ResolveTheirs = "theirs"
+	// Сохранить свою версию под новым именем
+	ResolveBoth = "both"
+)
+
+// Op - изменение, сделанное без связи с сервером и ожидающее отправки
+type Op struct {
+	ID   int64  `json:"id"`
+	Kind string `json:"kind"`
+	// Единица данных; для удаления - только имя
+	Unit Unit `json:"unit"`
+	// Дата изменения версии, на основе которой сделано изменение.
+	// Нулевая - единица данных не была известна клиенту
+	Base     time.Time `json:"base"`
+	QueuedAt time.Time `json:"queuedat"`
+	// Конфликт с более новой версией на сервере
+	Conflict *Conflict `json:"conflict,omitempty"`
+}
+
+// Conflict - состояние единицы данных на сервере при обнаружении конфликта
+type Conflict struct {
+	ServerUpdatedAt time.Time `json:"serverupdatedat"`
+	ServerDeleted   bool      `json:"serverdeleted"`
+}
+
+// TrashItem - единица данных в корзине
+type TrashItem struct {
+	ID        int64
+	Name      string
+	Type      int
+	DeletedAt time.Time
+}
+
+// SearchQuery - условия поиска единиц данных. Пустые условия не ограничивают выборку
+type SearchQuery struct {
+	// Подстрока имени без учета регистра
+	Name string
+	// Тип единицы данных
+	Type int
+	// Все перечисленные теги
+	Tags []string
+	// Поля по ключу; при непустом значении - и по значению
+	Fields []Field
+}
+
+// Match проверяет единицу данных на соответствие условиям поиска
+func (q SearchQuery) Match(unit Unit) bool {
+	if q.Name != "" && !strings.Contains(strings.ToLower(unit.Name), strings.ToLower(q.Name)) {
+		return false
+	}
+	if q.Type != 0 && q.Type != unit.Body.Meta.Type {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !slices.Contains(unit.Body.Meta.Tags, tag) {
+			return false
+		}
+	}
+	for _, cond := range q.Fields {
+		found := slices.ContainsFunc(unit.Body.Meta.Fields, func(field Field) bool {
+			return field.Key == cond.Key && (cond.Value == "" || (!field.Sensitive && field.Value == cond.Value))
+		})
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 const (
@@ -28,3 +220,10 @@ const (
 	UnitTypeBinary = 3
 	UnitTypeCard   = 4
 )
+
+// Vault - хранилище единиц данных для помощников учетных данных
+type Vault interface {
+	Units() ([]Unit, error)
+	Write(unit Unit) error
+	Delete(unitname string) error
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitMeta_DueAt(t *testing.T) {
+	updated := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expires := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		meta       UnitMeta
+		wantAt     time.Time
+		wantReason string
+	}{
+		{
+			name: "no schedule",
+			meta: UnitMeta{UpdatedAt: updated},
+		},
+		{
+			name:       "expires",
+			meta:       UnitMeta{UpdatedAt: updated, ExpiresAt: expires},
+			wantAt:     expires,
+			wantReason: DueExpires,
+		},
+		{
+			name:       "rotation first",
+			meta:       UnitMeta{UpdatedAt: updated, ExpiresAt: expires, RotateEvery: 10 * 24 * time.Hour},
+			wantAt:     updated.Add(10 * 24 * time.Hour),
+			wantReason: DueRotate,
+		},
+		{
+			name:       "expiry first",
+			meta:       UnitMeta{UpdatedAt: updated, ExpiresAt: expires, RotateEvery: 90 * 24 * time.Hour},
+			wantAt:     expires,
+			wantReason: DueExpires,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotAt, gotReason := test.meta.DueAt()
+			require.Equal(t, test.wantAt, gotAt)
+			require.Equal(t, test.wantReason, gotReason)
+		})
+	}
+}
+
+func TestParseData(t *testing.T) {
+	login := LoginData{Login: "bob", Password: "hunter2", URL: "https://example.com"}
+	require.Equal(t, login, ParseLogin(login.Bytes()))
+	require.Equal(t, LoginData{Password: "hunter2"}, ParseLogin([]byte("hunter2")))
+
+	card := CardData{Number: "4111 1111 1111 1234", Holder: "BOB", Expiry: "12/30", CVV: "123"}
+	require.Equal(t, card, ParseCard(card.Bytes()))
+	require.Equal(t, CardData{Number: "4111111111111234"}, ParseCard([]byte("4111111111111234")))
+	require.Equal(t, "••••••••••••1234", card.MaskedNumber())
+}
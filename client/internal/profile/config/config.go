@@ -0,0 +1,10 @@
+package config
+
+type Config struct {
+	// Файл конфигурации клиента с профилями
+	File string
+	// Профиль по умолчанию, если профили не настроены
+	DefaultServer string
+	DefaultCA     string
+	DefaultCache  string
+}
@@ -0,0 +1,229 @@
+// Пакет profile. Профили клиента: сервер, сертификат, каталог кэша и учетная запись.
+// Токен и кэш каждого профиля хранятся отдельно
+package profile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/iurnickita/gophkeeper/client/internal/profile/config"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultName - имя профиля, если профили не настроены
+const DefaultName = "default"
+
+var (
+	ErrNotFound      = errors.New("profile not found")
+	ErrAlreadyExists = errors.New("profile already exists")
+	ErrInvalidName   = errors.New("invalid profile name, use letters, digits, '-' and '_'")
+	ErrNoServer      = errors.New("profile server address is required")
+	ErrNoCurrent     = errors.New("no current profile, select one with: profile use <name>")
+	ErrInvalidFile   = errors.New("config file must be a YAML mapping")
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Profile - профиль подключения
+type Profile struct {
+	Name   string `yaml:"-"`
+	Server string `yaml:"server"`
+	CA     string `yaml:"ca,omitempty"`
+	Cache  string `yaml:"cache,omitempty"`
+	Login  string `yaml:"login,omitempty"`
+}
+
+// file - содержимое файла конфигурации
+type file struct {
+	Current  string              `yaml:"current,omitempty"`
+	Profiles map[string]*Profile `yaml:"profiles,omitempty"`
+}
+
+// Store - профили из файла конфигурации клиента
+type Store struct {
+	cfg  config.Config
+	file file
+	// Документ файла. Остальные разделы конфигурации сохраняются без изменений
+	doc yaml.Node
+}
+
+// List возвращает профили по имени и имя текущего профиля
+func (s *Store) List() ([]Profile, string) {
+	var profiles []Profile
+	for name := range s.file.Profiles {
+		profile, _ := s.Get(name)
+		profiles = append(profiles, profile)
+	}
+	slices.SortFunc(profiles, func(a, b Profile) int { return strings.Compare(a.Name, b.Name) })
+	return profiles, s.file.Current
+}
+
+// Get возвращает профиль с заполненными значениями по умолчанию.
+// Пустое имя - текущий профиль; без настроенных профилей - профиль по умолчанию
+func (s *Store) Get(name string) (Profile, error) {
+	if name == "" {
+		name = s.file.Current
+	}
+	if name == "" {
+		if len(s.file.Profiles) > 0 {
+			return Profile{}, ErrNoCurrent
+		}
+		return Profile{Name: DefaultName, Server: s.cfg.DefaultServer, CA: s.cfg.DefaultCA, Cache: s.cfg.DefaultCache}, nil
+	}
+
+	stored, ok := s.file.Profiles[name]
+	if !ok {
+		return Profile{}, ErrNotFound
+	}
+	profile := *stored
+	profile.Name = name
+	if profile.CA == "" {
+		profile.CA = s.cfg.DefaultCA
+	}
+	if profile.Cache == "" {
+		profile.Cache = filepath.Join(filepath.Dir(s.cfg.File), "profiles", name)
+	}
+	return profile, nil
+}
+
+// Add добавляет профиль. Первый профиль становится текущим
+func (s *Store) Add(profile Profile) error {
+	if !validName.MatchString(profile.Name) {
+		return ErrInvalidName
+	}
+	if profile.Server == "" {
+		return ErrNoServer
+	}
+	if _, ok := s.file.Profiles[profile.Name]; ok {
+		return ErrAlreadyExists
+	}
+	if s.file.Profiles == nil {
+		s.file.Profiles = make(map[string]*Profile)
+	}
+	s.file.Profiles[profile.Name] = &profile
+	if s.file.Current == "" {
+		s.file.Current = profile.Name
+	}
+	return s.save()
+}
+
+// Use делает профиль текущим
+func (s *Store) Use(name string) error {
+	if _, ok := s.file.Profiles[name]; !ok {
+		return ErrNotFound
+	}
+	s.file.Current = name
+	return s.save()
+}
+
+// Remove удаляет профиль из конфигурации. Кэш профиля не удаляется
+func (s *Store) Remove(name string) error {
+	if _, ok := s.file.Profiles[name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.file.Profiles, name)
+	if s.file.Current == name {
+		s.file.Current = ""
+	}
+	return s.save()
+}
+
+// SetLogin запоминает учетную запись профиля после входа.
+// Для профиля по умолчанию не сохраняется
+func (s *Store) SetLogin(name string, login string) error {
+	profile, ok := s.file.Profiles[name]
+	if !ok || profile.Login == login {
+		return nil
+	}
+	profile.Login = login
+	return s.save()
+}
+
+// save записывает файл конфигурации через временный файл
+func (s *Store) save() error {
+	var sections yaml.Node
+	err := sections.Encode(s.file)
+	if err != nil {
+		return err
+	}
+	if s.doc.Kind == 0 {
+		s.doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := s.doc.Content[0]
+	for _, key := range []string{"current", "profiles"} {
+		setSection(root, key, findSection(&sections, key))
+	}
+	data, err := yaml.Marshal(&s.doc)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(s.cfg.File), 0700)
+	if err != nil {
+		return err
+	}
+	tmp := s.cfg.File + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.cfg.File)
+}
+
+// NewStore читает профили из файла конфигурации. Отсутствие файла не является ошибкой
+func NewStore(cfg config.Config) (*Store, error) {
+	store := &Store{cfg: cfg}
+	data, err := os.ReadFile(cfg.File)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(data, &store.doc)
+	if err != nil {
+		return nil, err
+	}
+	if store.doc.Kind == 0 {
+		return store, nil
+	}
+	if len(store.doc.Content) == 0 || store.doc.Content[0].Kind != yaml.MappingNode {
+		return nil, ErrInvalidFile
+	}
+	err = store.doc.Decode(&store.file)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// findSection возвращает значение раздела или nil
+func findSection(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setSection заменяет, добавляет или (для nil) удаляет раздел
+func setSection(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		if value == nil {
+			mapping.Content = slices.Delete(mapping.Content, i, i+2)
+		} else {
+			mapping.Content[i+1] = value
+		}
+		return
+	}
+	if value != nil {
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+}
@@ -0,0 +1,69 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iurnickita/gophkeeper/client/internal/profile/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	cfg := config.Config{
+		File:          filepath.Join(t.TempDir(), "config.yaml"),
+		DefaultServer: "localhost:3200",
+		DefaultCA:     "ca.pem",
+		DefaultCache:  "data/",
+	}
+	store, err := NewStore(cfg)
+	require.NoError(t, err)
+
+	// Без профилей - профиль по умолчанию
+	p, err := store.Get("")
+	require.NoError(t, err)
+	require.Equal(t, Profile{Name: DefaultName, Server: "localhost:3200", CA: "ca.pem", Cache: "data/"}, p)
+
+	// Первый профиль становится текущим
+	require.NoError(t, store.Add(Profile{Name: "work", Server: "team:3200", Login: "bob"}))
+	require.NoError(t, store.Add(Profile{Name: "home", Server: "home:3200", Cache: "/srv/cache"}))
+	require.ErrorIs(t, store.Add(Profile{Name: "home", Server: "home:3200"}), ErrAlreadyExists)
+	require.ErrorIs(t, store.Add(Profile{Name: "../x", Server: "x:1"}), ErrInvalidName)
+	require.ErrorIs(t, store.Add(Profile{Name: "x"}), ErrNoServer)
+
+	p, err = store.Get("")
+	require.NoError(t, err)
+	require.Equal(t, "work", p.Name)
+	require.Equal(t, "ca.pem", p.CA)
+	require.Equal(t, filepath.Join(filepath.Dir(cfg.File), "profiles", "work"), p.Cache)
+
+	// Изменения сохраняются в файл
+	require.NoError(t, store.Use("home"))
+	require.NoError(t, store.SetLogin("home", "alice"))
+	store, err = NewStore(cfg)
+	require.NoError(t, err)
+	profiles, current := store.List()
+	require.Equal(t, "home", current)
+	require.Len(t, profiles, 2)
+	require.Equal(t, Profile{Name: "home", Server: "home:3200", CA: "ca.pem", Cache: "/srv/cache", Login: "alice"}, profiles[0])
+
+	// Удаление текущего профиля
+	require.NoError(t, store.Remove("home"))
+	require.ErrorIs(t, store.Remove("home"), ErrNotFound)
+	_, err = store.Get("")
+	require.ErrorIs(t, err, ErrNoCurrent)
+}
+
+func TestStore_KeepsSections(t *testing.T) {
+	cfg := config.Config{File: filepath.Join(t.TempDir(), "config.yaml")}
+	require.NoError(t, os.WriteFile(cfg.File, []byte("# настройки\nlogger:\n  level: warn\n"), 0600))
+
+	store, err := NewStore(cfg)
+	require.NoError(t, err)
+	require.NoError(t, store.Add(Profile{Name: "work", Server: "team:3200"}))
+
+	data, err := os.ReadFile(cfg.File)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "# настройки\nlogger:\n    level: warn\n")
+	require.Contains(t, string(data), "current: work")
+}
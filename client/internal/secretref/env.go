@@ -0,0 +1,100 @@
+package secretref
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrEnvSyntax = errors.New("invalid env file")
+)
+
+// envName - имя переменной среды
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Var - переменная среды
+type Var struct {
+	Key   string
+	Value string
+}
+
+// ParseEnv разбирает файл .env: строки KEY=VALUE, необязательный префикс export,
+// комментарии #. Значение в одинарных кавычках берется как есть, в двойных -
+// с экранированием \n, \t, \" и \\. В значении без кавычек комментарий начинается с " #"
+func ParseEnv(data []byte) ([]Var, error) {
+	var vars []Var
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !envName.MatchString(key) {
+			return nil, fmt.Errorf("%w: line %d: expected KEY=VALUE", ErrEnvSyntax, n)
+		}
+		value, err := envValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrEnvSyntax, n, err)
+		}
+		vars = append(vars, Var{Key: key, Value: value})
+	}
+	return vars, scanner.Err()
+}
+
+// envValue разбирает значение переменной
+func envValue(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	switch quote := s[0]; quote {
+	case '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", errors.New("unterminated quote")
+		}
+		return s[1 : end+1], trailing(s[end+2:])
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '"':
+				return b.String(), trailing(s[i+1:])
+			case c == '\\' && i+1 < len(s):
+				i++
+				switch s[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(s[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", errors.New("unterminated quote")
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// trailing проверяет остаток строки после закрывающей кавычки
+func trailing(s string) error {
+	if s = strings.TrimSpace(s); s != "" && !strings.HasPrefix(s, "#") {
+		return errors.New("unexpected text after quoted value")
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package secretref
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Mask - замена значения секрета в выводе
+const Mask = "*****"
+
+// MinMaskLen - минимальная длина маскируемого значения. Более короткие значения
+// совпадали бы с обычным выводом
+const MinMaskLen = 4
+
+// FlushIdle - время простоя, после которого задержанный остаток выводится.
+// Иначе приглашение вида "Password: pa" не появится, пока команда ждет ввода
+const FlushIdle = 100 * time.Millisecond
+
+// Masker заменяет значения секретов в потоке вывода. Конец последней строки,
+// с которого может начинаться секрет, задерживается до следующей записи, Flush
+// или простоя FlushIdle. Секрет, части которого записаны с большим перерывом
+// или разделены переводом строки, не маскируется
+type Masker struct {
+	mux     sync.Mutex
+	w       io.Writer
+	secrets [][]byte
+	buf     []byte
+	timer   *time.Timer
+	err     error // ошибка вывода по таймеру
+}
+
+// NewMasker создает маскирующий поток
+func NewMasker(w io.Writer, secrets []string) *Masker {
+	m := &Masker{w: w}
+	for _, secret := range secrets {
+		if len(secret) >= MinMaskLen && !slices.ContainsFunc(m.secrets, func(s []byte) bool { return string(s) == secret }) {
+			m.secrets = append(m.secrets, []byte(secret))
+		}
+	}
+	// Сначала длинные: секрет может содержать другой секрет
+	slices.SortFunc(m.secrets, func(a, b []byte) int { return len(b) - len(a) })
+	return m
+}
+
+// Write
+func (m *Masker) Write(p []byte) (int, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if err := m.err; err != nil {
+		m.err = nil
+		return 0, err
+	}
+	m.buf = append(m.buf, p...)
+	for _, secret := range m.secrets {
+		m.buf = bytes.ReplaceAll(m.buf, secret, []byte(Mask))
+	}
+	hold := m.hold()
+	if _, err := m.w.Write(m.buf[:len(m.buf)-hold]); err != nil {
+		return 0, err
+	}
+	m.buf = append(m.buf[:0], m.buf[len(m.buf)-hold:]...)
+	switch {
+	case hold == 0:
+		m.stop()
+	case m.timer == nil:
+		m.timer = time.AfterFunc(FlushIdle, m.idle)
+	default:
+		m.timer.Reset(FlushIdle)
+	}
+	return len(p), nil
+}
+
+// Flush выводит задержанный остаток
+func (m *Masker) Flush() error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.stop()
+	_, err := m.w.Write(m.buf)
+	m.buf = m.buf[:0]
+	if m.err != nil {
+		err, m.err = m.err, nil
+	}
+	return err
+}
+
+// idle выводит задержанный остаток по таймеру простоя
+func (m *Masker) idle() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if len(m.buf) == 0 {
+		return
+	}
+	if _, err := m.w.Write(m.buf); err != nil {
+		m.err = err
+	}
+	m.buf = m.buf[:0]
+}
+
+// stop останавливает таймер простоя
+func (m *Masker) stop() {
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+}
+
+// hold возвращает длину наибольшего окончания последней строки буфера,
+// с которого начинается секрет
+func (m *Masker) hold() int {
+	var hold int
+	line := len(m.buf) - bytes.LastIndexByte(m.buf, '\n') - 1
+	for _, secret := range m.secrets {
+		for n := min(len(secret)-1, line); n > hold; n-- {
+			if bytes.HasPrefix(secret, m.buf[len(m.buf)-n:]) {
+				hold = n
+				break
+			}
+		}
+	}
+	return hold
+}
@@ -0,0 +1,79 @@
+// Пакет secretref. Ссылки на секреты вида gk://<name>[#<field>], разбор файлов .env
+// и маскирование значений секретов в выводе
+package secretref
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Scheme - префикс ссылки
+const Scheme = "gk://"
+
+var (
+	ErrRef = errors.New("invalid secret reference, expected gk://<name>[#<field>]")
+)
+
+// refPattern - ссылка внутри значения. Имя продолжается до пробела, кавычки или #,
+// поле - буквы, цифры, _, . и -. Пробелы в имени записываются как %20
+var refPattern = regexp.MustCompile(`gk://[^\s"'#]+(?:#[\w.-]+)?`)
+
+// Ref - ссылка на значение единицы данных. Пустое поле - основное значение
+// (пароль, номер карты или данные)
+type Ref struct {
+	Name  string
+	Field string
+}
+
+// String
+func (r Ref) String() string {
+	s := Scheme + strings.ReplaceAll(url.PathEscape(r.Name), "%2F", "/")
+	if r.Field != "" {
+		s += "#" + r.Field
+	}
+	return s
+}
+
+// Parse разбирает ссылку
+func Parse(s string) (Ref, error) {
+	if !refPattern.MatchString(s) || refPattern.FindString(s) != s {
+		return Ref{}, fmt.Errorf("%w: %q", ErrRef, s)
+	}
+	name, field, _ := strings.Cut(strings.TrimPrefix(s, Scheme), "#")
+	name, err := url.PathUnescape(name)
+	if err != nil {
+		return Ref{}, fmt.Errorf("%w: %q", ErrRef, s)
+	}
+	return Ref{Name: name, Field: field}, nil
+}
+
+// Contains проверяет, что строка содержит ссылки
+func Contains(s string) bool {
+	return strings.Contains(s, Scheme)
+}
+
+// Expand заменяет ссылки в строке значениями resolve
+func Expand(s string, resolve func(Ref) (string, error)) (string, error) {
+	var err error
+	expanded := refPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if err != nil {
+			return match
+		}
+		var ref Ref
+		if ref, err = Parse(match); err != nil {
+			return match
+		}
+		var value string
+		if value, err = resolve(ref); err != nil {
+			err = fmt.Errorf("%s: %w", match, err)
+		}
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
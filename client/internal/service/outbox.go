@@ -0,0 +1,229 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/client/internal/blindindex"
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrQueued            = errors.New("offline, change queued for sync")
+	ErrNoConflict        = errors.New("operation has no conflict")
+	ErrUnknownResolution = errors.New("unknown resolution, use mine, theirs or both")
+	ErrPendingChanges    = errors.New("cache has changes not sent to the server, unlock it with the previous password and sync")
+)
+
+// isOffline проверяет, что сервер недоступен
+func isOffline(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// enqueue ставит изменение в очередь и сразу применяет его к кэшу.
+// В очереди не больше одной операции на имя: новое изменение заменяет
+// ожидающее, сохраняя версию, на основе которой оно сделано
+func (s service) enqueue(kind string, unit model.Unit) error {
+	ops := s.cache.GetOutbox()
+	idx := slices.IndexFunc(ops, func(op model.Op) bool { return op.Unit.Name == unit.Name })
+
+	// Удалять можно только известную клиенту единицу данных
+	if kind == model.OpDelete && idx == -1 {
+		list, _, err := s.cache.GetList()
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(list, unit.Name) {
+			return ErrNotFound
+		}
+	}
+
+	var err error
+	if idx == -1 {
+		op := model.Op{Kind: kind, Unit: unit, QueuedAt: time.Now()}
+		if cached, err := s.cache.GetUnit(unit.Name); err == nil {
+			op.Base = cached.Body.Meta.UpdatedAt
+		}
+		_, err = s.cache.PushOutbox(op)
+	} else {
+		op := ops[idx]
+		op.Kind, op.Unit, op.QueuedAt = kind, unit, time.Now()
+		err = s.cache.UpdateOutbox(op)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Применение к кэшу
+	if kind == model.OpDelete {
+		err = s.cache.DeleteUnit(unit.Name)
+		if err != nil {
+			s.logger.Sugar().Debugf("cache delete %s: %s", unit.Name, err)
+		}
+	} else {
+		unit.Body.Meta.UpdatedAt = time.Now()
+		err = s.cache.SetUnit(unit)
+		if err != nil {
+			return err
+		}
+	}
+	return ErrQueued
+}
+
+// replay отправляет на сервер изменения из очереди по порядку.
+// Изменение поверх более новой версии на сервере помечается конфликтом
+// и ждет решения пользователя. Возвращает число отправленных и конфликтующих операций
+func (s service) replay(idx blindindex.Indexer) (int, int, error) {
+	token := s.cache.GetToken()
+	var replayed, conflicts int
+	for _, op := range s.cache.GetOutbox() {
+		if op.Conflict != nil {
+			conflicts++
+			continue
+		}
+
+		// Текущая версия на сервере
+		server, err := s.client.Read(token, idx, op.Unit.Name)
+		missing := false
+		switch status.Code(err) {
+		case codes.OK:
+		case codes.NotFound:
+			missing = true
+		case codes.Unavailable:
+			return replayed, conflicts, ErrOffline
+		default:
+			return replayed, conflicts, err
+		}
+
+		if conflict := detectConflict(op, server.Body.Meta.UpdatedAt, missing); conflict != nil {
+			op.Conflict = conflict
+			err = s.cache.UpdateOutbox(op)
+			if err != nil {
+				return replayed, conflicts, err
+			}
+			conflicts++
+			continue
+		}
+
+		err = s.apply(token, idx, op, missing)
+		if err != nil {
+			if isOffline(err) {
+				return replayed, conflicts, ErrOffline
+			}
+			return replayed, conflicts, err
+		}
+		err = s.cache.RemoveOutbox(op.ID)
+		if err != nil {
+			return replayed, conflicts, err
+		}
+		replayed++
+	}
+	return replayed, conflicts, nil
+}
+
+// detectConflict сравнивает версию, на основе которой сделано изменение, с версией на сервере.
+// Возвращает nil, если изменение можно применить
+func detectConflict(op model.Op, serverUpdatedAt time.Time, missing bool) *model.Conflict {
+	conflict := &model.Conflict{ServerUpdatedAt: serverUpdatedAt, ServerDeleted: missing}
+	switch op.Kind {
+	case model.OpDelete:
+		// Удаленное на сервере удалять не нужно; неизвестная версия удаляется без проверки
+		if missing || op.Base.IsZero() {
+			return nil
+		}
+		if !serverUpdatedAt.Equal(op.Base) {
+			return conflict
+		}
+	default:
+		// Новая единица данных: имя занято на сервере
+		if op.Base.IsZero() {
+			if !missing {
+				return conflict
+			}
+			return nil
+		}
+		// Изменение: версия на сервере удалена или изменена
+		if missing || !serverUpdatedAt.Equal(op.Base) {
+			return conflict
+		}
+	}
+	return nil
+}
+
+// apply выполняет операцию на сервере
+func (s service) apply(token string, idx blindindex.Indexer, op model.Op, missing bool) error {
+	if op.Kind != model.OpDelete {
+		_, err := s.client.Write(token, idx, op.Unit)
+		return err
+	}
+	if missing {
+		return nil
+	}
+	err := s.client.Delete(token, idx, op.Unit.Name)
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+// Outbox возвращает изменения, ожидающие отправки на сервер
+func (s service) Outbox() []model.Op {
+	return s.cache.GetOutbox()
+}
+
+// Resolve разрешает конфликт отложенной операции:
+// mine - применить свою версию, theirs - оставить серверную,
+// both - сохранить свою версию под новым именем рядом с серверной
+func (s service) Resolve(id int64, resolution string) error {
+	idx, err := s.indexer()
+	if err != nil {
+		return err
+	}
+	ops := s.cache.GetOutbox()
+	i := slices.IndexFunc(ops, func(op model.Op) bool { return op.ID == id })
+	if i == -1 {
+		return ErrNotFound
+	}
+	op := ops[i]
+	if op.Conflict == nil {
+		return ErrNoConflict
+	}
+
+	token := s.cache.GetToken()
+	switch resolution {
+	case model.ResolveMine:
+		err = s.apply(token, idx, op, op.Conflict.ServerDeleted)
+	case model.ResolveTheirs:
+	case model.ResolveBoth:
+		if op.Kind == model.OpWrite {
+			unit := op.Unit
+			unit.Name = conflictName(unit.Name, op.QueuedAt)
+			_, err = s.client.Write(token, idx, unit)
+		}
+	default:
+		return ErrUnknownResolution
+	}
+	if err != nil {
+		if isOffline(err) {
+			return ErrOffline
+		}
+		return err
+	}
+	err = s.cache.RemoveOutbox(id)
+	if err != nil {
+		return err
+	}
+	// Кэш приводится к состоянию сервера
+	s.autoSync()
+	return nil
+}
+
+// conflictName возвращает имя для копии своей версии при конфликте
+func conflictName(name string, queuedAt time.Time) string {
+	base := unitpath.Base(name) + " (conflict " + queuedAt.Local().Format("2006-01-02 150405") + ")"
+	return unitpath.Join(unitpath.Dir(name), base)
+}
@@ -2,29 +2,61 @@
 package service
 
 import (
+	"context"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"slices"
+	"time"
 
+	"github.com/iurnickita/gophkeeper/client/internal/blindindex"
 	"github.com/iurnickita/gophkeeper/client/internal/cache"
 	grpcclient "github.com/iurnickita/gophkeeper/client/internal/grpc_client/client"
 	"github.com/iurnickita/gophkeeper/client/internal/model"
 	"github.com/iurnickita/gophkeeper/client/internal/service/config"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 var (
-	ErrOffline = errors.New("offline")
+	ErrOffline    = errors.New("offline")
+	ErrNotFound   = errors.New("data not found")
+	ErrNoIndexKey = errors.New("index key not found, login again")
+	ErrLocked     = errors.New("cache is locked: login, set GOPHKEEPER_MASTER_PASSWORD or GOPHKEEPER_KEY_FILE")
+	// Перемещения и папки не ставятся в очередь: без сервера они не выполняются
+	ErrOfflineNotQueued = fmt.Errorf("%w: moves and folders are not queued, retry when the server is available", ErrOffline)
 )
 
 // Service интерфейс сервиса
 type Service interface {
 	Register(login string, password string) error
 	Login(login string, password string) error
-	List() ([]string, error)
+	ResetCache(password string) error
+	Locked() bool
+	Unlock(password string) error
+	List(folder string, recursive bool) ([]string, []string, error)
+	Cached() ([]string, []string, error)
 	Read(unitname string) (model.Unit, error)
 	Write(unit model.Unit) error
+	WriteUnits(units []model.Unit) ([]error, error)
 	Delete(unitname string) error
+	Move(from string, to string) error
+	Rename(from string, name string) error
+	Mkdir(folder string) error
+	Search(query model.SearchQuery) ([]model.Unit, error)
+	Due(within time.Duration) ([]model.Unit, error)
+	Units() ([]model.Unit, error)
+	Snapshot() ([]model.Unit, int, error)
+	Sync() (model.SyncResult, error)
+	Watch(ctx context.Context, onEvent func(model.Event)) error
+	Reindex() (int, error)
+	ListTrash() ([]model.TrashItem, error)
+	Restore(id int64) error
+	Purge(ids []int64) (int64, error)
+	Outbox() []model.Op
+	Resolve(id int64, resolution string) error
 	Close()
 }
 
@@ -42,7 +74,13 @@ func (s service) Register(login string, password string) error {
 		return err
 	}
 	s.logger.Sugar().Debugf("register returns token: %s", token)
+	err = s.unlock(password)
+	if err != nil {
+		return err
+	}
 	s.cache.SetToken(token)
+	s.cache.SetIndexKey(hex.EncodeToString(blindindex.DeriveKey(login, password)))
+	s.cache.SetCursor(0)
 	return nil
 }
 
@@ -53,33 +91,121 @@ func (s service) Login(login string, password string) error {
 		return err
 	}
 	s.logger.Sugar().Debugf("authenticate returns token: %s", token)
+	err = s.unlock(password)
+	if err != nil {
+		return err
+	}
 	s.cache.SetToken(token)
+	s.cache.SetIndexKey(hex.EncodeToString(blindindex.DeriveKey(login, password)))
+	s.cache.SetCursor(0)
+
+	// Перевод ранее записанных данных на слепой индекс
+	count, err := s.Reindex()
+	if err != nil {
+		s.logger.Sugar().Debugf("reindex: %s", err)
+	} else if count > 0 {
+		s.logger.Sugar().Debugf("reindexed %d names", count)
+	}
+
+	// Начальное заполнение кэша
+	s.autoSync()
 	return nil
 }
 
-// List
-func (s service) List() ([]string, error) {
-	list, err := s.client.List(s.cache.GetToken())
+// unlock разблокирует кэш паролем пользователя после входа.
+// Кэш, зашифрованный другим паролем (мастер-паролем, файлом ключа или паролем
+// прежней учетной записи), не удаляется: сбросить его можно только через ResetCache
+func (s service) unlock(password string) error {
+	if !s.cache.Locked() {
+		return nil
+	}
+	err := s.cache.Unlock([]byte(password))
+	if errors.Is(err, cache.ErrWrongPassword) {
+		return fmt.Errorf("%w: the cache is encrypted with another password; "+
+			"unlock it with the master password or key file, or log in with --reset-cache", err)
+	}
+	return err
+}
+
+// Locked проверяет, что кэш заблокирован
+func (s service) Locked() bool {
+	return s.cache.Locked()
+}
+
+// Unlock разблокирует кэш мастер-паролем
+func (s service) Unlock(password string) error {
+	return s.cache.Unlock([]byte(password))
+}
+
+// ResetCache удаляет кэш, зашифрованный другим паролем, и создает новый ключ из password.
+// Кэш с неотправленными изменениями не удаляется
+func (s service) ResetCache(password string) error {
+	pending, err := s.cache.Pending()
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w: %d", ErrPendingChanges, pending)
+	}
+	s.logger.Sugar().Debug("reset cache")
+	return s.cache.Reset([]byte(password))
+}
+
+// List возвращает содержимое папки: единицы данных и подпапки.
+// С сервера запрашивается вся папка целиком для синхронизации кэша
+func (s service) List(folder string, recursive bool) ([]string, []string, error) {
+	folder, err := unitpath.CleanFolder(folder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	idx, err := s.indexer()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list, folders, err := s.client.List(s.cache.GetToken(), idx, folder, true)
 	switch err {
 	case nil:
 		// Вывод из сервера
-		s.cache.SyncList(list)
-		return list, nil
+		s.cache.SyncList(folder, list, folders)
+		s.autoSync()
 	default:
 		// Вывод из кэша
 		// тут надо отличать ошибку соединения от остальных
 		// case "connection_refused":
-		list, err = s.cache.GetList()
+		list, folders, err = s.cache.GetList()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		return list, ErrOffline
+		list, folders = unitpath.Filter(list, folders, folder, recursive)
+		return list, folders, ErrOffline
 	}
+
+	list, folders = unitpath.Filter(list, folders, folder, recursive)
+	return list, folders, nil
+}
+
+// Cached возвращает имена данных и папок из кэша без обращения к серверу
+func (s service) Cached() ([]string, []string, error) {
+	if s.cache.Locked() {
+		return nil, nil, ErrLocked
+	}
+	return s.cache.GetList()
 }
 
 // Read
 func (s service) Read(unitname string) (model.Unit, error) {
-	unit, err := s.client.Read(s.cache.GetToken(), unitname)
+	idx, err := s.indexer()
+	if err != nil {
+		return model.Unit{}, err
+	}
+	unitname, err = unitpath.Clean(unitname)
+	if err != nil {
+		return model.Unit{}, err
+	}
+
+	unit, err := s.client.Read(s.cache.GetToken(), idx, unitname)
 	if err == nil {
 		// Вывод из сервера
 		s.cache.SetUnit(unit)
@@ -91,7 +217,7 @@ func (s service) Read(unitname string) (model.Unit, error) {
 				// Connection refused - вывод из кэша
 				unit, err = s.cache.GetUnit(unitname)
 				if err != nil {
-					return model.Unit{}, nil
+					return model.Unit{}, err
 				}
 				return unit, ErrOffline
 			default:
@@ -102,49 +228,572 @@ func (s service) Read(unitname string) (model.Unit, error) {
 	}
 }
 
-// Write
+// Write.
+// В офлайне изменение применяется к кэшу и ставится в очередь
 func (s service) Write(unit model.Unit) error {
 	// Запись на сервер
 	s.logger.Sugar().Debug("Unit to write")
 	s.logger.Sugar().Debug(unit)
-	err := s.client.Write(s.cache.GetToken(), unit)
+	idx, err := s.indexer()
 	if err != nil {
-		// Это надо убрать
-		// А на стороне сервера сделать перезапись
-		if e, ok := status.FromError(err); ok {
-			switch e.Code() {
-			case codes.AlreadyExists:
-				return err
-			default:
-				return err
-			}
-		} else {
-			return err
+		return err
+	}
+	return s.write(s.cache.GetToken(), idx, unit, false)
+}
+
+// WriteUnits записывает единицы данных по порядку с одним индексатором и токеном.
+// После потери связи остальные единицы данных ставятся в очередь без обращения к серверу.
+// Возвращает результаты записанных и поставленных в очередь единиц данных; при потере
+// входа или блокировке кэша запись прерывается, и ошибка возвращается вторым значением
+func (s service) WriteUnits(units []model.Unit) ([]error, error) {
+	idx, err := s.indexer()
+	if err != nil {
+		return nil, err
+	}
+	token := s.cache.GetToken()
+	results := make([]error, 0, len(units))
+	offline := false
+	for _, unit := range units {
+		err := s.write(token, idx, unit, offline)
+		results = append(results, err)
+		switch {
+		case errors.Is(err, ErrQueued):
+			offline = true
+		case isLocked(err), status.Code(err) == codes.Unauthenticated:
+			return results, err
 		}
 	}
-	// Кэширование
-	err = s.cache.SetUnit(unit)
+	return results, nil
+}
+
+// write записывает единицу данных; offline - сразу в очередь
+func (s service) write(token string, idx blindindex.Indexer, unit model.Unit, offline bool) error {
+	var err error
+	unit.Name, err = unitpath.Clean(unit.Name)
 	if err != nil {
 		return err
 	}
-	return nil
+	if offline {
+		return s.enqueue(model.OpWrite, unit)
+	}
+	written, err := s.client.Write(token, idx, unit)
+	if err != nil {
+		if isOffline(err) {
+			return s.enqueue(model.OpWrite, unit)
+		}
+		return err
+	}
+	// Кэширование с датами сервера: дата изменения - версия для отложенных изменений
+	return s.cache.SetUnit(written)
 }
 
-// Delete
+// Delete перемещает единицу данных в корзину.
+// В офлайне изменение применяется к кэшу и ставится в очередь
 func (s service) Delete(unitname string) error {
-	// Удаление с сервера
-	err := s.client.Delete(s.cache.GetToken(), unitname)
+	idx, err := s.indexer()
+	if err != nil {
+		return err
+	}
+	unitname, err = unitpath.Clean(unitname)
 	if err != nil {
 		return err
 	}
-	// Удаление кэша
+
+	// Перемещение в корзину на сервере
+	err = s.client.Delete(s.cache.GetToken(), idx, unitname)
+	if err != nil {
+		if isOffline(err) {
+			return s.enqueue(model.OpDelete, model.Unit{Name: unitname})
+		}
+		return err
+	}
+	// Удаление кэша. Отсутствие в кэше не является ошибкой
 	err = s.cache.DeleteUnit(unitname)
+	if err != nil {
+		s.logger.Sugar().Debugf("cache delete %s: %s", unitname, err)
+	}
+	return nil
+}
+
+// Move перемещает единицу данных или папку.
+// Без связи с сервером не выполняется: ErrOfflineNotQueued
+func (s service) Move(from string, to string) error {
+	from, to, err := unitpath.MoveTarget(from, to)
+	if err != nil {
+		return err
+	}
+	return s.moveCached(from, to, s.move(from, to, false))
+}
+
+// Rename переименовывает единицу данных или папку в пределах родительской папки.
+// Без связи с сервером не выполняется: ErrOfflineNotQueued
+func (s service) Rename(from string, name string) error {
+	from, to, err := unitpath.RenameTarget(from, name)
 	if err != nil {
 		return err
 	}
+	return s.moveCached(from, to, s.move(from, to, true))
+}
+
+// move перемещает на сервере единицу данных или папку целиком.
+// Сервер не знает открытых имен, поэтому новые имена всего содержимого папки
+// рассчитываются здесь и передаются одним запросом. rename - запрос Rename вместо Move
+func (s service) move(from string, to string, rename bool) error {
+	idx, err := s.indexer()
+	if err != nil {
+		return err
+	}
+	token := s.cache.GetToken()
+	send := s.client.Move
+	if rename {
+		send = s.client.Rename
+	}
+
+	// Единица данных или папка
+	units, folders, err := s.client.List(token, idx, unitpath.Dir(from), false)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(units, from) {
+		return send(token, idx, "", []model.Rename{{From: from, To: to}}, nil)
+	}
+	if !slices.Contains(folders, from) {
+		return ErrNotFound
+	}
+
+	// Папка целиком
+	units, folders, err = s.client.List(token, idx, from, true)
+	if err != nil {
+		return err
+	}
+	var unitRenames, folderRenames []model.Rename
+	for _, unit := range units {
+		unitRenames = append(unitRenames, model.Rename{From: unit, To: unitpath.Rebase(unit, from, to)})
+	}
+	for _, folder := range append(folders, from) {
+		folderRenames = append(folderRenames, model.Rename{From: folder, To: unitpath.Rebase(folder, from, to)})
+	}
+	return send(token, idx, from, unitRenames, folderRenames)
+}
+
+// moveCached повторяет перемещение в кэше после успешного ответа сервера.
+// Без связи кэш не изменяется: изменение потерялось бы при полной синхронизации
+func (s service) moveCached(from string, to string, serverErr error) error {
+	if isOffline(serverErr) {
+		return ErrOfflineNotQueued
+	}
+	if serverErr != nil {
+		return serverErr
+	}
+
+	err := s.cache.MoveUnit(from, to)
+	if err != nil {
+		// Кэш расходится с сервером и будет обновлен при следующем List
+		s.logger.Sugar().Debugf("cache move %s -> %s: %s", from, to, err)
+	}
 	return nil
 }
 
+// Mkdir создает папку.
+// Без связи с сервером не выполняется: ErrOfflineNotQueued
+func (s service) Mkdir(folder string) error {
+	folder, err := unitpath.Clean(folder)
+	if err != nil {
+		return err
+	}
+	idx, err := s.indexer()
+	if err != nil {
+		return err
+	}
+	err = s.client.Mkdir(s.cache.GetToken(), idx, folder)
+	if isOffline(err) {
+		return ErrOfflineNotQueued
+	}
+	if err != nil {
+		return err
+	}
+	return s.cache.AddFolder(folder)
+}
+
+// Search ищет единицы данных на сервере.
+// В офлайне поиск выполняется по кэшированным единицам данных
+func (s service) Search(query model.SearchQuery) ([]model.Unit, error) {
+	idx, err := s.indexer()
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := s.client.Search(s.cache.GetToken(), idx, query)
+	if err == nil {
+		// Поиск по токенам n-грамм дает надмножество: уточнение по открытым именам
+		return slices.DeleteFunc(units, func(unit model.Unit) bool {
+			return !query.Match(unit)
+		}), nil
+	}
+	if e, ok := status.FromError(err); !ok || e.Code() != codes.Unavailable {
+		return nil, err
+	}
+
+	// Connection refused - поиск в кэше
+	cached, err := s.cache.GetUnits()
+	if err != nil {
+		return nil, err
+	}
+	units = nil
+	for _, unit := range cached {
+		if query.Match(unit) {
+			units = append(units, unit)
+		}
+	}
+	return units, ErrOffline
+}
+
+// Due возвращает единицы данных, просроченные или требующие смены в течение within,
+// начиная с самых срочных. В офлайне отбор выполняется по кэшу
+func (s service) Due(within time.Duration) ([]model.Unit, error) {
+	idx, err := s.indexer()
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := s.client.Due(s.cache.GetToken(), idx, within)
+	if err != nil {
+		if e, ok := status.FromError(err); !ok || e.Code() != codes.Unavailable {
+			return nil, err
+		}
+
+		// Connection refused - отбор в кэше
+		cached, cacheErr := s.cache.GetUnits()
+		if cacheErr != nil {
+			return nil, cacheErr
+		}
+		deadline := time.Now().Add(within)
+		units = nil
+		for _, unit := range cached {
+			if dueAt, _ := unit.Body.Meta.DueAt(); !dueAt.IsZero() && !dueAt.After(deadline) {
+				units = append(units, unit)
+			}
+		}
+		err = ErrOffline
+	}
+
+	slices.SortStableFunc(units, func(a, b model.Unit) int {
+		dueA, _ := a.Body.Meta.DueAt()
+		dueB, _ := b.Body.Meta.DueAt()
+		return dueA.Compare(dueB)
+	})
+	return units, err
+}
+
+// Units возвращает все единицы данных для обработки на клиенте. Кэш предварительно
+// синхронизируется с сервером, и синхронизация продлевает срок действия всего кэша;
+// без связи возвращаются действительные единицы данных кэша
+func (s service) Units() ([]model.Unit, error) {
+	_, syncErr := s.Sync()
+	if syncErr != nil && syncErr != ErrOffline {
+		return nil, syncErr
+	}
+	units, err := s.cache.GetUnits()
+	if err != nil {
+		return nil, err
+	}
+	return units, syncErr
+}
+
+// Snapshot возвращает все единицы данных полным снимком с сервера, без кэша.
+// Изменения из очереди предварительно отправляются; кэш заменяется снимком.
+// Второе значение - число изменений, оставшихся в очереди (конфликты), они в снимок не входят
+func (s service) Snapshot() ([]model.Unit, int, error) {
+	idx, err := s.indexer()
+	if err != nil {
+		return nil, 0, err
+	}
+	_, conflicts, err := s.replay(idx)
+	if err != nil {
+		return nil, 0, err
+	}
+	changes, err := s.client.Sync(s.cache.GetToken(), idx, 0)
+	if isOffline(err) {
+		return nil, 0, ErrOffline
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	err = s.cache.ApplySync(changes.Units, nil, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.cache.SetCursor(changes.Cursor)
+	return changes.Units, conflicts, nil
+}
+
+// Sync отправляет на сервер изменения из очереди, затем получает изменения
+// с сервера после сохраненного курсора и применяет их к кэшу
+func (s service) Sync() (model.SyncResult, error) {
+	idx, err := s.indexer()
+	if err != nil {
+		return model.SyncResult{}, err
+	}
+
+	replayed, conflicts, err := s.replay(idx)
+	if err != nil {
+		return model.SyncResult{}, err
+	}
+
+	changes, err := s.client.Sync(s.cache.GetToken(), idx, s.cache.GetCursor())
+	if err != nil {
+		if e, ok := status.FromError(err); ok && e.Code() == codes.Unavailable {
+			return model.SyncResult{}, ErrOffline
+		}
+		return model.SyncResult{}, err
+	}
+
+	// Сервер возвращает токены удаленных имен: сопоставление с кэшем
+	cached, _, err := s.cache.GetList()
+	if err != nil {
+		return model.SyncResult{}, err
+	}
+	byToken := make(map[string]string, len(cached))
+	for _, name := range cached {
+		byToken[idx.NameToken(name)] = name
+	}
+	result := model.SyncResult{Replayed: replayed, Conflicts: conflicts}
+	var deleted []string
+	for _, token := range changes.Deleted {
+		if name, ok := byToken[token]; ok {
+			deleted = append(deleted, name)
+		}
+	}
+	received := make(map[string]bool, len(changes.Units))
+	for _, unit := range changes.Units {
+		received[unit.Name] = true
+		if slices.Contains(cached, unit.Name) {
+			result.Updated++
+		} else {
+			result.Created++
+		}
+	}
+	if changes.Full {
+		deleted = nil
+		for _, name := range cached {
+			if !received[name] {
+				deleted = append(deleted, name)
+			}
+		}
+	}
+	result.Deleted = len(deleted)
+
+	err = s.cache.ApplySync(changes.Units, deleted, changes.Full)
+	if err != nil {
+		return model.SyncResult{}, err
+	}
+	s.cache.SetCursor(changes.Cursor)
+	return result, nil
+}
+
+// autoSync выполняет синхронизацию кэша, ошибки не прерывают основную операцию
+func (s service) autoSync() {
+	result, err := s.Sync()
+	if err != nil {
+		s.logger.Sugar().Debugf("sync: %s", err)
+		return
+	}
+	s.logger.Sugar().Debugf("sync: %+v", result)
+}
+
+// Задержка повторного подключения к потоку событий
+const (
+	watchMinBackoff = time.Second
+	watchMaxBackoff = time.Minute
+)
+
+// Watch поддерживает кэш в актуальном состоянии по событиям сервера до отмены ctx.
+// После обрыва соединения подключается повторно с нарастающей задержкой.
+// onEvent вызывается для каждого события после обновления кэша.
+// На время наблюдения кэш не блокируется по бездействию; заблокированный кэш завершает Watch
+func (s service) Watch(ctx context.Context, onEvent func(model.Event)) error {
+	idx, err := s.indexer()
+	if err != nil {
+		return err
+	}
+	release := s.cache.Hold()
+	defer release()
+
+	backoff := watchMinBackoff
+	for {
+		// Без разблокированного кэша подключение шло бы с пустым токеном
+		if s.cache.Locked() {
+			return ErrLocked
+		}
+		connected, err := s.watchStream(ctx, idx, onEvent)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if e, ok := status.FromError(err); ok && e.Code() == codes.Unauthenticated {
+			return err
+		}
+		if isLocked(err) {
+			return err
+		}
+		if connected {
+			backoff = watchMinBackoff
+		}
+		onEvent(model.Event{Kind: model.EventDisconnected, Err: err, Retry: backoff})
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, watchMaxBackoff)
+	}
+}
+
+// watchStream обрабатывает один поток событий до ошибки.
+// Накопившиеся события применяются одной синхронизацией
+func (s service) watchStream(ctx context.Context, idx blindindex.Indexer, onEvent func(model.Event)) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	recv, err := s.client.Watch(ctx, s.cache.GetToken(), idx)
+	if err != nil {
+		return false, err
+	}
+	events := make(chan model.Event, 256)
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			event, err := recv()
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	connected := false
+	for {
+		select {
+		case err := <-errc:
+			return connected, err
+		case event := <-events:
+			batch := []model.Event{event}
+		drain:
+			for {
+				select {
+				case event := <-events:
+					batch = append(batch, event)
+				default:
+					break drain
+				}
+			}
+			if slices.ContainsFunc(batch, func(e model.Event) bool { return e.Kind == model.EventSubscribed }) {
+				connected = true
+			}
+			if err := s.applyEvents(idx, batch, onEvent); err != nil {
+				return connected, err
+			}
+		}
+	}
+}
+
+// isLocked проверяет, что кэш заблокирован и без мастер-пароля работа невозможна
+func isLocked(err error) bool {
+	return errors.Is(err, ErrLocked) || errors.Is(err, cache.ErrLocked)
+}
+
+// applyEvents обновляет кэш по пачке событий и передает их обработчику.
+// Возвращает ошибку, только если кэш заблокирован
+func (s service) applyEvents(idx blindindex.Indexer, batch []model.Event, onEvent func(model.Event)) error {
+	// Имена удаленных известны только по кэшу
+	cached, _, _ := s.cache.GetList()
+	for i, event := range batch {
+		if event.Name != "" || event.Token == "" {
+			continue
+		}
+		for _, name := range cached {
+			if idx.NameToken(name) == event.Token {
+				batch[i].Name = name
+				break
+			}
+		}
+	}
+
+	result, err := s.Sync()
+	switch {
+	case isLocked(err):
+		return err
+	case err != nil:
+		s.logger.Sugar().Debugf("sync: %s", err)
+	default:
+		s.logger.Sugar().Debugf("sync: %+v", result)
+	}
+	if err := s.cache.Flush(); err != nil {
+		s.logger.Sugar().Debugf("cache flush: %s", err)
+	}
+	for _, event := range batch {
+		onEvent(event)
+	}
+	return nil
+}
+
+// Reindex переводит данные, записанные до введения слепого индекса, на токены.
+// Возвращает количество переведенных имен
+func (s service) Reindex() (int, error) {
+	idx, err := s.indexer()
+	if err != nil {
+		return 0, err
+	}
+	token := s.cache.GetToken()
+
+	units, folders, err := s.client.Legacy(token)
+	if err != nil {
+		return 0, err
+	}
+	err = s.client.Reindex(token, idx, units, folders)
+	if err != nil {
+		return 0, err
+	}
+	return len(units) + len(folders), nil
+}
+
+// ListTrash возвращает содержимое корзины
+func (s service) ListTrash() ([]model.TrashItem, error) {
+	idx, err := s.indexer()
+	if err != nil {
+		return nil, err
+	}
+	return s.client.ListTrash(s.cache.GetToken(), idx)
+}
+
+// Restore возвращает единицу данных из корзины.
+// Кэш обновляется при следующем List или Read
+func (s service) Restore(id int64) error {
+	return s.client.Restore(s.cache.GetToken(), id)
+}
+
+// Purge окончательно удаляет единицы данных из корзины. Пустой ids - вся корзина
+func (s service) Purge(ids []int64) (int64, error) {
+	return s.client.Purge(s.cache.GetToken(), ids)
+}
+
+// indexer создает индексатор из ключа, полученного при входе
+func (s service) indexer() (blindindex.Indexer, error) {
+	if s.cache.Locked() {
+		return blindindex.Indexer{}, ErrLocked
+	}
+	key, err := hex.DecodeString(s.cache.GetIndexKey())
+	if err != nil || len(key) == 0 {
+		return blindindex.Indexer{}, ErrNoIndexKey
+	}
+	return blindindex.NewIndexer(key)
+}
+
 // Close
 func (s service) Close() {
 	s.client.Close()
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/client/internal/cache"
+	cacheConfig "github.com/iurnickita/gophkeeper/client/internal/cache/config"
+	grpcclient "github.com/iurnickita/gophkeeper/client/internal/grpc_client/client"
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	pb "github.com/iurnickita/gophkeeper/contract/proto"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// unitServer - сервер единиц данных в памяти. Не реализованные методы возвращают Unimplemented
+type unitServer struct {
+	pb.UnimplementedGophkeeperServer
+	mux   sync.Mutex
+	units map[string]*pb.ReadResponse
+	// Сервер недоступен
+	offline bool
+	// Число запросов Write
+	writes int
+}
+
+func (s *unitServer) Write(ctx context.Context, in *pb.WriteRequest) (*pb.WriteResponse, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.writes++
+	if s.offline {
+		return nil, status.Error(codes.Unavailable, "offline")
+	}
+	now := timestamppb.Now()
+	createdAt := now
+	if current, ok := s.units[in.Unitname]; ok {
+		createdAt = current.CreatedAt
+	}
+	s.units[in.Unitname] = &pb.ReadResponse{Unittype: in.Unittype, Unitdata: in.Unitdata,
+		Description: in.Description, Tags: in.Tags, Fields: in.Fields,
+		CreatedAt: createdAt, UpdatedAt: now, ExpiresAt: in.ExpiresAt, RotateEvery: in.RotateEvery}
+	return &pb.WriteResponse{CreatedAt: createdAt, UpdatedAt: now}, nil
+}
+
+func (s *unitServer) Read(ctx context.Context, in *pb.ReadRequest) (*pb.ReadResponse, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.offline {
+		return nil, status.Error(codes.Unavailable, "offline")
+	}
+	unit, ok := s.units[in.Unitname]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return unit, nil
+}
+
+func (s *unitServer) Due(ctx context.Context, in *pb.DueRequest) (*pb.SearchResponse, error) {
+	return nil, status.Error(codes.Unavailable, "offline")
+}
+
+func (s *unitServer) List(ctx context.Context, in *pb.ListRequest) (*pb.ListResponse, error) {
+	return nil, status.Error(codes.Unavailable, "offline")
+}
+
+func (s *unitServer) Mkdir(ctx context.Context, in *pb.MkdirRequest) (*pb.Empty, error) {
+	return nil, status.Error(codes.Unavailable, "offline")
+}
+
+func (s *unitServer) setOffline(offline bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.offline = offline
+}
+
+// testService создает сервис с сервером в памяти и разблокированным кэшем
+func testService(t *testing.T) (service, *unitServer) {
+	server := &unitServer{units: make(map[string]*pb.ReadResponse)}
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	pb.RegisterGophkeeperServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	c, err := cache.NewCache(cacheConfig.Config{FileRepo: t.TempDir() + "/", ValidPeriod: 1, MasterPassword: "master"}, zap.NewNop())
+	require.NoError(t, err)
+	c.SetIndexKey("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+
+	s := service{client: grpcclient.NewClientConn(conn), cache: c, logger: zap.NewNop()}
+	t.Cleanup(s.Close)
+	return s, server
+}
+
+func TestService_WriteOfflineEdit(t *testing.T) {
+	s, server := testService(t)
+	unit := model.Unit{Name: "bank/login", Body: model.UnitBody{
+		Meta: model.UnitMeta{Type: model.UnitTypeLogin, RotateEvery: 24 * time.Hour},
+		Data: model.LoginData{Login: "alice", Password: "1"}.Bytes(),
+	}}
+
+	// В кэше версия сервера
+	require.NoError(t, s.Write(unit))
+	cached, err := s.cache.GetUnit("bank/login")
+	require.NoError(t, err)
+	require.False(t, cached.Body.Meta.UpdatedAt.IsZero())
+
+	// Без связи срок смены считается от даты сервера
+	server.setOffline(true)
+	due, err := s.Due(0)
+	require.ErrorIs(t, err, ErrOffline)
+	require.Empty(t, due)
+
+	// Изменение без связи основано на версии сервера
+	unit.Body.Data = model.LoginData{Login: "alice", Password: "2"}.Bytes()
+	require.ErrorIs(t, s.Write(unit), ErrQueued)
+	ops := s.cache.GetOutbox()
+	require.Len(t, ops, 1)
+	require.True(t, cached.Body.Meta.UpdatedAt.Equal(ops[0].Base))
+
+	// Отправка без конфликта
+	server.setOffline(false)
+	idx, err := s.indexer()
+	require.NoError(t, err)
+	replayed, conflicts, err := s.replay(idx)
+	require.NoError(t, err)
+	require.Equal(t, 1, replayed)
+	require.Zero(t, conflicts)
+	require.Empty(t, s.cache.GetOutbox())
+	written, err := s.client.Read(s.cache.GetToken(), idx, "bank/login")
+	require.NoError(t, err)
+	require.Equal(t, "2", model.ParseLogin(written.Body.Data).Password)
+}
+
+func TestService_UnlockWrongPassword(t *testing.T) {
+	s, _ := testService(t)
+	_, err := s.cache.PushOutbox(model.Op{Kind: model.OpDelete, Unit: model.Unit{Name: "bank/login"}})
+	require.NoError(t, err)
+	require.NoError(t, s.cache.Lock())
+
+	// Кэш с другим паролем не удаляется при входе и без явного сброса
+	require.ErrorIs(t, s.unlock("account"), cache.ErrWrongPassword)
+	require.ErrorIs(t, s.ResetCache("account"), ErrPendingChanges)
+	require.NoError(t, s.cache.Unlock([]byte("master")))
+	require.Len(t, s.cache.GetOutbox(), 1)
+
+	// Без неотправленных изменений кэш сбрасывается под новый пароль
+	require.NoError(t, s.cache.RemoveOutbox(1))
+	require.NoError(t, s.cache.Lock())
+	require.NoError(t, s.ResetCache("account"))
+	require.False(t, s.cache.Locked())
+	require.NoError(t, s.cache.Lock())
+	require.NoError(t, s.unlock("account"))
+}
+
+func TestService_OfflineMoveMkdir(t *testing.T) {
+	s, _ := testService(t)
+	require.NoError(t, s.cache.SetUnit(model.Unit{Name: "bank/login"}))
+
+	// Без связи перемещения и папки не выполняются и не меняют кэш
+	require.ErrorIs(t, s.Move("bank/login", "archive/login"), ErrOffline)
+	require.ErrorIs(t, s.Rename("bank/login", "card"), ErrOffline)
+	require.ErrorIs(t, s.Mkdir("infra"), ErrOffline)
+	list, folders, err := s.cache.GetList()
+	require.NoError(t, err)
+	require.Equal(t, []string{"bank/login"}, list)
+	require.Empty(t, folders)
+	require.Empty(t, s.cache.GetOutbox())
+}
+
+func TestService_WriteUnits(t *testing.T) {
+	s, server := testService(t)
+	units := []model.Unit{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	results, err := s.WriteUnits(units[:1])
+	require.NoError(t, err)
+	require.Equal(t, []error{nil}, results)
+
+	// После потери связи остальные единицы данных ставятся в очередь без запросов
+	server.setOffline(true)
+	results, err = s.WriteUnits(units[1:])
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		require.ErrorIs(t, result, ErrQueued)
+	}
+	require.Equal(t, 2, server.writes)
+	require.Len(t, s.cache.GetOutbox(), 2)
+
+	// Заблокированный кэш: запись не выполняется
+	require.NoError(t, s.cache.Lock())
+	results, err = s.WriteUnits(units)
+	require.ErrorIs(t, err, ErrLocked)
+	require.Empty(t, results)
+}
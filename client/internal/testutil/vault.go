@@ -0,0 +1,35 @@
+// Пакет testutil. Общие заготовки тестов
+package testutil
+
+import (
+	"slices"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+)
+
+// MemVault - хранилище единиц данных в памяти
+type MemVault struct {
+	Stored []model.Unit
+	// Количество записей
+	Writes int
+}
+
+// Units
+func (v *MemVault) Units() ([]model.Unit, error) { return slices.Clone(v.Stored), nil }
+
+// Write
+func (v *MemVault) Write(unit model.Unit) error {
+	v.Writes++
+	if i := slices.IndexFunc(v.Stored, func(u model.Unit) bool { return u.Name == unit.Name }); i >= 0 {
+		v.Stored[i] = unit
+		return nil
+	}
+	v.Stored = append(v.Stored, unit)
+	return nil
+}
+
+// Delete
+func (v *MemVault) Delete(unitname string) error {
+	v.Stored = slices.DeleteFunc(v.Stored, func(u model.Unit) bool { return u.Name == unitname })
+	return nil
+}
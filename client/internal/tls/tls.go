@@ -9,8 +9,9 @@ import (
 	"google.golang.org/grpc/credentials"
 )
 
-func LoadTLSCredentials() (credentials.TransportCredentials, error) {
-	pemServerCA, err := os.ReadFile("cert/ca-cert.pem")
+// LoadTLSCredentials загружает сертификат удостоверяющего центра сервера
+func LoadTLSCredentials(caFile string) (credentials.TransportCredentials, error) {
+	pemServerCA, err := os.ReadFile(caFile)
 	if err != nil {
 		return nil, err
 	}
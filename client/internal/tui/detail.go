@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+)
+
+// binaryPreview - размер просмотра двоичных данных
+const binaryPreview = 256
+
+// hidden - отображение скрытого значения
+const hidden = "••••••••"
+
+// renderUnit выводит единицу данных по ее типу. Секреты показываются только с reveal
+func renderUnit(unit model.Unit, reveal bool) string {
+	var b strings.Builder
+	secret := func(value string) string {
+		if reveal || value == "" {
+			return value
+		}
+		return hidden
+	}
+	row := func(label string, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-12s", label)), value)
+		}
+	}
+
+	meta := unit.Body.Meta
+	row("type", model.TypeName(meta.Type))
+	b.WriteString("\n")
+
+	switch meta.Type {
+	case model.UnitTypeLogin:
+		login := model.ParseLogin(unit.Body.Data)
+		row("login", login.Login)
+		row("password", secret(login.Password))
+		row("url", login.URL)
+	case model.UnitTypeCard:
+		card := model.ParseCard(unit.Body.Data)
+		if reveal {
+			row("number", card.Number)
+		} else {
+			row("number", card.MaskedNumber())
+		}
+		row("holder", card.Holder)
+		row("expiry", card.Expiry)
+		row("cvv", secret(card.CVV))
+	case model.UnitTypeText:
+		b.WriteString(string(unit.Body.Data))
+		b.WriteString("\n")
+	default:
+		row("size", fmt.Sprintf("%d bytes", len(unit.Body.Data)))
+		preview := unit.Body.Data
+		if len(preview) > binaryPreview {
+			preview = preview[:binaryPreview]
+		}
+		b.WriteString("\n")
+		b.WriteString(hex.Dump(preview))
+	}
+
+	b.WriteString("\n")
+	row("description", meta.Description)
+	row("tags", strings.Join(meta.Tags, ", "))
+	for _, field := range meta.Fields {
+		value := field.Value
+		if field.Sensitive {
+			value = secret(value)
+		}
+		row(field.Key, value)
+	}
+	if !meta.ExpiresAt.IsZero() {
+		row("expires", meta.ExpiresAt.Local().Format(time.DateOnly))
+	}
+	if dueAt, reason := meta.DueAt(); !dueAt.IsZero() && !dueAt.After(time.Now()) {
+		row("due", errStyle.Render(reason+" overdue since "+dueAt.Local().Format(time.DateOnly)))
+	}
+	if !meta.CreatedAt.IsZero() {
+		row("created", meta.CreatedAt.Local().Format(time.DateTime))
+	}
+	if !meta.UpdatedAt.IsZero() {
+		row("updated", meta.UpdatedAt.Local().Format(time.DateTime))
+	}
+	return b.String()
+}
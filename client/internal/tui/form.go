@@ -0,0 +1,295 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+)
+
+var (
+	ErrNoName = errors.New("name is required")
+	ErrNoFile = errors.New("file is required")
+)
+
+// formKind - назначение формы
+type formKind int
+
+const (
+	formUnit formKind = iota
+	formLogin
+)
+
+// Поля форм
+const (
+	fieldName     = "name"
+	fieldLogin    = "login"
+	fieldPassword = "password"
+	fieldURL      = "url"
+	fieldNumber   = "number"
+	fieldHolder   = "holder"
+	fieldExpiry   = "expiry"
+	fieldCVV      = "cvv"
+	fieldFile     = "file"
+	fieldDesc     = "description"
+	fieldTags     = "tags"
+)
+
+// input - поле ввода формы
+type input struct {
+	key   string
+	model textinput.Model
+}
+
+// form - форма создания и изменения единицы данных или входа
+type form struct {
+	kind formKind
+	// Тип единицы данных
+	typ int
+	// Изменяемая единица данных; nil - новая
+	edit   *model.Unit
+	inputs []input
+	// Содержимое текстовой единицы данных, следует за полями ввода
+	text  *textarea.Model
+	focus int
+}
+
+// newUnitForm создает форму единицы данных типа typ. edit - изменяемая единица данных или nil
+func newUnitForm(typ int, edit *model.Unit) form {
+	f := form{kind: formUnit, typ: typ, edit: edit}
+	var data []byte
+	var meta model.UnitMeta
+	if edit != nil {
+		data, meta = edit.Body.Data, edit.Body.Meta
+	} else {
+		f.add(fieldName, "", false)
+	}
+
+	switch typ {
+	case model.UnitTypeLogin:
+		login := model.ParseLogin(data)
+		f.add(fieldLogin, login.Login, false)
+		f.add(fieldPassword, login.Password, true)
+		f.add(fieldURL, login.URL, false)
+	case model.UnitTypeCard:
+		card := model.ParseCard(data)
+		f.add(fieldNumber, card.Number, false)
+		f.add(fieldHolder, card.Holder, false)
+		f.add(fieldExpiry, card.Expiry, false)
+		f.add(fieldCVV, card.CVV, true)
+	case model.UnitTypeBinary:
+		f.add(fieldFile, "", false)
+		if edit != nil {
+			f.inputs[len(f.inputs)-1].model.Placeholder = "empty - keep current data"
+		}
+	}
+	f.add(fieldDesc, meta.Description, false)
+	f.add(fieldTags, strings.Join(meta.Tags, ", "), false)
+
+	if typ == model.UnitTypeText {
+		text := textarea.New()
+		text.ShowLineNumbers = false
+		text.SetValue(string(data))
+		f.text = &text
+	}
+	f.focusCurrent()
+	return f
+}
+
+// newLoginForm создает форму входа
+func newLoginForm() form {
+	f := form{kind: formLogin}
+	f.add(fieldLogin, "", false)
+	f.add(fieldPassword, "", true)
+	f.focusCurrent()
+	return f
+}
+
+// add добавляет поле ввода. secret - значение не отображается
+func (f *form) add(key string, value string, secret bool) {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.SetValue(value)
+	if secret {
+		ti.EchoMode = textinput.EchoPassword
+	}
+	f.inputs = append(f.inputs, input{key: key, model: ti})
+}
+
+// value возвращает значение поля. Пробелы по краям секретов сохраняются
+func (f *form) value(key string) string {
+	for _, in := range f.inputs {
+		if in.key != key {
+			continue
+		}
+		if in.model.EchoMode == textinput.EchoPassword {
+			return in.model.Value()
+		}
+		return strings.TrimSpace(in.model.Value())
+	}
+	return ""
+}
+
+// count - количество элементов формы
+func (f *form) count() int {
+	if f.text != nil {
+		return len(f.inputs) + 1
+	}
+	return len(f.inputs)
+}
+
+// inText проверяет, что в фокусе многострочное поле
+func (f *form) inText() bool {
+	return f.text != nil && f.focus == len(f.inputs)
+}
+
+// last проверяет, что в фокусе последний элемент
+func (f *form) last() bool {
+	return f.focus == f.count()-1
+}
+
+// next переводит фокус на следующий элемент
+func (f *form) next() {
+	f.focus = (f.focus + 1) % f.count()
+	f.focusCurrent()
+}
+
+// prev переводит фокус на предыдущий элемент
+func (f *form) prev() {
+	f.focus = (f.focus + f.count() - 1) % f.count()
+	f.focusCurrent()
+}
+
+// focusCurrent передает фокус элементу f.focus
+func (f *form) focusCurrent() {
+	for i := range f.inputs {
+		if i == f.focus {
+			f.inputs[i].model.Focus()
+		} else {
+			f.inputs[i].model.Blur()
+		}
+	}
+	if f.text != nil {
+		if f.inText() {
+			f.text.Focus()
+		} else {
+			f.text.Blur()
+		}
+	}
+}
+
+// resize подстраивает поля под ширину экрана
+func (f *form) resize(width int) {
+	for i := range f.inputs {
+		f.inputs[i].model.Width = max(width-16, 10)
+	}
+	if f.text != nil {
+		f.text.SetWidth(max(width-2, 10))
+		f.text.SetHeight(10)
+	}
+}
+
+// update передает сообщение элементу в фокусе
+func (f *form) update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	if f.inText() {
+		*f.text, cmd = f.text.Update(msg)
+		return cmd
+	}
+	if f.focus < len(f.inputs) {
+		f.inputs[f.focus].model, cmd = f.inputs[f.focus].model.Update(msg)
+	}
+	return cmd
+}
+
+// credentials возвращает значения формы входа
+func (f *form) credentials() (string, string) {
+	return f.value(fieldLogin), f.value(fieldPassword)
+}
+
+// unit собирает единицу данных из формы. Прочие метаданные изменяемой единицы сохраняются
+func (f *form) unit() (model.Unit, error) {
+	var unit model.Unit
+	if f.edit != nil {
+		unit = *f.edit
+	} else {
+		unit.Name = f.value(fieldName)
+		unit.Body.Meta.Type = f.typ
+	}
+	if unit.Name == "" {
+		return model.Unit{}, ErrNoName
+	}
+
+	switch f.typ {
+	case model.UnitTypeLogin:
+		unit.Body.Data = model.LoginData{
+			Login:    f.value(fieldLogin),
+			Password: f.value(fieldPassword),
+			URL:      f.value(fieldURL),
+		}.Bytes()
+	case model.UnitTypeCard:
+		unit.Body.Data = model.CardData{
+			Number: f.value(fieldNumber),
+			Holder: f.value(fieldHolder),
+			Expiry: f.value(fieldExpiry),
+			CVV:    f.value(fieldCVV),
+		}.Bytes()
+	case model.UnitTypeText:
+		unit.Body.Data = []byte(f.text.Value())
+	case model.UnitTypeBinary:
+		path := f.value(fieldFile)
+		switch {
+		case path != "":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return model.Unit{}, err
+			}
+			unit.Body.Data = data
+		case f.edit == nil:
+			return model.Unit{}, ErrNoFile
+		}
+	}
+
+	unit.Body.Meta.Description = f.value(fieldDesc)
+	unit.Body.Meta.Tags = nil
+	for _, tag := range strings.Split(f.value(fieldTags), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			unit.Body.Meta.Tags = append(unit.Body.Meta.Tags, tag)
+		}
+	}
+	return unit, nil
+}
+
+// view выводит форму
+func (f *form) view() string {
+	var b strings.Builder
+	switch {
+	case f.kind == formLogin:
+		b.WriteString(titleStyle.Render("Login: cache is locked"))
+	case f.edit != nil:
+		b.WriteString(titleStyle.Render(fmt.Sprintf("Edit %s %s", model.TypeName(f.typ), f.edit.Name)))
+	default:
+		b.WriteString(titleStyle.Render("New " + model.TypeName(f.typ)))
+	}
+	b.WriteString("\n\n")
+	for _, in := range f.inputs {
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-12s", in.key)), in.model.View())
+	}
+	if f.text != nil {
+		b.WriteString("\n")
+		b.WriteString(f.text.View())
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	if f.kind == formLogin {
+		b.WriteString(labelStyle.Render("enter next/login · esc quit"))
+	} else {
+		b.WriteString(labelStyle.Render("tab next · enter next/save · ctrl+s save · esc cancel"))
+	}
+	return b.String()
+}
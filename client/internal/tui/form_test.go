@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForm_Unit(t *testing.T) {
+	set := func(f *form, key string, value string) {
+		for i := range f.inputs {
+			if f.inputs[i].key == key {
+				f.inputs[i].model.SetValue(value)
+			}
+		}
+	}
+
+	// Новая карта
+	f := newUnitForm(model.UnitTypeCard, nil)
+	_, err := f.unit()
+	require.ErrorIs(t, err, ErrNoName)
+	set(&f, fieldName, " bank/visa ")
+	set(&f, fieldNumber, "4111 1111 1111 1234")
+	set(&f, fieldCVV, "123")
+	set(&f, fieldTags, "bank, , personal")
+	unit, err := f.unit()
+	require.NoError(t, err)
+	require.Equal(t, "bank/visa", unit.Name)
+	require.Equal(t, model.UnitTypeCard, unit.Body.Meta.Type)
+	require.Equal(t, []string{"bank", "personal"}, unit.Body.Meta.Tags)
+	require.Equal(t, model.CardData{Number: "4111 1111 1111 1234", CVV: "123"}, model.ParseCard(unit.Body.Data))
+
+	// Изменение входа сохраняет прочие метаданные
+	login := model.Unit{Name: "web/mail", Body: model.UnitBody{
+		Meta: model.UnitMeta{Type: model.UnitTypeLogin, Fields: []model.Field{{Key: "pin", Value: "1", Sensitive: true}}},
+		Data: model.LoginData{Login: "bob", Password: "old"}.Bytes(),
+	}}
+	f = newUnitForm(model.UnitTypeLogin, &login)
+	set(&f, fieldPassword, " new ")
+	unit, err = f.unit()
+	require.NoError(t, err)
+	require.Equal(t, model.LoginData{Login: "bob", Password: " new "}, model.ParseLogin(unit.Body.Data))
+	require.Equal(t, login.Body.Meta.Fields, unit.Body.Meta.Fields)
+
+	// Двоичные данные из файла; при изменении без файла данные сохраняются
+	file := filepath.Join(t.TempDir(), "key.bin")
+	require.NoError(t, os.WriteFile(file, []byte{0, 1, 2}, 0600))
+	f = newUnitForm(model.UnitTypeBinary, nil)
+	set(&f, fieldName, "keys/ssh")
+	_, err = f.unit()
+	require.ErrorIs(t, err, ErrNoFile)
+	set(&f, fieldFile, file)
+	unit, err = f.unit()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 1, 2}, unit.Body.Data)
+	f = newUnitForm(model.UnitTypeBinary, &unit)
+	unit, err = f.unit()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 1, 2}, unit.Body.Data)
+}
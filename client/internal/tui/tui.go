@@ -0,0 +1,489 @@
+// Пакет tui. Полноэкранный интерфейс: список данных с поиском, просмотр по типам,
+// формы создания и изменения, состояние связи и синхронизации
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/iurnickita/gophkeeper/client/internal/model"
+	"github.com/iurnickita/gophkeeper/client/internal/service"
+)
+
+// Run запускает интерфейс поверх сервиса до выхода пользователя
+func Run(svc service.Service, profile string) error {
+	_, err := tea.NewProgram(newUI(svc, profile), tea.WithAltScreen()).Run()
+	return err
+}
+
+// screen - текущий экран
+type screen int
+
+const (
+	screenList screen = iota
+	screenDetail
+	screenType
+	screenForm
+	screenDelete
+)
+
+// Результаты команд сервиса
+type (
+	listMsg struct {
+		names []string
+		err   error
+	}
+	unitMsg struct {
+		unit model.Unit
+		err  error
+	}
+	savedMsg struct {
+		name string
+		err  error
+	}
+	deletedMsg struct {
+		name string
+		err  error
+	}
+	syncMsg struct {
+		result model.SyncResult
+		err    error
+	}
+	loginMsg struct {
+		err error
+	}
+)
+
+// item - строка списка
+type item string
+
+func (i item) FilterValue() string { return string(i) }
+func (i item) Title() string       { return string(i) }
+func (i item) Description() string { return "" }
+
+// Клавиши, показываемые в подсказке списка
+var (
+	keyOpen    = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open"))
+	keyNew     = key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new"))
+	keySync    = key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sync"))
+	keyRefresh = key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh"))
+)
+
+// ui - состояние интерфейса
+type ui struct {
+	svc     service.Service
+	profile string
+	screen  screen
+
+	list   list.Model
+	detail viewport.Model
+	unit   model.Unit
+	reveal bool
+	form   form
+
+	// Состояние связи и синхронизации
+	offline  bool
+	syncing  bool
+	syncedAt time.Time
+	synced   model.SyncResult
+	message  string
+	err      error
+
+	width, height int
+}
+
+// newUI создает интерфейс
+func newUI(svc service.Service, profile string) *ui {
+	delegate := list.NewDefaultDelegate()
+	delegate.ShowDescription = false
+	delegate.SetSpacing(0)
+	l := list.New(nil, delegate, 0, 0)
+	l.Title = "GophKeeper"
+	l.DisableQuitKeybindings()
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{keyOpen, keyNew, keySync, keyRefresh}
+	}
+	return &ui{svc: svc, profile: profile, list: l, detail: viewport.New(0, 0)}
+}
+
+// Init
+func (m *ui) Init() tea.Cmd {
+	return m.loadList
+}
+
+// Update
+func (m *ui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.list.SetSize(msg.Width, msg.Height-1)
+		m.detail.Width, m.detail.Height = msg.Width, msg.Height-2
+		m.form.resize(msg.Width)
+		return m, nil
+
+	case listMsg:
+		m.setErr(msg.err)
+		if msg.err != nil && !errors.Is(msg.err, service.ErrOffline) {
+			if errors.Is(msg.err, service.ErrLocked) || errors.Is(msg.err, service.ErrNoIndexKey) {
+				m.openForm(newLoginForm())
+			}
+			return m, nil
+		}
+		items := make([]list.Item, len(msg.names))
+		for i, name := range msg.names {
+			items[i] = item(name)
+		}
+		return m, m.list.SetItems(items)
+
+	case unitMsg:
+		m.setErr(msg.err)
+		if msg.err != nil && !errors.Is(msg.err, service.ErrOffline) {
+			return m, nil
+		}
+		m.unit, m.reveal = msg.unit, false
+		m.screen = screenDetail
+		m.renderDetail()
+		return m, nil
+
+	case savedMsg:
+		m.setErr(msg.err)
+		if msg.err != nil && !errors.Is(msg.err, service.ErrQueued) {
+			return m, nil
+		}
+		if msg.err == nil {
+			m.message = "saved " + msg.name
+		}
+		m.screen = screenList
+		return m, tea.Batch(m.loadList, m.read(msg.name))
+
+	case deletedMsg:
+		m.setErr(msg.err)
+		if msg.err != nil && !errors.Is(msg.err, service.ErrQueued) {
+			return m, nil
+		}
+		if msg.err == nil {
+			m.message = "deleted " + msg.name
+		}
+		m.screen = screenList
+		return m, m.loadList
+
+	case syncMsg:
+		m.syncing = false
+		m.setErr(msg.err)
+		if msg.err == nil {
+			m.syncedAt, m.synced = time.Now(), msg.result
+		}
+		return m, m.loadList
+
+	case loginMsg:
+		m.setErr(msg.err)
+		if msg.err != nil {
+			return m, nil
+		}
+		m.screen = screenList
+		return m, m.loadList
+
+	case tea.KeyMsg:
+		m.message = ""
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		switch m.screen {
+		case screenList:
+			return m.updateList(msg)
+		case screenDetail:
+			return m.updateDetail(msg)
+		case screenType:
+			return m.updateType(msg)
+		case screenForm:
+			return m.updateForm(msg)
+		case screenDelete:
+			return m.updateDelete(msg)
+		}
+	}
+
+	// Прочие сообщения (мигание курсора, фильтр списка)
+	var cmd tea.Cmd
+	switch m.screen {
+	case screenList:
+		m.list, cmd = m.list.Update(msg)
+	case screenForm:
+		cmd = m.form.update(msg)
+	}
+	return m, cmd
+}
+
+// updateList - клавиши списка
+func (m *ui) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.list.FilterState() != list.Filtering {
+		switch msg.String() {
+		case "q":
+			return m, tea.Quit
+		case "enter":
+			if selected, ok := m.list.SelectedItem().(item); ok {
+				return m, m.read(string(selected))
+			}
+			return m, nil
+		case "n":
+			m.screen = screenType
+			return m, nil
+		case "s":
+			return m, m.sync()
+		case "r":
+			return m, m.loadList
+		}
+	}
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// updateDetail - клавиши просмотра
+func (m *ui) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "esc", "backspace", "left", "h":
+		m.screen = screenList
+		return m, nil
+	case "p":
+		m.reveal = !m.reveal
+		m.renderDetail()
+		return m, nil
+	case "e":
+		m.openForm(newUnitForm(m.unit.Body.Meta.Type, &m.unit))
+		return m, nil
+	case "d":
+		m.screen = screenDelete
+		return m, nil
+	case "s":
+		return m, m.sync()
+	}
+	var cmd tea.Cmd
+	m.detail, cmd = m.detail.Update(msg)
+	return m, cmd
+}
+
+// updateType - выбор типа новой единицы данных
+func (m *ui) updateType(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "1":
+		m.openForm(newUnitForm(model.UnitTypeLogin, nil))
+	case "2":
+		m.openForm(newUnitForm(model.UnitTypeText, nil))
+	case "3":
+		m.openForm(newUnitForm(model.UnitTypeBinary, nil))
+	case "4":
+		m.openForm(newUnitForm(model.UnitTypeCard, nil))
+	case "esc", "q":
+		m.screen = screenList
+	}
+	return m, nil
+}
+
+// updateForm - клавиши формы
+func (m *ui) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		if m.form.kind == formLogin {
+			return m, tea.Quit
+		}
+		m.screen = screenList
+		if m.form.edit != nil {
+			m.screen = screenDetail
+		}
+		return m, nil
+	case "ctrl+s":
+		return m, m.submit()
+	case "enter":
+		// В многострочном поле enter - перевод строки
+		if !m.form.inText() {
+			if m.form.last() {
+				return m, m.submit()
+			}
+			m.form.next()
+			return m, nil
+		}
+	case "tab", "down":
+		if !m.form.inText() || msg.String() == "tab" {
+			m.form.next()
+			return m, nil
+		}
+	case "shift+tab", "up":
+		if !m.form.inText() || msg.String() == "shift+tab" {
+			m.form.prev()
+			return m, nil
+		}
+	}
+	return m, m.form.update(msg)
+}
+
+// updateDelete - подтверждение удаления
+func (m *ui) updateDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y":
+		return m, m.delete(m.unit.Name)
+	default:
+		m.screen = screenDetail
+	}
+	return m, nil
+}
+
+// openForm показывает форму
+func (m *ui) openForm(f form) {
+	m.form = f
+	m.form.resize(m.width)
+	m.err = nil
+	m.screen = screenForm
+}
+
+// submit отправляет форму
+func (m *ui) submit() tea.Cmd {
+	if m.form.kind == formLogin {
+		login, password := m.form.credentials()
+		return func() tea.Msg {
+			return loginMsg{err: m.svc.Login(login, password)}
+		}
+	}
+	unit, err := m.form.unit()
+	if err != nil {
+		m.err = err
+		return nil
+	}
+	return func() tea.Msg {
+		return savedMsg{name: unit.Name, err: m.svc.Write(unit)}
+	}
+}
+
+// setErr отображает результат операции. Офлайн - не ошибка, а состояние
+func (m *ui) setErr(err error) {
+	m.err = nil
+	switch {
+	case err == nil:
+		m.offline = false
+	case errors.Is(err, service.ErrOffline):
+		m.offline = true
+	case errors.Is(err, service.ErrQueued):
+		m.offline = true
+		m.message = err.Error()
+	default:
+		m.err = err
+	}
+}
+
+// loadList загружает список имен
+func (m *ui) loadList() tea.Msg {
+	names, _, err := m.svc.List("", true)
+	return listMsg{names: names, err: err}
+}
+
+// read загружает единицу данных
+func (m *ui) read(name string) tea.Cmd {
+	return func() tea.Msg {
+		unit, err := m.svc.Read(name)
+		return unitMsg{unit: unit, err: err}
+	}
+}
+
+// delete удаляет единицу данных
+func (m *ui) delete(name string) tea.Cmd {
+	return func() tea.Msg {
+		return deletedMsg{name: name, err: m.svc.Delete(name)}
+	}
+}
+
+// sync синхронизирует кэш
+func (m *ui) sync() tea.Cmd {
+	if m.syncing {
+		return nil
+	}
+	m.syncing = true
+	return func() tea.Msg {
+		result, err := m.svc.Sync()
+		return syncMsg{result: result, err: err}
+	}
+}
+
+// renderDetail обновляет содержимое просмотра
+func (m *ui) renderDetail() {
+	m.detail.SetContent(renderUnit(m.unit, m.reveal))
+	m.detail.GotoTop()
+}
+
+// Стили
+var (
+	titleStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	labelStyle   = lipgloss.NewStyle().Faint(true)
+	errStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	onlineStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	offlineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	statusStyle  = lipgloss.NewStyle().Reverse(true)
+)
+
+// View
+func (m *ui) View() string {
+	var body string
+	switch m.screen {
+	case screenList:
+		body = m.list.View()
+	case screenDetail:
+		body = titleStyle.Render(m.unit.Name) + "\n" + m.detail.View()
+	case screenDelete:
+		body = titleStyle.Render(m.unit.Name) + "\n\nDelete to trash? y/n"
+	case screenType:
+		body = titleStyle.Render("New unit") + "\n\n1 login\n2 text\n3 binary\n4 card\n\nesc cancel"
+	case screenForm:
+		body = m.form.view()
+	}
+	lines := strings.Count(body, "\n") + 1
+	if pad := m.height - 1 - lines; pad > 0 {
+		body += strings.Repeat("\n", pad)
+	}
+	return body + "\n" + m.statusLine()
+}
+
+// statusLine - строка состояния: профиль, связь, синхронизация, очередь, сообщение
+func (m *ui) statusLine() string {
+	parts := []string{m.profile}
+	if m.offline {
+		parts = append(parts, offlineStyle.Render("○ offline"))
+	} else {
+		parts = append(parts, onlineStyle.Render("● online"))
+	}
+	switch {
+	case m.syncing:
+		parts = append(parts, "syncing…")
+	case !m.syncedAt.IsZero():
+		parts = append(parts, fmt.Sprintf("synced %s +%d ~%d -%d",
+			m.syncedAt.Format(time.TimeOnly), m.synced.Created, m.synced.Updated, m.synced.Deleted))
+	}
+	pending, conflicts := 0, 0
+	for _, op := range m.svc.Outbox() {
+		pending++
+		if op.Conflict != nil {
+			conflicts++
+		}
+	}
+	if pending > 0 {
+		parts = append(parts, fmt.Sprintf("outbox %d", pending))
+	}
+	if conflicts > 0 {
+		parts = append(parts, errStyle.Render(fmt.Sprintf("%d conflicts", conflicts)))
+	}
+	if m.err != nil {
+		parts = append(parts, errStyle.Render(m.err.Error()))
+	} else if m.message != "" {
+		parts = append(parts, m.message)
+	}
+	if m.screen == screenDetail {
+		parts = append(parts, labelStyle.Render("p reveal · e edit · d delete · esc back"))
+	}
+	return statusStyle.Render(" ") + " " + strings.Join(parts, " │ ")
+}
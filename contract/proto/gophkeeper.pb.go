@@ -9,6 +9,8 @@ package proto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,6 +23,58 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type WatchEvent_Kind int32
+
+const (
+	WatchEvent_SUBSCRIBED WatchEvent_Kind = 0
+	WatchEvent_CREATED    WatchEvent_Kind = 1
+	WatchEvent_UPDATED    WatchEvent_Kind = 2
+	WatchEvent_DELETED    WatchEvent_Kind = 3
+)
+
+// Enum value maps for WatchEvent_Kind.
+var (
+	WatchEvent_Kind_name = map[int32]string{
+		0: "SUBSCRIBED",
+		1: "CREATED",
+		2: "UPDATED",
+		3: "DELETED",
+	}
+	WatchEvent_Kind_value = map[string]int32{
+		"SUBSCRIBED": 0,
+		"CREATED":    1,
+		"UPDATED":    2,
+		"DELETED":    3,
+	}
+)
+
+func (x WatchEvent_Kind) Enum() *WatchEvent_Kind {
+	p := new(WatchEvent_Kind)
+	*p = x
+	return p
+}
+
+func (x WatchEvent_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WatchEvent_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_gophkeeper_proto_enumTypes[0].Descriptor()
+}
+
+func (WatchEvent_Kind) Type() protoreflect.EnumType {
+	return &file_proto_gophkeeper_proto_enumTypes[0]
+}
+
+func (x WatchEvent_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WatchEvent_Kind.Descriptor instead.
+func (WatchEvent_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{25, 0}
+}
+
 type Empty struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -57,6 +111,188 @@ func (*Empty) Descriptor() ([]byte, []int) {
 	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{0}
 }
 
+type Field struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Sensitive     bool                   `protobuf:"varint,3,opt,name=sensitive,proto3" json:"sensitive,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Field) Reset() {
+	*x = Field{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Field) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Field) ProtoMessage() {}
+
+func (x *Field) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Field.ProtoReflect.Descriptor instead.
+func (*Field) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Field) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Field) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Field) GetSensitive() bool {
+	if x != nil {
+		return x.Sensitive
+	}
+	return false
+}
+
+// Имя в слепом индексе: токен HMAC и зашифрованное клиентом значение
+type NameRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Enc           []byte                 `protobuf:"bytes,2,opt,name=enc,proto3" json:"enc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NameRef) Reset() {
+	*x = NameRef{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NameRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NameRef) ProtoMessage() {}
+
+func (x *NameRef) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NameRef.ProtoReflect.Descriptor instead.
+func (*NameRef) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NameRef) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *NameRef) GetEnc() []byte {
+	if x != nil {
+		return x.Enc
+	}
+	return nil
+}
+
+// Слепой индекс единицы данных, рассчитывается клиентом
+type BlindIndex struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NameEnc       []byte                 `protobuf:"bytes,1,opt,name=name_enc,json=nameEnc,proto3" json:"name_enc,omitempty"`
+	NameNgrams    []string               `protobuf:"bytes,2,rep,name=name_ngrams,json=nameNgrams,proto3" json:"name_ngrams,omitempty"`
+	Folders       []*NameRef             `protobuf:"bytes,3,rep,name=folders,proto3" json:"folders,omitempty"`
+	TagTokens     []string               `protobuf:"bytes,4,rep,name=tag_tokens,json=tagTokens,proto3" json:"tag_tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlindIndex) Reset() {
+	*x = BlindIndex{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlindIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlindIndex) ProtoMessage() {}
+
+func (x *BlindIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlindIndex.ProtoReflect.Descriptor instead.
+func (*BlindIndex) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BlindIndex) GetNameEnc() []byte {
+	if x != nil {
+		return x.NameEnc
+	}
+	return nil
+}
+
+func (x *BlindIndex) GetNameNgrams() []string {
+	if x != nil {
+		return x.NameNgrams
+	}
+	return nil
+}
+
+func (x *BlindIndex) GetFolders() []*NameRef {
+	if x != nil {
+		return x.Folders
+	}
+	return nil
+}
+
+func (x *BlindIndex) GetTagTokens() []string {
+	if x != nil {
+		return x.TagTokens
+	}
+	return nil
+}
+
 type RegisterRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Login         string                 `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`
@@ -67,7 +303,7 @@ type RegisterRequest struct {
 
 func (x *RegisterRequest) Reset() {
 	*x = RegisterRequest{}
-	mi := &file_proto_gophkeeper_proto_msgTypes[1]
+	mi := &file_proto_gophkeeper_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -79,7 +315,7 @@ func (x *RegisterRequest) String() string {
 func (*RegisterRequest) ProtoMessage() {}
 
 func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gophkeeper_proto_msgTypes[1]
+	mi := &file_proto_gophkeeper_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -92,7 +328,7 @@ func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
 func (*RegisterRequest) Descriptor() ([]byte, []int) {
-	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{1}
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *RegisterRequest) GetLogin() string {
@@ -118,7 +354,7 @@ type RegisterResponse struct {
 
 func (x *RegisterResponse) Reset() {
 	*x = RegisterResponse{}
-	mi := &file_proto_gophkeeper_proto_msgTypes[2]
+	mi := &file_proto_gophkeeper_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -130,7 +366,7 @@ func (x *RegisterResponse) String() string {
 func (*RegisterResponse) ProtoMessage() {}
 
 func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gophkeeper_proto_msgTypes[2]
+	mi := &file_proto_gophkeeper_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -143,7 +379,7 @@ func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
 func (*RegisterResponse) Descriptor() ([]byte, []int) {
-	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{2}
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *RegisterResponse) GetToken() string {
@@ -163,7 +399,7 @@ type AuthenticateRequest struct {
 
 func (x *AuthenticateRequest) Reset() {
 	*x = AuthenticateRequest{}
-	mi := &file_proto_gophkeeper_proto_msgTypes[3]
+	mi := &file_proto_gophkeeper_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -175,7 +411,7 @@ func (x *AuthenticateRequest) String() string {
 func (*AuthenticateRequest) ProtoMessage() {}
 
 func (x *AuthenticateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gophkeeper_proto_msgTypes[3]
+	mi := &file_proto_gophkeeper_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -188,7 +424,7 @@ func (x *AuthenticateRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AuthenticateRequest.ProtoReflect.Descriptor instead.
 func (*AuthenticateRequest) Descriptor() ([]byte, []int) {
-	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{3}
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *AuthenticateRequest) GetLogin() string {
@@ -214,7 +450,7 @@ type AuthenticateResponse struct {
 
 func (x *AuthenticateResponse) Reset() {
 	*x = AuthenticateResponse{}
-	mi := &file_proto_gophkeeper_proto_msgTypes[4]
+	mi := &file_proto_gophkeeper_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -226,7 +462,7 @@ func (x *AuthenticateResponse) String() string {
 func (*AuthenticateResponse) ProtoMessage() {}
 
 func (x *AuthenticateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gophkeeper_proto_msgTypes[4]
+	mi := &file_proto_gophkeeper_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -239,7 +475,7 @@ func (x *AuthenticateResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AuthenticateResponse.ProtoReflect.Descriptor instead.
 func (*AuthenticateResponse) Descriptor() ([]byte, []int) {
-	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{4}
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *AuthenticateResponse) GetToken() string {
@@ -249,16 +485,69 @@ func (x *AuthenticateResponse) GetToken() string {
 	return ""
 }
 
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Recursive     bool                   `protobuf:"varint,2,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ListRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
 type ListResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Unitname      []string               `protobuf:"bytes,1,rep,name=unitname,proto3" json:"unitname,omitempty"`
+	Units         []*NameRef             `protobuf:"bytes,1,rep,name=units,proto3" json:"units,omitempty"`
+	Folders       []*NameRef             `protobuf:"bytes,2,rep,name=folders,proto3" json:"folders,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ListResponse) Reset() {
 	*x = ListResponse{}
-	mi := &file_proto_gophkeeper_proto_msgTypes[5]
+	mi := &file_proto_gophkeeper_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -270,7 +559,7 @@ func (x *ListResponse) String() string {
 func (*ListResponse) ProtoMessage() {}
 
 func (x *ListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gophkeeper_proto_msgTypes[5]
+	mi := &file_proto_gophkeeper_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -283,12 +572,19 @@ func (x *ListResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
 func (*ListResponse) Descriptor() ([]byte, []int) {
-	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{5}
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *ListResponse) GetUnitname() []string {
+func (x *ListResponse) GetUnits() []*NameRef {
 	if x != nil {
-		return x.Unitname
+		return x.Units
+	}
+	return nil
+}
+
+func (x *ListResponse) GetFolders() []*NameRef {
+	if x != nil {
+		return x.Folders
 	}
 	return nil
 }
@@ -302,7 +598,7 @@ type ReadRequest struct {
 
 func (x *ReadRequest) Reset() {
 	*x = ReadRequest{}
-	mi := &file_proto_gophkeeper_proto_msgTypes[6]
+	mi := &file_proto_gophkeeper_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -314,7 +610,7 @@ func (x *ReadRequest) String() string {
 func (*ReadRequest) ProtoMessage() {}
 
 func (x *ReadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gophkeeper_proto_msgTypes[6]
+	mi := &file_proto_gophkeeper_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -327,7 +623,7 @@ func (x *ReadRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReadRequest.ProtoReflect.Descriptor instead.
 func (*ReadRequest) Descriptor() ([]byte, []int) {
-	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{6}
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *ReadRequest) GetUnitname() string {
@@ -341,13 +637,20 @@ type ReadResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Unittype      int32                  `protobuf:"varint,1,opt,name=unittype,proto3" json:"unittype,omitempty"`
 	Unitdata      []byte                 `protobuf:"bytes,2,opt,name=unitdata,proto3" json:"unitdata,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Tags          []string               `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	Fields        []*Field               `protobuf:"bytes,5,rep,name=fields,proto3" json:"fields,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	RotateEvery   *durationpb.Duration   `protobuf:"bytes,9,opt,name=rotate_every,json=rotateEvery,proto3" json:"rotate_every,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ReadResponse) Reset() {
 	*x = ReadResponse{}
-	mi := &file_proto_gophkeeper_proto_msgTypes[7]
+	mi := &file_proto_gophkeeper_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -359,7 +662,7 @@ func (x *ReadResponse) String() string {
 func (*ReadResponse) ProtoMessage() {}
 
 func (x *ReadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gophkeeper_proto_msgTypes[7]
+	mi := &file_proto_gophkeeper_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -372,7 +675,7 @@ func (x *ReadResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReadResponse.ProtoReflect.Descriptor instead.
 func (*ReadResponse) Descriptor() ([]byte, []int) {
-	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{7}
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *ReadResponse) GetUnittype() int32 {
@@ -389,30 +692,770 @@ func (x *ReadResponse) GetUnitdata() []byte {
 	return nil
 }
 
-type WriteRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Unitname      string                 `protobuf:"bytes,1,opt,name=unitname,proto3" json:"unitname,omitempty"`
-	Unittype      int32                  `protobuf:"varint,2,opt,name=unittype,proto3" json:"unittype,omitempty"`
-	Unitdata      []byte                 `protobuf:"bytes,3,opt,name=unitdata,proto3" json:"unitdata,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ReadResponse) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
 }
 
-func (x *WriteRequest) Reset() {
-	*x = WriteRequest{}
-	mi := &file_proto_gophkeeper_proto_msgTypes[8]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *ReadResponse) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
 }
 
-func (x *WriteRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ReadResponse) GetFields() []*Field {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
 }
 
-func (*WriteRequest) ProtoMessage() {}
-
-func (x *WriteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gophkeeper_proto_msgTypes[8]
+func (x *ReadResponse) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *ReadResponse) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *ReadResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *ReadResponse) GetRotateEvery() *durationpb.Duration {
+	if x != nil {
+		return x.RotateEvery
+	}
+	return nil
+}
+
+type WriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Unitname      string                 `protobuf:"bytes,1,opt,name=unitname,proto3" json:"unitname,omitempty"`
+	Unittype      int32                  `protobuf:"varint,2,opt,name=unittype,proto3" json:"unittype,omitempty"`
+	Unitdata      []byte                 `protobuf:"bytes,3,opt,name=unitdata,proto3" json:"unitdata,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Tags          []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	Fields        []*Field               `protobuf:"bytes,6,rep,name=fields,proto3" json:"fields,omitempty"`
+	Index         *BlindIndex            `protobuf:"bytes,7,opt,name=index,proto3" json:"index,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	RotateEvery   *durationpb.Duration   `protobuf:"bytes,9,opt,name=rotate_every,json=rotateEvery,proto3" json:"rotate_every,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteRequest) Reset() {
+	*x = WriteRequest{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteRequest) ProtoMessage() {}
+
+func (x *WriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteRequest.ProtoReflect.Descriptor instead.
+func (*WriteRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WriteRequest) GetUnitname() string {
+	if x != nil {
+		return x.Unitname
+	}
+	return ""
+}
+
+func (x *WriteRequest) GetUnittype() int32 {
+	if x != nil {
+		return x.Unittype
+	}
+	return 0
+}
+
+func (x *WriteRequest) GetUnitdata() []byte {
+	if x != nil {
+		return x.Unitdata
+	}
+	return nil
+}
+
+func (x *WriteRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *WriteRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *WriteRequest) GetFields() []*Field {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *WriteRequest) GetIndex() *BlindIndex {
+	if x != nil {
+		return x.Index
+	}
+	return nil
+}
+
+func (x *WriteRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *WriteRequest) GetRotateEvery() *durationpb.Duration {
+	if x != nil {
+		return x.RotateEvery
+	}
+	return nil
+}
+
+type WriteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteResponse) Reset() {
+	*x = WriteResponse{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteResponse) ProtoMessage() {}
+
+func (x *WriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteResponse.ProtoReflect.Descriptor instead.
+func (*WriteResponse) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WriteResponse) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *WriteResponse) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Unitname      string                 `protobuf:"bytes,1,opt,name=unitname,proto3" json:"unitname,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DeleteRequest) GetUnitname() string {
+	if x != nil {
+		return x.Unitname
+	}
+	return ""
+}
+
+type Rename struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Index         *BlindIndex            `protobuf:"bytes,3,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rename) Reset() {
+	*x = Rename{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rename) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rename) ProtoMessage() {}
+
+func (x *Rename) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rename.ProtoReflect.Descriptor instead.
+func (*Rename) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Rename) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Rename) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *Rename) GetIndex() *BlindIndex {
+	if x != nil {
+		return x.Index
+	}
+	return nil
+}
+
+type MoveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromFolder    string                 `protobuf:"bytes,1,opt,name=from_folder,json=fromFolder,proto3" json:"from_folder,omitempty"`
+	Units         []*Rename              `protobuf:"bytes,2,rep,name=units,proto3" json:"units,omitempty"`
+	Folders       []*Rename              `protobuf:"bytes,3,rep,name=folders,proto3" json:"folders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveRequest) Reset() {
+	*x = MoveRequest{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveRequest) ProtoMessage() {}
+
+func (x *MoveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveRequest.ProtoReflect.Descriptor instead.
+func (*MoveRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *MoveRequest) GetFromFolder() string {
+	if x != nil {
+		return x.FromFolder
+	}
+	return ""
+}
+
+func (x *MoveRequest) GetUnits() []*Rename {
+	if x != nil {
+		return x.Units
+	}
+	return nil
+}
+
+func (x *MoveRequest) GetFolders() []*Rename {
+	if x != nil {
+		return x.Folders
+	}
+	return nil
+}
+
+type MkdirRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Folder        *NameRef               `protobuf:"bytes,1,opt,name=folder,proto3" json:"folder,omitempty"`
+	Parents       []*NameRef             `protobuf:"bytes,2,rep,name=parents,proto3" json:"parents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MkdirRequest) Reset() {
+	*x = MkdirRequest{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MkdirRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MkdirRequest) ProtoMessage() {}
+
+func (x *MkdirRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MkdirRequest.ProtoReflect.Descriptor instead.
+func (*MkdirRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *MkdirRequest) GetFolder() *NameRef {
+	if x != nil {
+		return x.Folder
+	}
+	return nil
+}
+
+func (x *MkdirRequest) GetParents() []*NameRef {
+	if x != nil {
+		return x.Parents
+	}
+	return nil
+}
+
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NameNgrams    []string               `protobuf:"bytes,1,rep,name=name_ngrams,json=nameNgrams,proto3" json:"name_ngrams,omitempty"`
+	Unittype      int32                  `protobuf:"varint,2,opt,name=unittype,proto3" json:"unittype,omitempty"`
+	TagTokens     []string               `protobuf:"bytes,3,rep,name=tag_tokens,json=tagTokens,proto3" json:"tag_tokens,omitempty"`
+	Fields        []*Field               `protobuf:"bytes,4,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SearchRequest) GetNameNgrams() []string {
+	if x != nil {
+		return x.NameNgrams
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetUnittype() int32 {
+	if x != nil {
+		return x.Unittype
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetTagTokens() []string {
+	if x != nil {
+		return x.TagTokens
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetFields() []*Field {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type UnitInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Unitname      string                 `protobuf:"bytes,1,opt,name=unitname,proto3" json:"unitname,omitempty"`
+	Unittype      int32                  `protobuf:"varint,2,opt,name=unittype,proto3" json:"unittype,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Tags          []string               `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	Fields        []*Field               `protobuf:"bytes,5,rep,name=fields,proto3" json:"fields,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	NameEnc       []byte                 `protobuf:"bytes,8,opt,name=name_enc,json=nameEnc,proto3" json:"name_enc,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	RotateEvery   *durationpb.Duration   `protobuf:"bytes,10,opt,name=rotate_every,json=rotateEvery,proto3" json:"rotate_every,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnitInfo) Reset() {
+	*x = UnitInfo{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnitInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnitInfo) ProtoMessage() {}
+
+func (x *UnitInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnitInfo.ProtoReflect.Descriptor instead.
+func (*UnitInfo) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UnitInfo) GetUnitname() string {
+	if x != nil {
+		return x.Unitname
+	}
+	return ""
+}
+
+func (x *UnitInfo) GetUnittype() int32 {
+	if x != nil {
+		return x.Unittype
+	}
+	return 0
+}
+
+func (x *UnitInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UnitInfo) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *UnitInfo) GetFields() []*Field {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *UnitInfo) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *UnitInfo) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *UnitInfo) GetNameEnc() []byte {
+	if x != nil {
+		return x.NameEnc
+	}
+	return nil
+}
+
+func (x *UnitInfo) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *UnitInfo) GetRotateEvery() *durationpb.Duration {
+	if x != nil {
+		return x.RotateEvery
+	}
+	return nil
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Units         []*UnitInfo            `protobuf:"bytes,1,rep,name=units,proto3" json:"units,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SearchResponse) GetUnits() []*UnitInfo {
+	if x != nil {
+		return x.Units
+	}
+	return nil
+}
+
+type DueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Within        *durationpb.Duration   `protobuf:"bytes,1,opt,name=within,proto3" json:"within,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DueRequest) Reset() {
+	*x = DueRequest{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DueRequest) ProtoMessage() {}
+
+func (x *DueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DueRequest.ProtoReflect.Descriptor instead.
+func (*DueRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DueRequest) GetWithin() *durationpb.Duration {
+	if x != nil {
+		return x.Within
+	}
+	return nil
+}
+
+type SyncRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SinceCursor   int64                  `protobuf:"varint,1,opt,name=since_cursor,json=sinceCursor,proto3" json:"since_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncRequest) Reset() {
+	*x = SyncRequest{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncRequest) ProtoMessage() {}
+
+func (x *SyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncRequest.ProtoReflect.Descriptor instead.
+func (*SyncRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SyncRequest) GetSinceCursor() int64 {
+	if x != nil {
+		return x.SinceCursor
+	}
+	return 0
+}
+
+type SyncUnit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Info          *UnitInfo              `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	Unitdata      []byte                 `protobuf:"bytes,2,opt,name=unitdata,proto3" json:"unitdata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncUnit) Reset() {
+	*x = SyncUnit{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncUnit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncUnit) ProtoMessage() {}
+
+func (x *SyncUnit) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -423,54 +1466,243 @@ func (x *WriteRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WriteRequest.ProtoReflect.Descriptor instead.
-func (*WriteRequest) Descriptor() ([]byte, []int) {
-	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use SyncUnit.ProtoReflect.Descriptor instead.
+func (*SyncUnit) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *WriteRequest) GetUnitname() string {
+func (x *SyncUnit) GetInfo() *UnitInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *SyncUnit) GetUnitdata() []byte {
+	if x != nil {
+		return x.Unitdata
+	}
+	return nil
+}
+
+type SyncResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cursor        int64                  `protobuf:"varint,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Full          bool                   `protobuf:"varint,2,opt,name=full,proto3" json:"full,omitempty"`
+	Units         []*SyncUnit            `protobuf:"bytes,3,rep,name=units,proto3" json:"units,omitempty"`
+	Deleted       []string               `protobuf:"bytes,4,rep,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncResponse) Reset() {
+	*x = SyncResponse{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncResponse) ProtoMessage() {}
+
+func (x *SyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncResponse.ProtoReflect.Descriptor instead.
+func (*SyncResponse) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SyncResponse) GetCursor() int64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+func (x *SyncResponse) GetFull() bool {
+	if x != nil {
+		return x.Full
+	}
+	return false
+}
+
+func (x *SyncResponse) GetUnits() []*SyncUnit {
+	if x != nil {
+		return x.Units
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetDeleted() []string {
+	if x != nil {
+		return x.Deleted
+	}
+	return nil
+}
+
+type WatchEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          WatchEvent_Kind        `protobuf:"varint,1,opt,name=kind,proto3,enum=gophkeeper.WatchEvent_Kind" json:"kind,omitempty"`
+	Unitname      string                 `protobuf:"bytes,2,opt,name=unitname,proto3" json:"unitname,omitempty"`
+	NameEnc       []byte                 `protobuf:"bytes,3,opt,name=name_enc,json=nameEnc,proto3" json:"name_enc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEvent) Reset() {
+	*x = WatchEvent{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEvent) ProtoMessage() {}
+
+func (x *WatchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEvent.ProtoReflect.Descriptor instead.
+func (*WatchEvent) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *WatchEvent) GetKind() WatchEvent_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return WatchEvent_SUBSCRIBED
+}
+
+func (x *WatchEvent) GetUnitname() string {
 	if x != nil {
 		return x.Unitname
 	}
 	return ""
 }
 
-func (x *WriteRequest) GetUnittype() int32 {
+func (x *WatchEvent) GetNameEnc() []byte {
+	if x != nil {
+		return x.NameEnc
+	}
+	return nil
+}
+
+type TrashItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          *NameRef               `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Unittype      int32                  `protobuf:"varint,3,opt,name=unittype,proto3" json:"unittype,omitempty"`
+	DeletedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrashItem) Reset() {
+	*x = TrashItem{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrashItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrashItem) ProtoMessage() {}
+
+func (x *TrashItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrashItem.ProtoReflect.Descriptor instead.
+func (*TrashItem) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *TrashItem) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TrashItem) GetName() *NameRef {
+	if x != nil {
+		return x.Name
+	}
+	return nil
+}
+
+func (x *TrashItem) GetUnittype() int32 {
 	if x != nil {
 		return x.Unittype
 	}
 	return 0
 }
 
-func (x *WriteRequest) GetUnitdata() []byte {
+func (x *TrashItem) GetDeletedAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Unitdata
+		return x.DeletedAt
 	}
 	return nil
 }
 
-type DeleteRequest struct {
+type ListTrashResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Unitname      string                 `protobuf:"bytes,1,opt,name=unitname,proto3" json:"unitname,omitempty"`
+	Items         []*TrashItem           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteRequest) Reset() {
-	*x = DeleteRequest{}
-	mi := &file_proto_gophkeeper_proto_msgTypes[9]
+func (x *ListTrashResponse) Reset() {
+	*x = ListTrashResponse{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteRequest) String() string {
+func (x *ListTrashResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteRequest) ProtoMessage() {}
+func (*ListTrashResponse) ProtoMessage() {}
 
-func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gophkeeper_proto_msgTypes[9]
+func (x *ListTrashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -481,16 +1713,148 @@ func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
-func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use ListTrashResponse.ProtoReflect.Descriptor instead.
+func (*ListTrashResponse) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *DeleteRequest) GetUnitname() string {
+func (x *ListTrashResponse) GetItems() []*TrashItem {
 	if x != nil {
-		return x.Unitname
+		return x.Items
 	}
-	return ""
+	return nil
+}
+
+type RestoreRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreRequest) Reset() {
+	*x = RestoreRequest{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRequest) ProtoMessage() {}
+
+func (x *RestoreRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RestoreRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type PurgeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []int64                `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeRequest) Reset() {
+	*x = PurgeRequest{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeRequest) ProtoMessage() {}
+
+func (x *PurgeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeRequest.ProtoReflect.Descriptor instead.
+func (*PurgeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *PurgeRequest) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type PurgeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Purged        int64                  `protobuf:"varint,1,opt,name=purged,proto3" json:"purged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeResponse) Reset() {
+	*x = PurgeResponse{}
+	mi := &file_proto_gophkeeper_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeResponse) ProtoMessage() {}
+
+func (x *PurgeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gophkeeper_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeResponse.ProtoReflect.Descriptor instead.
+func (*PurgeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_gophkeeper_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *PurgeResponse) GetPurged() int64 {
+	if x != nil {
+		return x.Purged
+	}
+	return 0
 }
 
 var File_proto_gophkeeper_proto protoreflect.FileDescriptor
@@ -498,8 +1862,23 @@ var File_proto_gophkeeper_proto protoreflect.FileDescriptor
 const file_proto_gophkeeper_proto_rawDesc = "" +
 	"\n" +
 	"\x16proto/gophkeeper.proto\x12\n" +
-	"gophkeeper\"\a\n" +
-	"\x05Empty\"C\n" +
+	"gophkeeper\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\"\a\n" +
+	"\x05Empty\"M\n" +
+	"\x05Field\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x1c\n" +
+	"\tsensitive\x18\x03 \x01(\bR\tsensitive\"1\n" +
+	"\aNameRef\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x10\n" +
+	"\x03enc\x18\x02 \x01(\fR\x03enc\"\x96\x01\n" +
+	"\n" +
+	"BlindIndex\x12\x19\n" +
+	"\bname_enc\x18\x01 \x01(\fR\anameEnc\x12\x1f\n" +
+	"\vname_ngrams\x18\x02 \x03(\tR\n" +
+	"nameNgrams\x12-\n" +
+	"\afolders\x18\x03 \x03(\v2\x13.gophkeeper.NameRefR\afolders\x12\x1d\n" +
+	"\n" +
+	"tag_tokens\x18\x04 \x03(\tR\ttagTokens\"C\n" +
 	"\x0fRegisterRequest\x12\x14\n" +
 	"\x05login\x18\x01 \x01(\tR\x05login\x12\x1a\n" +
 	"\bpassword\x18\x02 \x01(\tR\bpassword\"(\n" +
@@ -509,28 +1888,138 @@ const file_proto_gophkeeper_proto_rawDesc = "" +
 	"\x05login\x18\x01 \x01(\tR\x05login\x12\x1a\n" +
 	"\bpassword\x18\x02 \x01(\tR\bpassword\",\n" +
 	"\x14AuthenticateResponse\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\"*\n" +
-	"\fListResponse\x12\x1a\n" +
-	"\bunitname\x18\x01 \x03(\tR\bunitname\")\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"C\n" +
+	"\vListRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x1c\n" +
+	"\trecursive\x18\x02 \x01(\bR\trecursive\"h\n" +
+	"\fListResponse\x12)\n" +
+	"\x05units\x18\x01 \x03(\v2\x13.gophkeeper.NameRefR\x05units\x12-\n" +
+	"\afolders\x18\x02 \x03(\v2\x13.gophkeeper.NameRefR\afolders\")\n" +
 	"\vReadRequest\x12\x1a\n" +
-	"\bunitname\x18\x01 \x01(\tR\bunitname\"F\n" +
+	"\bunitname\x18\x01 \x01(\tR\bunitname\"\x96\x03\n" +
 	"\fReadResponse\x12\x1a\n" +
 	"\bunittype\x18\x01 \x01(\x05R\bunittype\x12\x1a\n" +
-	"\bunitdata\x18\x02 \x01(\fR\bunitdata\"b\n" +
+	"\bunitdata\x18\x02 \x01(\fR\bunitdata\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04tags\x18\x04 \x03(\tR\x04tags\x12)\n" +
+	"\x06fields\x18\x05 \x03(\v2\x11.gophkeeper.FieldR\x06fields\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12<\n" +
+	"\frotate_every\x18\t \x01(\v2\x19.google.protobuf.DurationR\vrotateEvery\"\xea\x02\n" +
 	"\fWriteRequest\x12\x1a\n" +
 	"\bunitname\x18\x01 \x01(\tR\bunitname\x12\x1a\n" +
 	"\bunittype\x18\x02 \x01(\x05R\bunittype\x12\x1a\n" +
-	"\bunitdata\x18\x03 \x01(\fR\bunitdata\"+\n" +
+	"\bunitdata\x18\x03 \x01(\fR\bunitdata\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12)\n" +
+	"\x06fields\x18\x06 \x03(\v2\x11.gophkeeper.FieldR\x06fields\x12,\n" +
+	"\x05index\x18\a \x01(\v2\x16.gophkeeper.BlindIndexR\x05index\x129\n" +
+	"\n" +
+	"expires_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12<\n" +
+	"\frotate_every\x18\t \x01(\v2\x19.google.protobuf.DurationR\vrotateEvery\"\x85\x01\n" +
+	"\rWriteResponse\x129\n" +
+	"\n" +
+	"created_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"+\n" +
 	"\rDeleteRequest\x12\x1a\n" +
-	"\bunitname\x18\x01 \x01(\tR\bunitname2\x84\x03\n" +
+	"\bunitname\x18\x01 \x01(\tR\bunitname\"Z\n" +
+	"\x06Rename\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12,\n" +
+	"\x05index\x18\x03 \x01(\v2\x16.gophkeeper.BlindIndexR\x05index\"\x86\x01\n" +
+	"\vMoveRequest\x12\x1f\n" +
+	"\vfrom_folder\x18\x01 \x01(\tR\n" +
+	"fromFolder\x12(\n" +
+	"\x05units\x18\x02 \x03(\v2\x12.gophkeeper.RenameR\x05units\x12,\n" +
+	"\afolders\x18\x03 \x03(\v2\x12.gophkeeper.RenameR\afolders\"j\n" +
+	"\fMkdirRequest\x12+\n" +
+	"\x06folder\x18\x01 \x01(\v2\x13.gophkeeper.NameRefR\x06folder\x12-\n" +
+	"\aparents\x18\x02 \x03(\v2\x13.gophkeeper.NameRefR\aparents\"\x96\x01\n" +
+	"\rSearchRequest\x12\x1f\n" +
+	"\vname_ngrams\x18\x01 \x03(\tR\n" +
+	"nameNgrams\x12\x1a\n" +
+	"\bunittype\x18\x02 \x01(\x05R\bunittype\x12\x1d\n" +
+	"\n" +
+	"tag_tokens\x18\x03 \x03(\tR\ttagTokens\x12)\n" +
+	"\x06fields\x18\x04 \x03(\v2\x11.gophkeeper.FieldR\x06fields\"\xad\x03\n" +
+	"\bUnitInfo\x12\x1a\n" +
+	"\bunitname\x18\x01 \x01(\tR\bunitname\x12\x1a\n" +
+	"\bunittype\x18\x02 \x01(\x05R\bunittype\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04tags\x18\x04 \x03(\tR\x04tags\x12)\n" +
+	"\x06fields\x18\x05 \x03(\v2\x11.gophkeeper.FieldR\x06fields\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x19\n" +
+	"\bname_enc\x18\b \x01(\fR\anameEnc\x129\n" +
+	"\n" +
+	"expires_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12<\n" +
+	"\frotate_every\x18\n" +
+	" \x01(\v2\x19.google.protobuf.DurationR\vrotateEvery\"<\n" +
+	"\x0eSearchResponse\x12*\n" +
+	"\x05units\x18\x01 \x03(\v2\x14.gophkeeper.UnitInfoR\x05units\"?\n" +
+	"\n" +
+	"DueRequest\x121\n" +
+	"\x06within\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\x06within\"0\n" +
+	"\vSyncRequest\x12!\n" +
+	"\fsince_cursor\x18\x01 \x01(\x03R\vsinceCursor\"P\n" +
+	"\bSyncUnit\x12(\n" +
+	"\x04info\x18\x01 \x01(\v2\x14.gophkeeper.UnitInfoR\x04info\x12\x1a\n" +
+	"\bunitdata\x18\x02 \x01(\fR\bunitdata\"\x80\x01\n" +
+	"\fSyncResponse\x12\x16\n" +
+	"\x06cursor\x18\x01 \x01(\x03R\x06cursor\x12\x12\n" +
+	"\x04full\x18\x02 \x01(\bR\x04full\x12*\n" +
+	"\x05units\x18\x03 \x03(\v2\x14.gophkeeper.SyncUnitR\x05units\x12\x18\n" +
+	"\adeleted\x18\x04 \x03(\tR\adeleted\"\xb3\x01\n" +
+	"\n" +
+	"WatchEvent\x12/\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1b.gophkeeper.WatchEvent.KindR\x04kind\x12\x1a\n" +
+	"\bunitname\x18\x02 \x01(\tR\bunitname\x12\x19\n" +
+	"\bname_enc\x18\x03 \x01(\fR\anameEnc\"=\n" +
+	"\x04Kind\x12\x0e\n" +
+	"\n" +
+	"SUBSCRIBED\x10\x00\x12\v\n" +
+	"\aCREATED\x10\x01\x12\v\n" +
+	"\aUPDATED\x10\x02\x12\v\n" +
+	"\aDELETED\x10\x03\"\x9b\x01\n" +
+	"\tTrashItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12'\n" +
+	"\x04name\x18\x02 \x01(\v2\x13.gophkeeper.NameRefR\x04name\x12\x1a\n" +
+	"\bunittype\x18\x03 \x01(\x05R\bunittype\x129\n" +
+	"\n" +
+	"deleted_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\"@\n" +
+	"\x11ListTrashResponse\x12+\n" +
+	"\x05items\x18\x01 \x03(\v2\x15.gophkeeper.TrashItemR\x05items\" \n" +
+	"\x0eRestoreRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\" \n" +
+	"\fPurgeRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x03R\x03ids\"'\n" +
+	"\rPurgeResponse\x12\x16\n" +
+	"\x06purged\x18\x01 \x01(\x03R\x06purged2\xd6\a\n" +
 	"\n" +
 	"Gophkeeper\x12E\n" +
 	"\bRegister\x12\x1b.gophkeeper.RegisterRequest\x1a\x1c.gophkeeper.RegisterResponse\x12Q\n" +
-	"\fAuthenticate\x12\x1f.gophkeeper.AuthenticateRequest\x1a .gophkeeper.AuthenticateResponse\x123\n" +
-	"\x04List\x12\x11.gophkeeper.Empty\x1a\x18.gophkeeper.ListResponse\x129\n" +
-	"\x04Read\x12\x17.gophkeeper.ReadRequest\x1a\x18.gophkeeper.ReadResponse\x124\n" +
-	"\x05Write\x12\x18.gophkeeper.WriteRequest\x1a\x11.gophkeeper.Empty\x126\n" +
-	"\x06Delete\x12\x19.gophkeeper.DeleteRequest\x1a\x11.gophkeeper.EmptyB1Z/github.com/iurnickita/gophkeeper/contract/protob\x06proto3"
+	"\fAuthenticate\x12\x1f.gophkeeper.AuthenticateRequest\x1a .gophkeeper.AuthenticateResponse\x129\n" +
+	"\x04List\x12\x17.gophkeeper.ListRequest\x1a\x18.gophkeeper.ListResponse\x129\n" +
+	"\x04Read\x12\x17.gophkeeper.ReadRequest\x1a\x18.gophkeeper.ReadResponse\x12<\n" +
+	"\x05Write\x12\x18.gophkeeper.WriteRequest\x1a\x19.gophkeeper.WriteResponse\x126\n" +
+	"\x06Delete\x12\x19.gophkeeper.DeleteRequest\x1a\x11.gophkeeper.Empty\x122\n" +
+	"\x04Move\x12\x17.gophkeeper.MoveRequest\x1a\x11.gophkeeper.Empty\x124\n" +
+	"\x06Rename\x12\x17.gophkeeper.MoveRequest\x1a\x11.gophkeeper.Empty\x124\n" +
+	"\x05Mkdir\x12\x18.gophkeeper.MkdirRequest\x1a\x11.gophkeeper.Empty\x12?\n" +
+	"\x06Search\x12\x19.gophkeeper.SearchRequest\x1a\x1a.gophkeeper.SearchResponse\x129\n" +
+	"\x03Due\x12\x16.gophkeeper.DueRequest\x1a\x1a.gophkeeper.SearchResponse\x129\n" +
+	"\x04Sync\x12\x17.gophkeeper.SyncRequest\x1a\x18.gophkeeper.SyncResponse\x124\n" +
+	"\x05Watch\x12\x11.gophkeeper.Empty\x1a\x16.gophkeeper.WatchEvent0\x01\x12=\n" +
+	"\tListTrash\x12\x11.gophkeeper.Empty\x1a\x1d.gophkeeper.ListTrashResponse\x128\n" +
+	"\aRestore\x12\x1a.gophkeeper.RestoreRequest\x1a\x11.gophkeeper.Empty\x12<\n" +
+	"\x05Purge\x12\x18.gophkeeper.PurgeRequest\x1a\x19.gophkeeper.PurgeResponseB1Z/github.com/iurnickita/gophkeeper/contract/protob\x06proto3"
 
 var (
 	file_proto_gophkeeper_proto_rawDescOnce sync.Once
@@ -544,37 +2033,115 @@ func file_proto_gophkeeper_proto_rawDescGZIP() []byte {
 	return file_proto_gophkeeper_proto_rawDescData
 }
 
-var file_proto_gophkeeper_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_proto_gophkeeper_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_gophkeeper_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
 var file_proto_gophkeeper_proto_goTypes = []any{
-	(*Empty)(nil),                // 0: gophkeeper.Empty
-	(*RegisterRequest)(nil),      // 1: gophkeeper.RegisterRequest
-	(*RegisterResponse)(nil),     // 2: gophkeeper.RegisterResponse
-	(*AuthenticateRequest)(nil),  // 3: gophkeeper.AuthenticateRequest
-	(*AuthenticateResponse)(nil), // 4: gophkeeper.AuthenticateResponse
-	(*ListResponse)(nil),         // 5: gophkeeper.ListResponse
-	(*ReadRequest)(nil),          // 6: gophkeeper.ReadRequest
-	(*ReadResponse)(nil),         // 7: gophkeeper.ReadResponse
-	(*WriteRequest)(nil),         // 8: gophkeeper.WriteRequest
-	(*DeleteRequest)(nil),        // 9: gophkeeper.DeleteRequest
+	(WatchEvent_Kind)(0),          // 0: gophkeeper.WatchEvent.Kind
+	(*Empty)(nil),                 // 1: gophkeeper.Empty
+	(*Field)(nil),                 // 2: gophkeeper.Field
+	(*NameRef)(nil),               // 3: gophkeeper.NameRef
+	(*BlindIndex)(nil),            // 4: gophkeeper.BlindIndex
+	(*RegisterRequest)(nil),       // 5: gophkeeper.RegisterRequest
+	(*RegisterResponse)(nil),      // 6: gophkeeper.RegisterResponse
+	(*AuthenticateRequest)(nil),   // 7: gophkeeper.AuthenticateRequest
+	(*AuthenticateResponse)(nil),  // 8: gophkeeper.AuthenticateResponse
+	(*ListRequest)(nil),           // 9: gophkeeper.ListRequest
+	(*ListResponse)(nil),          // 10: gophkeeper.ListResponse
+	(*ReadRequest)(nil),           // 11: gophkeeper.ReadRequest
+	(*ReadResponse)(nil),          // 12: gophkeeper.ReadResponse
+	(*WriteRequest)(nil),          // 13: gophkeeper.WriteRequest
+	(*WriteResponse)(nil),         // 14: gophkeeper.WriteResponse
+	(*DeleteRequest)(nil),         // 15: gophkeeper.DeleteRequest
+	(*Rename)(nil),                // 16: gophkeeper.Rename
+	(*MoveRequest)(nil),           // 17: gophkeeper.MoveRequest
+	(*MkdirRequest)(nil),          // 18: gophkeeper.MkdirRequest
+	(*SearchRequest)(nil),         // 19: gophkeeper.SearchRequest
+	(*UnitInfo)(nil),              // 20: gophkeeper.UnitInfo
+	(*SearchResponse)(nil),        // 21: gophkeeper.SearchResponse
+	(*DueRequest)(nil),            // 22: gophkeeper.DueRequest
+	(*SyncRequest)(nil),           // 23: gophkeeper.SyncRequest
+	(*SyncUnit)(nil),              // 24: gophkeeper.SyncUnit
+	(*SyncResponse)(nil),          // 25: gophkeeper.SyncResponse
+	(*WatchEvent)(nil),            // 26: gophkeeper.WatchEvent
+	(*TrashItem)(nil),             // 27: gophkeeper.TrashItem
+	(*ListTrashResponse)(nil),     // 28: gophkeeper.ListTrashResponse
+	(*RestoreRequest)(nil),        // 29: gophkeeper.RestoreRequest
+	(*PurgeRequest)(nil),          // 30: gophkeeper.PurgeRequest
+	(*PurgeResponse)(nil),         // 31: gophkeeper.PurgeResponse
+	(*timestamppb.Timestamp)(nil), // 32: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 33: google.protobuf.Duration
 }
 var file_proto_gophkeeper_proto_depIdxs = []int32{
-	1, // 0: gophkeeper.Gophkeeper.Register:input_type -> gophkeeper.RegisterRequest
-	3, // 1: gophkeeper.Gophkeeper.Authenticate:input_type -> gophkeeper.AuthenticateRequest
-	0, // 2: gophkeeper.Gophkeeper.List:input_type -> gophkeeper.Empty
-	6, // 3: gophkeeper.Gophkeeper.Read:input_type -> gophkeeper.ReadRequest
-	8, // 4: gophkeeper.Gophkeeper.Write:input_type -> gophkeeper.WriteRequest
-	9, // 5: gophkeeper.Gophkeeper.Delete:input_type -> gophkeeper.DeleteRequest
-	2, // 6: gophkeeper.Gophkeeper.Register:output_type -> gophkeeper.RegisterResponse
-	4, // 7: gophkeeper.Gophkeeper.Authenticate:output_type -> gophkeeper.AuthenticateResponse
-	5, // 8: gophkeeper.Gophkeeper.List:output_type -> gophkeeper.ListResponse
-	7, // 9: gophkeeper.Gophkeeper.Read:output_type -> gophkeeper.ReadResponse
-	0, // 10: gophkeeper.Gophkeeper.Write:output_type -> gophkeeper.Empty
-	0, // 11: gophkeeper.Gophkeeper.Delete:output_type -> gophkeeper.Empty
-	6, // [6:12] is the sub-list for method output_type
-	0, // [0:6] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	3,  // 0: gophkeeper.BlindIndex.folders:type_name -> gophkeeper.NameRef
+	3,  // 1: gophkeeper.ListResponse.units:type_name -> gophkeeper.NameRef
+	3,  // 2: gophkeeper.ListResponse.folders:type_name -> gophkeeper.NameRef
+	2,  // 3: gophkeeper.ReadResponse.fields:type_name -> gophkeeper.Field
+	32, // 4: gophkeeper.ReadResponse.created_at:type_name -> google.protobuf.Timestamp
+	32, // 5: gophkeeper.ReadResponse.updated_at:type_name -> google.protobuf.Timestamp
+	32, // 6: gophkeeper.ReadResponse.expires_at:type_name -> google.protobuf.Timestamp
+	33, // 7: gophkeeper.ReadResponse.rotate_every:type_name -> google.protobuf.Duration
+	2,  // 8: gophkeeper.WriteRequest.fields:type_name -> gophkeeper.Field
+	4,  // 9: gophkeeper.WriteRequest.index:type_name -> gophkeeper.BlindIndex
+	32, // 10: gophkeeper.WriteRequest.expires_at:type_name -> google.protobuf.Timestamp
+	33, // 11: gophkeeper.WriteRequest.rotate_every:type_name -> google.protobuf.Duration
+	32, // 12: gophkeeper.WriteResponse.created_at:type_name -> google.protobuf.Timestamp
+	32, // 13: gophkeeper.WriteResponse.updated_at:type_name -> google.protobuf.Timestamp
+	4,  // 14: gophkeeper.Rename.index:type_name -> gophkeeper.BlindIndex
+	16, // 15: gophkeeper.MoveRequest.units:type_name -> gophkeeper.Rename
+	16, // 16: gophkeeper.MoveRequest.folders:type_name -> gophkeeper.Rename
+	3,  // 17: gophkeeper.MkdirRequest.folder:type_name -> gophkeeper.NameRef
+	3,  // 18: gophkeeper.MkdirRequest.parents:type_name -> gophkeeper.NameRef
+	2,  // 19: gophkeeper.SearchRequest.fields:type_name -> gophkeeper.Field
+	2,  // 20: gophkeeper.UnitInfo.fields:type_name -> gophkeeper.Field
+	32, // 21: gophkeeper.UnitInfo.created_at:type_name -> google.protobuf.Timestamp
+	32, // 22: gophkeeper.UnitInfo.updated_at:type_name -> google.protobuf.Timestamp
+	32, // 23: gophkeeper.UnitInfo.expires_at:type_name -> google.protobuf.Timestamp
+	33, // 24: gophkeeper.UnitInfo.rotate_every:type_name -> google.protobuf.Duration
+	20, // 25: gophkeeper.SearchResponse.units:type_name -> gophkeeper.UnitInfo
+	33, // 26: gophkeeper.DueRequest.within:type_name -> google.protobuf.Duration
+	20, // 27: gophkeeper.SyncUnit.info:type_name -> gophkeeper.UnitInfo
+	24, // 28: gophkeeper.SyncResponse.units:type_name -> gophkeeper.SyncUnit
+	0,  // 29: gophkeeper.WatchEvent.kind:type_name -> gophkeeper.WatchEvent.Kind
+	3,  // 30: gophkeeper.TrashItem.name:type_name -> gophkeeper.NameRef
+	32, // 31: gophkeeper.TrashItem.deleted_at:type_name -> google.protobuf.Timestamp
+	27, // 32: gophkeeper.ListTrashResponse.items:type_name -> gophkeeper.TrashItem
+	5,  // 33: gophkeeper.Gophkeeper.Register:input_type -> gophkeeper.RegisterRequest
+	7,  // 34: gophkeeper.Gophkeeper.Authenticate:input_type -> gophkeeper.AuthenticateRequest
+	9,  // 35: gophkeeper.Gophkeeper.List:input_type -> gophkeeper.ListRequest
+	11, // 36: gophkeeper.Gophkeeper.Read:input_type -> gophkeeper.ReadRequest
+	13, // 37: gophkeeper.Gophkeeper.Write:input_type -> gophkeeper.WriteRequest
+	15, // 38: gophkeeper.Gophkeeper.Delete:input_type -> gophkeeper.DeleteRequest
+	17, // 39: gophkeeper.Gophkeeper.Move:input_type -> gophkeeper.MoveRequest
+	17, // 40: gophkeeper.Gophkeeper.Rename:input_type -> gophkeeper.MoveRequest
+	18, // 41: gophkeeper.Gophkeeper.Mkdir:input_type -> gophkeeper.MkdirRequest
+	19, // 42: gophkeeper.Gophkeeper.Search:input_type -> gophkeeper.SearchRequest
+	22, // 43: gophkeeper.Gophkeeper.Due:input_type -> gophkeeper.DueRequest
+	23, // 44: gophkeeper.Gophkeeper.Sync:input_type -> gophkeeper.SyncRequest
+	1,  // 45: gophkeeper.Gophkeeper.Watch:input_type -> gophkeeper.Empty
+	1,  // 46: gophkeeper.Gophkeeper.ListTrash:input_type -> gophkeeper.Empty
+	29, // 47: gophkeeper.Gophkeeper.Restore:input_type -> gophkeeper.RestoreRequest
+	30, // 48: gophkeeper.Gophkeeper.Purge:input_type -> gophkeeper.PurgeRequest
+	6,  // 49: gophkeeper.Gophkeeper.Register:output_type -> gophkeeper.RegisterResponse
+	8,  // 50: gophkeeper.Gophkeeper.Authenticate:output_type -> gophkeeper.AuthenticateResponse
+	10, // 51: gophkeeper.Gophkeeper.List:output_type -> gophkeeper.ListResponse
+	12, // 52: gophkeeper.Gophkeeper.Read:output_type -> gophkeeper.ReadResponse
+	14, // 53: gophkeeper.Gophkeeper.Write:output_type -> gophkeeper.WriteResponse
+	1,  // 54: gophkeeper.Gophkeeper.Delete:output_type -> gophkeeper.Empty
+	1,  // 55: gophkeeper.Gophkeeper.Move:output_type -> gophkeeper.Empty
+	1,  // 56: gophkeeper.Gophkeeper.Rename:output_type -> gophkeeper.Empty
+	1,  // 57: gophkeeper.Gophkeeper.Mkdir:output_type -> gophkeeper.Empty
+	21, // 58: gophkeeper.Gophkeeper.Search:output_type -> gophkeeper.SearchResponse
+	21, // 59: gophkeeper.Gophkeeper.Due:output_type -> gophkeeper.SearchResponse
+	25, // 60: gophkeeper.Gophkeeper.Sync:output_type -> gophkeeper.SyncResponse
+	26, // 61: gophkeeper.Gophkeeper.Watch:output_type -> gophkeeper.WatchEvent
+	28, // 62: gophkeeper.Gophkeeper.ListTrash:output_type -> gophkeeper.ListTrashResponse
+	1,  // 63: gophkeeper.Gophkeeper.Restore:output_type -> gophkeeper.Empty
+	31, // 64: gophkeeper.Gophkeeper.Purge:output_type -> gophkeeper.PurgeResponse
+	49, // [49:65] is the sub-list for method output_type
+	33, // [33:49] is the sub-list for method input_type
+	33, // [33:33] is the sub-list for extension type_name
+	33, // [33:33] is the sub-list for extension extendee
+	0,  // [0:33] is the sub-list for field type_name
 }
 
 func init() { file_proto_gophkeeper_proto_init() }
@@ -587,13 +2154,14 @@ func file_proto_gophkeeper_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_gophkeeper_proto_rawDesc), len(file_proto_gophkeeper_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   10,
+			NumEnums:      1,
+			NumMessages:   31,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_gophkeeper_proto_goTypes,
 		DependencyIndexes: file_proto_gophkeeper_proto_depIdxs,
+		EnumInfos:         file_proto_gophkeeper_proto_enumTypes,
 		MessageInfos:      file_proto_gophkeeper_proto_msgTypes,
 	}.Build()
 	File_proto_gophkeeper_proto = out.File
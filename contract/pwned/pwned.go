@@ -0,0 +1,193 @@
+// Пакет pwned. Проверка паролей по локальной копии Pwned Passwords (SHA-1) без обращения к внешним сервисам.
+// Поддерживаются файл, упорядоченный по хешу (строки HASH:COUNT), с двоичным поиском,
+// и каталог файлов диапазонов по первым 5 символам хеша (PREFIX.txt со строками SUFFIX:COUNT).
+// Отсутствующий файл диапазона означает, что паролей с этим префиксом в копии нет
+package pwned
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// prefixLen - длина префикса хеша в именах файлов диапазонов
+const prefixLen = 5
+
+var (
+	ErrInvalidHash = errors.New("invalid SHA-1 hash")
+	ErrInvalidLine = errors.New("invalid pwned passwords line")
+	ErrNoRanges    = errors.New("no pwned passwords range files in directory")
+)
+
+// Store - локальная копия Pwned Passwords
+type Store struct {
+	// Файл, упорядоченный по хешу
+	file *os.File
+	size int64
+	// Каталог файлов диапазонов
+	dir string
+}
+
+// Open открывает файл или каталог диапазонов.
+// Каталог проверяется при открытии: он должен читаться и содержать файлы диапазонов
+func Open(path string) (*Store, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		ranges, err := filepath.Glob(filepath.Join(path, "*.txt"))
+		if err != nil {
+			return nil, err
+		}
+		if len(ranges) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNoRanges, path)
+		}
+		// Права на чтение
+		dir, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer dir.Close()
+		if _, err := dir.Readdirnames(1); err != nil {
+			return nil, err
+		}
+		return &Store{dir: path}, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{file: file, size: info.Size()}, nil
+}
+
+// Hash возвращает SHA-1 пароля в формате Pwned Passwords
+func Hash(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// Count возвращает количество утечек, в которых встречается пароль. 0 - не встречается
+func (s *Store) Count(password string) (int, error) {
+	return s.CountHash(Hash(password))
+}
+
+// CountHash возвращает количество утечек по SHA-1 пароля
+func (s *Store) CountHash(hash string) (int, error) {
+	hash = strings.ToUpper(hash)
+	if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha1.Size*2 {
+		return 0, ErrInvalidHash
+	}
+	if s.dir != "" {
+		return s.countRange(hash)
+	}
+	return s.search(hash)
+}
+
+// Close
+func (s *Store) Close() error {
+	if s.file != nil {
+		return s.file.Close()
+	}
+	return nil
+}
+
+// countRange ищет суффикс хеша в файле диапазона
+func (s *Store) countRange(hash string) (int, error) {
+	file, err := os.Open(filepath.Join(s.dir, hash[:prefixLen]+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		suffix, count, err := parseLine(scanner.Text())
+		if err != nil {
+			return 0, err
+		}
+		if strings.EqualFold(suffix, hash[prefixLen:]) {
+			return count, nil
+		}
+	}
+	return 0, scanner.Err()
+}
+
+// search - двоичный поиск строки хеша по смещениям в файле.
+// Область поиска - строки, начинающиеся в [lo, hi)
+func (s *Store) search(hash string) (int, error) {
+	lo, hi := int64(0), s.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, line, err := s.lineAt(mid)
+		if err != nil {
+			return 0, err
+		}
+		if start >= hi || line == "" {
+			// В [mid, hi) строки не начинаются
+			hi = mid
+			continue
+		}
+		lineHash, count, err := parseLine(line)
+		if err != nil {
+			return 0, err
+		}
+		switch cmp := strings.Compare(strings.ToUpper(lineHash), hash); {
+		case cmp == 0:
+			return count, nil
+		case cmp < 0:
+			lo = start + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0, nil
+}
+
+// lineAt возвращает первую строку, начинающуюся не раньше offset, и ее смещение
+func (s *Store) lineAt(offset int64) (int64, string, error) {
+	start := offset
+	if offset > 0 {
+		start--
+	}
+	reader := bufio.NewReader(io.NewSectionReader(s.file, start, s.size-start))
+	if offset > 0 {
+		// Остаток предыдущей строки
+		skipped, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return s.size, "", nil
+		}
+		if err != nil {
+			return 0, "", err
+		}
+		start += int64(len(skipped))
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, "", err
+	}
+	return start, strings.TrimRight(line, "\r\n"), nil
+}
+
+// parseLine разбирает строку HASH:COUNT
+func parseLine(line string) (string, int, error) {
+	hash, count, found := strings.Cut(strings.TrimSpace(line), ":")
+	if !found {
+		return "", 0, fmt.Errorf("%w: %q", ErrInvalidLine, line)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %q", ErrInvalidLine, line)
+	}
+	return hash, n, nil
+}
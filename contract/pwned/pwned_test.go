@@ -0,0 +1,69 @@
+package pwned
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_File(t *testing.T) {
+	var lines []string
+	counts := make(map[string]int)
+	for i := range 500 {
+		hash := Hash(fmt.Sprintf("password%d", i))
+		counts[hash] = i + 1
+		lines = append(lines, fmt.Sprintf("%s:%d", hash, i+1))
+	}
+	slices.Sort(lines)
+	file := filepath.Join(t.TempDir(), "pwned.txt")
+	require.NoError(t, os.WriteFile(file, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0600))
+
+	store, err := Open(file)
+	require.NoError(t, err)
+	defer store.Close()
+
+	for hash, count := range counts {
+		got, err := store.CountHash(hash)
+		require.NoError(t, err)
+		require.Equal(t, count, got, hash)
+	}
+	for i := range 100 {
+		got, err := store.Count(fmt.Sprintf("missing%d", i))
+		require.NoError(t, err)
+		require.Zero(t, got)
+	}
+
+	_, err = store.CountHash("XYZ")
+	require.ErrorIs(t, err, ErrInvalidHash)
+}
+
+func TestStore_Dir(t *testing.T) {
+	dir := t.TempDir()
+	hash := Hash("hunter2")
+	data := fmt.Sprintf("%s:3\n%s:42\n", strings.Repeat("0", 35), hash[prefixLen:])
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hash[:prefixLen]+".txt"), []byte(data), 0600))
+
+	store, err := Open(dir)
+	require.NoError(t, err)
+	defer store.Close()
+
+	count, err := store.Count("hunter2")
+	require.NoError(t, err)
+	require.Equal(t, 42, count)
+
+	// Нет файла диапазона - пароль не встречается
+	count, err = store.Count("password")
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	// Каталог без файлов диапазонов - ошибка при открытии
+	_, err = Open(t.TempDir())
+	require.ErrorIs(t, err, ErrNoRanges)
+	_, err = Open(filepath.Join(dir, "missing"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,261 @@
+// Пакет settings. Многоуровневая конфигурация клиента и сервера:
+// значения по умолчанию, файл YAML, переменные окружения и флаги
+// в порядке возрастания приоритета
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// Источники значений
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// redacted - отображение секретного значения
+const redacted = "***"
+
+var (
+	ErrRequired       = errors.New("required setting is not set")
+	ErrUnknownSetting = errors.New("unknown setting")
+)
+
+// Setting - параметр конфигурации
+type Setting struct {
+	// Ключ в файле: раздел.параметр
+	Key string
+	// Переменная окружения. Пустая - не читается
+	Env string
+	// Флаг и его краткая форма. Пустой - не регистрируется (секреты не передаются флагами)
+	Flag  string
+	Short string
+	Usage string
+	// Значение скрывается при выводе
+	Secret bool
+	// Значение обязательно
+	Required bool
+	// Поле конфигурации. Значение поля на момент регистрации - значение по умолчанию
+	Value pflag.Value
+}
+
+// Set - набор параметров конфигурации
+type Set struct {
+	settings []Setting
+	flags    *pflag.FlagSet
+	sources  map[string]string
+	file     string
+}
+
+// New регистрирует флаги параметров
+func New(flags *pflag.FlagSet, settings ...Setting) *Set {
+	s := &Set{settings: settings, flags: flags, sources: make(map[string]string)}
+	for _, setting := range settings {
+		s.sources[setting.Key] = SourceDefault
+		if setting.Flag != "" {
+			flags.VarP(setting.Value, setting.Flag, setting.Short, setting.Usage)
+		}
+	}
+	return s
+}
+
+// Load применяет файл и переменные окружения к параметрам, не заданным флагами,
+// и проверяет обязательные параметры. Вызывается после разбора флагов.
+// Отсутствие файла не является ошибкой, если required не установлен
+func (s *Set) Load(file string, required bool) error {
+	values, err := readFile(file, required)
+	if err != nil {
+		return err
+	}
+	if values != nil {
+		s.file = file
+	}
+
+	// Неизвестные параметры в известных разделах - вероятно, опечатки
+	sections := make(map[string]bool)
+	known := make(map[string]bool)
+	for _, setting := range s.settings {
+		sections[section(setting.Key)] = true
+		known[setting.Key] = true
+	}
+	for key := range values {
+		if sections[section(key)] && !known[key] {
+			return fmt.Errorf("%w %s in %s", ErrUnknownSetting, key, file)
+		}
+	}
+
+	for _, setting := range s.settings {
+		if setting.Flag != "" && s.flags.Changed(setting.Flag) {
+			s.sources[setting.Key] = SourceFlag
+			continue
+		}
+		if env, ok := os.LookupEnv(setting.Env); ok && setting.Env != "" && env != "" {
+			if err := setting.Value.Set(env); err != nil {
+				return fmt.Errorf("%s: %w", setting.Env, err)
+			}
+			s.sources[setting.Key] = SourceEnv
+			continue
+		}
+		if value, ok := values[setting.Key]; ok {
+			if err := setting.Value.Set(value); err != nil {
+				return fmt.Errorf("%s in %s: %w", setting.Key, file, err)
+			}
+			s.sources[setting.Key] = SourceFile
+		}
+	}
+
+	for _, setting := range s.settings {
+		if setting.Required && setting.Value.String() == "" {
+			return fmt.Errorf("%w: %s", ErrRequired, setting.describe())
+		}
+	}
+	return nil
+}
+
+// Value - действующее значение параметра
+type Value struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// Values возвращает действующие значения и их источники. Секреты скрываются
+func (s *Set) Values() []Value {
+	values := make([]Value, 0, len(s.settings))
+	for _, setting := range s.settings {
+		value := setting.Value.String()
+		if setting.Secret && value != "" {
+			value = redacted
+		}
+		values = append(values, Value{Key: setting.Key, Value: value, Source: s.sources[setting.Key]})
+	}
+	return values
+}
+
+// File возвращает прочитанный файл конфигурации. Пустой - файл не читался
+func (s *Set) File() string {
+	return s.file
+}
+
+// Show выводит действующие значения и их источники. Секреты скрываются
+func (s *Set) Show(w io.Writer) {
+	if s.file != "" {
+		fmt.Fprintf(w, "# file: %s\n", s.file)
+	}
+	for _, value := range s.Values() {
+		fmt.Fprintf(w, "%s: %s\t# %s\n", value.Key, strconv.Quote(value.Value), value.Source)
+	}
+}
+
+// Args возвращает аргументы, оставшиеся после разбора флагов
+func (s *Set) Args() []string {
+	return s.flags.Args()
+}
+
+// describe перечисляет способы задать параметр
+func (setting Setting) describe() string {
+	ways := []string{setting.Key + " in file"}
+	if setting.Env != "" {
+		ways = append(ways, "env "+setting.Env)
+	}
+	if setting.Flag != "" {
+		ways = append(ways, "flag --"+setting.Flag)
+	}
+	return strings.Join(ways, ", ")
+}
+
+// section возвращает раздел ключа
+func section(key string) string {
+	name, _, _ := strings.Cut(key, ".")
+	return name
+}
+
+// readFile читает файл YAML в плоский набор ключей раздел.параметр.
+// Для отсутствующего файла возвращает nil
+func readFile(file string, required bool) (map[string]string, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	values := make(map[string]string)
+	flatten("", tree, values)
+	return values, nil
+}
+
+// flatten раскладывает вложенные разделы в ключи через точку
+func flatten(prefix string, tree map[string]any, values map[string]string) {
+	for key, value := range tree {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(key, v, values)
+		case nil:
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// String - строковый параметр
+func String(p *string) pflag.Value { return (*stringValue)(p) }
+
+// Int - целочисленный параметр
+func Int(p *int) pflag.Value { return (*intValue)(p) }
+
+// Duration - параметр длительности (15m, 1h30m)
+func Duration(p *time.Duration) pflag.Value { return (*durationValue)(p) }
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error { *v = stringValue(s); return nil }
+func (v *stringValue) String() string     { return string(*v) }
+func (v *stringValue) Type() string       { return "string" }
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*v = intValue(i)
+	return nil
+}
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+func (v *intValue) Type() string   { return "int" }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*v = durationValue(d)
+	return nil
+}
+func (v *durationValue) String() string { return time.Duration(*v).String() }
+func (v *durationValue) Type() string   { return "duration" }
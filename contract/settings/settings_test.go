@@ -0,0 +1,68 @@
+package settings
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSet_Load(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+db:
+  dsn: file-dsn
+  timeout: 5s
+log:
+  level: warn
+profiles:
+  work:
+    server: team:3200
+`), 0600))
+
+	var dsn, level, key string
+	var timeout time.Duration
+	retries := 3
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	set := New(flags,
+		Setting{Key: "db.dsn", Env: "TEST_DSN", Flag: "dsn", Secret: true, Value: String(&dsn)},
+		Setting{Key: "db.timeout", Env: "TEST_TIMEOUT", Flag: "timeout", Value: Duration(&timeout)},
+		Setting{Key: "db.retries", Env: "TEST_RETRIES", Value: Int(&retries)},
+		Setting{Key: "log.level", Env: "TEST_LEVEL", Flag: "level", Value: String(&level)},
+		Setting{Key: "crypto.key", Env: "TEST_KEY", Secret: true, Required: true, Value: String(&key)},
+	)
+	require.NoError(t, flags.Parse([]string{"--level", "error"}))
+	t.Setenv("TEST_DSN", "env-dsn")
+	t.Setenv("TEST_LEVEL", "debug")
+
+	// Обязательный параметр не задан
+	require.ErrorIs(t, set.Load(file, true), ErrRequired)
+
+	// Флаг, переменная среды, файл, значение по умолчанию
+	t.Setenv("TEST_KEY", "secret")
+	require.NoError(t, set.Load(file, true))
+	require.Equal(t, "error", level)
+	require.Equal(t, "env-dsn", dsn)
+	require.Equal(t, 5*time.Second, timeout)
+	require.Equal(t, 3, retries)
+
+	var out bytes.Buffer
+	set.Show(&out)
+	require.Contains(t, out.String(), "log.level: \"error\"\t# flag")
+	require.Contains(t, out.String(), "db.dsn: \"***\"\t# env")
+	require.Contains(t, out.String(), "db.timeout: \"5s\"\t# file")
+	require.Contains(t, out.String(), "db.retries: \"3\"\t# default")
+	require.NotContains(t, out.String(), "secret")
+
+	// Опечатка в известном разделе
+	require.NoError(t, os.WriteFile(file, []byte("db:\n  dns: x\n"), 0600))
+	require.ErrorIs(t, set.Load(file, true), ErrUnknownSetting)
+
+	// Файл по умолчанию может отсутствовать
+	require.NoError(t, set.Load(filepath.Join(t.TempDir(), "none.yaml"), false))
+	require.Error(t, set.Load(filepath.Join(t.TempDir(), "none.yaml"), true))
+}
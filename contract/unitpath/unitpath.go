@@ -0,0 +1,204 @@
+// Пакет unitpath. Иерархические имена единиц данных (infra/db/prod).
+// Общие правила для клиента и сервера
+package unitpath
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Separator - разделитель папок в имени
+const Separator = "/"
+
+// MaxLen - максимальная длина полного имени
+const MaxLen = 255
+
+var (
+	ErrInvalidName = errors.New("invalid unit name")
+)
+
+// Clean проверяет и нормализует имя единицы данных.
+// Крайние разделители отбрасываются, пустые сегменты и "."/".." запрещены
+func Clean(name string) (string, error) {
+	name = strings.Trim(strings.TrimSpace(name), Separator)
+	if name == "" {
+		return "", ErrInvalidName
+	}
+	if err := check(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// CleanFolder нормализует имя папки. Пустая строка - корень
+func CleanFolder(folder string) (string, error) {
+	folder = strings.Trim(strings.TrimSpace(folder), Separator)
+	if folder == "" {
+		return "", nil
+	}
+	if err := check(folder); err != nil {
+		return "", err
+	}
+	return folder, nil
+}
+
+// check проверяет сегменты имени
+func check(name string) error {
+	if len(name) > MaxLen {
+		return ErrInvalidName
+	}
+	for _, segment := range strings.Split(name, Separator) {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidName
+		}
+		if strings.IndexFunc(segment, unicode.IsControl) != -1 {
+			return ErrInvalidName
+		}
+	}
+	return nil
+}
+
+// Dir возвращает папку единицы данных ("" для корня)
+func Dir(name string) string {
+	idx := strings.LastIndex(name, Separator)
+	if idx == -1 {
+		return ""
+	}
+	return name[:idx]
+}
+
+// Base возвращает последний сегмент имени
+func Base(name string) string {
+	return name[strings.LastIndex(name, Separator)+1:]
+}
+
+// Join собирает имя из папки и базового имени
+func Join(folder string, base string) string {
+	if folder == "" {
+		return base
+	}
+	return folder + Separator + base
+}
+
+// Prefix возвращает префикс для поиска содержимого папки ("" для корня)
+func Prefix(folder string) string {
+	if folder == "" {
+		return ""
+	}
+	return folder + Separator
+}
+
+// InFolder проверяет, что имя находится в папке (непосредственно или во вложенной)
+func InFolder(name string, folder string, recursive bool) bool {
+	if !strings.HasPrefix(name, Prefix(folder)) || name == folder {
+		return false
+	}
+	if recursive {
+		return true
+	}
+	return !strings.Contains(name[len(Prefix(folder)):], Separator)
+}
+
+// Rebase переносит имя из папки from в папку to
+func Rebase(name string, from string, to string) string {
+	if name == from {
+		return to
+	}
+	return Prefix(to) + strings.TrimPrefix(name, Prefix(from))
+}
+
+// MoveTarget проверяет и нормализует параметры перемещения.
+// to - полное новое имя; при завершающем разделителе - папка назначения с сохранением имени.
+// Перемещение папки внутрь самой себя запрещено
+func MoveTarget(from string, to string) (string, string, error) {
+	from, err := Clean(from)
+	if err != nil {
+		return "", "", err
+	}
+	if strings.HasSuffix(to, Separator) {
+		folder, err := CleanFolder(to)
+		if err != nil {
+			return "", "", err
+		}
+		to = Join(folder, Base(from))
+	}
+	to, err = Clean(to)
+	if err != nil {
+		return "", "", err
+	}
+	if to == from || InFolder(to, from, true) {
+		return "", "", ErrInvalidName
+	}
+	return from, to, nil
+}
+
+// RenameTarget возвращает полное новое имя при переименовании в пределах родительской папки
+func RenameTarget(from string, name string) (string, string, error) {
+	if name == "" || strings.Contains(name, Separator) {
+		return "", "", ErrInvalidName
+	}
+	from, err := Clean(from)
+	if err != nil {
+		return "", "", err
+	}
+	return MoveTarget(from, Join(Dir(from), name))
+}
+
+// Ancestors возвращает все родительские папки имени, начиная с верхней
+func Ancestors(name string) []string {
+	var ancestors []string
+	parts := strings.Split(name, Separator)
+	for i := 1; i < len(parts); i++ {
+		ancestors = append(ancestors, strings.Join(parts[:i], Separator))
+	}
+	return ancestors
+}
+
+// Filter отбирает единицы данных и папки внутри folder.
+// folders - явно созданные папки; неявные папки выводятся из имен единиц.
+// Без recursive возвращается только непосредственное содержимое
+func Filter(units []string, folders []string, folder string, recursive bool) ([]string, []string) {
+	var resUnits []string
+	resFolders := make(map[string]struct{})
+
+	addFolder := func(f string) {
+		if !InFolder(f, folder, true) {
+			return
+		}
+		if recursive {
+			resFolders[f] = struct{}{}
+			return
+		}
+		// Непосредственная подпапка
+		rest := f[len(Prefix(folder)):]
+		if idx := strings.Index(rest, Separator); idx != -1 {
+			rest = rest[:idx]
+		}
+		resFolders[Join(folder, rest)] = struct{}{}
+	}
+
+	for _, name := range units {
+		if InFolder(name, folder, recursive) {
+			resUnits = append(resUnits, name)
+		}
+		for _, f := range Ancestors(name) {
+			addFolder(f)
+		}
+	}
+	for _, f := range folders {
+		for _, ancestor := range Ancestors(f) {
+			addFolder(ancestor)
+		}
+		addFolder(f)
+	}
+
+	folderList := make([]string, 0, len(resFolders))
+	for f := range resFolders {
+		folderList = append(folderList, f)
+	}
+	slices.Sort(resUnits)
+	slices.Sort(folderList)
+	return resUnits, folderList
+}
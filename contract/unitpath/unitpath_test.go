@@ -0,0 +1,80 @@
+package unitpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "flat", input: "secret1", want: "secret1"},
+		{name: "path", input: "/infra/db/prod/", want: "infra/db/prod"},
+		{name: "empty", input: "/", wantErr: true},
+		{name: "empty segment", input: "infra//prod", wantErr: true},
+		{name: "dot segment", input: "infra/../prod", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Clean(test.input)
+			if test.wantErr {
+				require.ErrorIs(t, err, ErrInvalidName)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	units := []string{"top", "infra/db/prod", "infra/db/test", "infra/web"}
+	folders := []string{"empty", "infra/cache"}
+
+	tests := []struct {
+		name        string
+		folder      string
+		recursive   bool
+		wantUnits   []string
+		wantFolders []string
+	}{
+		{
+			name:        "root",
+			folder:      "",
+			wantUnits:   []string{"top"},
+			wantFolders: []string{"empty", "infra"},
+		},
+		{
+			name:        "folder",
+			folder:      "infra",
+			wantUnits:   []string{"infra/web"},
+			wantFolders: []string{"infra/cache", "infra/db"},
+		},
+		{
+			name:        "recursive",
+			folder:      "infra",
+			recursive:   true,
+			wantUnits:   []string{"infra/db/prod", "infra/db/test", "infra/web"},
+			wantFolders: []string{"infra/cache", "infra/db"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotUnits, gotFolders := Filter(units, folders, test.folder, test.recursive)
+			require.Equal(t, test.wantUnits, gotUnits)
+			require.Equal(t, test.wantFolders, gotFolders)
+		})
+	}
+}
+
+func TestRebase(t *testing.T) {
+	require.Equal(t, "ops/db/prod", Rebase("infra/db/prod", "infra", "ops"))
+	require.Equal(t, "ops", Rebase("infra", "infra", "ops"))
+}
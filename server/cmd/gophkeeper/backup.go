@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/server/internal/backup"
+	"github.com/iurnickita/gophkeeper/server/internal/config"
+	"github.com/iurnickita/gophkeeper/server/internal/crypto/aesgcm"
+	"github.com/iurnickita/gophkeeper/server/internal/store"
+)
+
+// runBackup записывает согласованный снимок хранилища в новый файл
+func runBackup(cfg config.Config, file string) error {
+	db, err := store.NewStore(cfg.Store)
+	if err != nil {
+		return err
+	}
+	dump, err := db.Dump(context.Background())
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	manifest, err := backup.Write(f, dump)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file)
+		return err
+	}
+	fmt.Printf("backup %s: %d users, %d keys, %d folders, %d units\n",
+		file, manifest.Users, manifest.Keys, manifest.Folders, manifest.Units)
+	return nil
+}
+
+// runRestore проверяет резервную копию и восстанавливает ее.
+// Без logins - полное восстановление в пустое хранилище с промежуточными ключами копии.
+// С logins - замена данных указанных пользователей; данные должны расшифровываться
+// промежуточными ключами текущего хранилища, поэтому копию, снятую при других
+// промежуточных ключах (другой encryption_sk), по пользователям восстановить нельзя
+func runRestore(cfg config.Config, file string, logins []string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	archive, err := backup.Read(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	dump := archive.Dump
+	if len(logins) > 0 {
+		if dump, err = backup.Select(dump, logins); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	db, err := store.NewStore(cfg.Store)
+	if err != nil {
+		return err
+	}
+	var keys []string
+	if len(logins) > 0 {
+		keys, err = db.GetEncryptSK(ctx)
+	} else {
+		keys, err = backup.Keys(dump)
+	}
+	if err != nil {
+		return err
+	}
+	crypter, err := aesgcm.OpenCrypter(cfg.Crypter, keys)
+	if err != nil {
+		return err
+	}
+	if err := backup.Verify(dump, crypter); err != nil {
+		if len(logins) > 0 {
+			return fmt.Errorf("%w: restoring selected users requires a backup taken under the intermediate keys of this database, backups from another key set can only be restored in full into an empty database", err)
+		}
+		return err
+	}
+
+	if err := db.Load(ctx, dump, len(logins) > 0); err != nil {
+		if errors.Is(err, store.ErrNotEmpty) {
+			return fmt.Errorf("%w: full restore requires an empty database, restore selected users with restore <file> <login>...", err)
+		}
+		return err
+	}
+	fmt.Printf("restored %s (created %s): %d users, %d folders, %d units\n",
+		file, archive.Manifest.CreatedAt.Format(time.RFC3339), len(dump.Auth), len(dump.Folders), len(dump.DataUnits))
+	return nil
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"slices"
 
 	"github.com/iurnickita/gophkeeper/server/internal/auth"
 	"github.com/iurnickita/gophkeeper/server/internal/config"
 	"github.com/iurnickita/gophkeeper/server/internal/crypto/aesgcm"
 	grpcserver "github.com/iurnickita/gophkeeper/server/internal/grpc_server/server"
 	"github.com/iurnickita/gophkeeper/server/internal/logger"
+	"github.com/iurnickita/gophkeeper/server/internal/notify"
 	"github.com/iurnickita/gophkeeper/server/internal/service"
 	"github.com/iurnickita/gophkeeper/server/internal/store"
 )
@@ -19,7 +23,26 @@ func main() {
 }
 
 func run() error {
-	cfg := config.GetConfig()
+	cfg, set, err := config.GetConfig(os.Args[1:])
+	if set == nil {
+		return err
+	}
+	// config show - вывод действующей конфигурации,
+	// backup и restore - резервное копирование хранилища
+	args := set.Args()
+	switch {
+	case slices.Equal(args, []string{"config", "show"}):
+		set.Show(os.Stdout)
+		return err
+	case err != nil:
+		return err
+	case len(args) == 2 && args[0] == "backup":
+		return runBackup(cfg, args[1])
+	case len(args) >= 2 && args[0] == "restore":
+		return runRestore(cfg, args[1], args[2:])
+	case len(args) > 0:
+		return fmt.Errorf("unknown command %q, usage: gophkeeper-server [flags] [config show | backup <file> | restore <file> [login...]], restoring selected users needs a backup taken under this database's intermediate keys", args)
+	}
 
 	zaplog, err := logger.NewZapLog(cfg.Logger)
 	if err != nil {
@@ -31,7 +54,7 @@ func run() error {
 		return err
 	}
 
-	auth, err := auth.NewAuth(store)
+	auth, err := auth.NewAuth(cfg.Auth, store)
 	if err != nil {
 		return err
 	}
@@ -41,7 +64,13 @@ func run() error {
 		return err
 	}
 
-	service, err := service.NewService(cfg.Service, store, crypter, zaplog)
+	broker, err := notify.NewBroker(cfg.Notify, zaplog)
+	if err != nil {
+		return err
+	}
+	defer broker.Close()
+
+	service, err := service.NewService(cfg.Service, store, crypter, broker, zaplog)
 	if err != nil {
 		return err
 	}
@@ -2,8 +2,12 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strconv"
 
+	"github.com/iurnickita/gophkeeper/contract/pwned"
+	"github.com/iurnickita/gophkeeper/server/internal/auth/config"
 	"github.com/iurnickita/gophkeeper/server/internal/store"
 	"github.com/iurnickita/gophkeeper/server/internal/token"
 	"google.golang.org/grpc"
@@ -16,6 +20,7 @@ type Auth interface {
 	Register(ctx context.Context, login string, password string) (string, error)
 	Login(ctx context.Context, login string, password string) (string, error)
 	AuthUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error)
+	AuthStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error
 }
 
 type Key string
@@ -25,17 +30,42 @@ const (
 	ContextUserID     Key    = "userID"
 )
 
+var (
+	ErrBreachedPassword = errors.New("password appears in known data breaches, choose another one")
+)
+
 type auth struct {
 	store store.Store
+	// Локальная копия Pwned Passwords; nil - не проверяется
+	pwned *pwned.Store
 }
 
-func NewAuth(store store.Store) (Auth, error) {
-	return &auth{store: store}, nil
+func NewAuth(cfg config.Config, store store.Store) (Auth, error) {
+	a := &auth{store: store}
+	if cfg.PwnedFile != "" {
+		var err error
+		a.pwned, err = pwned.Open(cfg.PwnedFile)
+		if err != nil {
+			return nil, fmt.Errorf("pwned passwords: %w", err)
+		}
+	}
+	return a, nil
 }
 
 // Register
-// Ошибки: store.ErrAlreadyExists
+// Ошибки: store.ErrAlreadyExists, ErrBreachedPassword
 func (a *auth) Register(ctx context.Context, login string, password string) (string, error) {
+	// Проверка по утечкам
+	if a.pwned != nil {
+		count, err := a.pwned.Count(password)
+		if err != nil {
+			return "", err
+		}
+		if count > 0 {
+			return "", ErrBreachedPassword
+		}
+	}
+
 	// Запись в БД
 	userID, err := a.store.AuthRegister(ctx, login, password)
 	if err != nil {
@@ -76,6 +106,35 @@ func (a *auth) AuthUnaryInterceptor(ctx context.Context, req interface{}, info *
 		return handler(ctx, req)
 	}
 
+	ctx, err := authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// AuthStreamInterceptor прослойка аутентификации для потоковых gRPC хендлеров
+func (a *auth) AuthStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+// authStream поток с контекстом, содержащим код пользователя
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate проверяет токен из метаданных и записывает код пользователя в контекст
+func authenticate(ctx context.Context, method string) (context.Context, error) {
 	// Получение метаданных из контекста
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
 		var t string
@@ -91,11 +150,10 @@ func (a *auth) AuthUnaryInterceptor(ctx context.Context, req interface{}, info *
 				return nil, status.Errorf(codes.Unauthenticated, err.Error())
 			}
 		} else {
-			return nil, status.Errorf(codes.Unauthenticated, "%s Unauthenticated. Use Register procedure", info.FullMethod)
+			return nil, status.Errorf(codes.Unauthenticated, "%s Unauthenticated. Use Register procedure", method)
 		}
 		// Запись кода пользователя в контекст для дальнейшего использования
 		ctx = context.WithValue(ctx, ContextUserID, userID)
 	}
-
-	return handler(ctx, req)
+	return ctx, nil
 }
@@ -0,0 +1,7 @@
+package config
+
+type Config struct {
+	// Локальная копия Pwned Passwords: файл, упорядоченный по хешу, или каталог диапазонов.
+	// Пароли из утечек не принимаются при регистрации. Пустой - не проверяется
+	PwnedFile string
+}
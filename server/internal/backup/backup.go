@@ -0,0 +1,354 @@
+// Пакет backup. Резервная копия хранилища: согласованный снимок учетных записей,
+// промежуточных ключей, папок и единиц данных в архиве tar.gz.
+// Архив: manifest.json (версия, счетчики, размер и SHA-256 каждого файла),
+// строки таблиц в JSON Lines и данные единиц данных в blobs/<sha256>
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/server/internal/crypto/aesgcm"
+	"github.com/iurnickita/gophkeeper/server/internal/model"
+)
+
+// Version - версия формата резервной копии
+const Version = 1
+
+// Файлы архива
+const (
+	manifestFile     = "manifest.json"
+	authFile         = "auth.jsonl"
+	encryptionSKFile = "encryption_sk.jsonl"
+	foldersFile      = "folders.jsonl"
+	dataUnitsFile    = "data_units.jsonl"
+	blobsDir         = "blobs/"
+)
+
+// timestampLayout - TIMESTAMP в JSON postgresql
+const timestampLayout = "2006-01-02T15:04:05.999999999"
+
+var (
+	ErrFormat      = errors.New("not a gophkeeper backup")
+	ErrVersion     = errors.New("unsupported backup version")
+	ErrIntegrity   = errors.New("backup integrity check failed")
+	ErrUnknownUser = errors.New("user not found in backup")
+	ErrDecrypt     = errors.New("backup is not decryptable with the master key")
+)
+
+// Manifest - опись архива
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Users     int       `json:"users"`
+	Keys      int       `json:"keys"`
+	Folders   int       `json:"folders"`
+	Units     int       `json:"units"`
+	Files     []File    `json:"files"`
+}
+
+// File - файл архива с контрольной суммой
+type File struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Archive - прочитанная резервная копия
+type Archive struct {
+	Manifest Manifest
+	Dump     model.Dump
+}
+
+// line - строка таблицы в архиве. Blob - SHA-256 данных единицы данных
+type line struct {
+	Row  json.RawMessage `json:"row"`
+	Blob string          `json:"blob,omitempty"`
+}
+
+// Write записывает снимок в архив
+func Write(w io.Writer, dump model.Dump) (Manifest, error) {
+	manifest := Manifest{
+		Version:   Version,
+		CreatedAt: time.Now(),
+		Users:     len(dump.Auth),
+		Keys:      len(dump.EncryptionSK),
+		Folders:   len(dump.Folders),
+		Units:     len(dump.DataUnits),
+	}
+
+	// Содержимое файлов; одинаковые данные хранятся один раз
+	files := make(map[string][]byte)
+	tables := []struct {
+		name string
+		rows []model.DumpRow
+	}{
+		{authFile, dump.Auth},
+		{encryptionSKFile, dump.EncryptionSK},
+		{foldersFile, dump.Folders},
+		{dataUnitsFile, dump.DataUnits},
+	}
+	for _, table := range tables {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		for _, row := range table.rows {
+			l := line{Row: row.Row}
+			if table.name == dataUnitsFile {
+				l.Blob = checksum(row.Data)
+				files[blobsDir+l.Blob] = row.Data
+			}
+			if err := enc.Encode(l); err != nil {
+				return Manifest{}, err
+			}
+		}
+		files[table.name] = buf.Bytes()
+	}
+	for name, data := range files {
+		manifest.Files = append(manifest.Files, File{Name: name, Size: int64(len(data)), SHA256: checksum(data)})
+	}
+	slices.SortFunc(manifest.Files, func(a, b File) int { return strings.Compare(a.Name, b.Name) })
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, err
+	}
+	if err := writeFile(tw, manifestFile, data, manifest.CreatedAt); err != nil {
+		return Manifest{}, err
+	}
+	for _, file := range manifest.Files {
+		if err := writeFile(tw, file.Name, files[file.Name], manifest.CreatedAt); err != nil {
+			return Manifest{}, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return Manifest{}, err
+	}
+	return manifest, gz.Close()
+}
+
+// writeFile
+func writeFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: modTime, Typeflag: tar.TypeReg})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// Read читает архив и проверяет контрольные суммы всех файлов по описи
+func Read(r io.Reader) (Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Archive{}, ErrFormat
+	}
+	tr := tar.NewReader(gz)
+	files := make(map[string][]byte)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Archive{}, fmt.Errorf("%w: %w", ErrFormat, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if files[path.Clean(header.Name)], err = io.ReadAll(tr); err != nil {
+			return Archive{}, err
+		}
+	}
+
+	data, ok := files[manifestFile]
+	if !ok {
+		return Archive{}, ErrFormat
+	}
+	var archive Archive
+	if err := json.Unmarshal(data, &archive.Manifest); err != nil {
+		return Archive{}, fmt.Errorf("%w: %w", ErrFormat, err)
+	}
+	if archive.Manifest.Version != Version {
+		return Archive{}, fmt.Errorf("%w %d", ErrVersion, archive.Manifest.Version)
+	}
+	for _, file := range archive.Manifest.Files {
+		data, ok := files[file.Name]
+		if !ok {
+			return Archive{}, fmt.Errorf("%w: %s is missing", ErrIntegrity, file.Name)
+		}
+		if int64(len(data)) != file.Size || checksum(data) != file.SHA256 {
+			return Archive{}, fmt.Errorf("%w: %s", ErrIntegrity, file.Name)
+		}
+	}
+	if len(files) != len(archive.Manifest.Files)+1 {
+		return Archive{}, fmt.Errorf("%w: files not listed in manifest", ErrIntegrity)
+	}
+
+	tables := []struct {
+		name string
+		rows *[]model.DumpRow
+	}{
+		{authFile, &archive.Dump.Auth},
+		{encryptionSKFile, &archive.Dump.EncryptionSK},
+		{foldersFile, &archive.Dump.Folders},
+		{dataUnitsFile, &archive.Dump.DataUnits},
+	}
+	for _, table := range tables {
+		if *table.rows, err = readRows(files, table.name); err != nil {
+			return Archive{}, err
+		}
+	}
+	counts := []int{archive.Manifest.Users, archive.Manifest.Keys, archive.Manifest.Folders, archive.Manifest.Units}
+	for i, table := range tables {
+		if len(*table.rows) != counts[i] {
+			return Archive{}, fmt.Errorf("%w: %s has %d rows, manifest %d", ErrIntegrity, table.name, len(*table.rows), counts[i])
+		}
+	}
+	return archive, nil
+}
+
+// readRows разбирает строки таблицы и связывает единицы данных с их данными
+func readRows(files map[string][]byte, name string) ([]model.DumpRow, error) {
+	data, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is missing", ErrIntegrity, name)
+	}
+	var rows []model.DumpRow
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var l line
+		if err := dec.Decode(&l); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrFormat, name, err)
+		}
+		var key struct {
+			UserID *int `json:"userid"`
+		}
+		if err := json.Unmarshal(l.Row, &key); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrFormat, name, err)
+		}
+		row := model.DumpRow{Row: l.Row}
+		if key.UserID != nil {
+			row.UserID = *key.UserID
+		}
+		if name == dataUnitsFile {
+			if row.Data, ok = files[blobsDir+l.Blob]; !ok || checksum(row.Data) != l.Blob {
+				return nil, fmt.Errorf("%w: blob %s", ErrIntegrity, l.Blob)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// Select оставляет в снимке учетные записи logins с их папками и единицами данных
+func Select(dump model.Dump, logins []string) (model.Dump, error) {
+	users := make(map[int]bool, len(logins))
+	selected := model.Dump{EncryptionSK: dump.EncryptionSK}
+	for _, login := range logins {
+		i := slices.IndexFunc(dump.Auth, func(row model.DumpRow) bool {
+			var auth struct {
+				Login string `json:"login"`
+			}
+			return json.Unmarshal(row.Row, &auth) == nil && auth.Login == login
+		})
+		if i < 0 {
+			return model.Dump{}, fmt.Errorf("%w: %s", ErrUnknownUser, login)
+		}
+		if !users[dump.Auth[i].UserID] {
+			users[dump.Auth[i].UserID] = true
+			selected.Auth = append(selected.Auth, dump.Auth[i])
+		}
+	}
+	for _, row := range dump.Folders {
+		if users[row.UserID] {
+			selected.Folders = append(selected.Folders, row)
+		}
+	}
+	for _, row := range dump.DataUnits {
+		if users[row.UserID] {
+			selected.DataUnits = append(selected.DataUnits, row)
+		}
+	}
+	return selected, nil
+}
+
+// Keys возвращает зашифрованные мастер-ключом промежуточные ключи снимка
+func Keys(dump model.Dump) ([]string, error) {
+	keys := make([]string, 0, len(dump.EncryptionSK))
+	for _, row := range dump.EncryptionSK {
+		var key struct {
+			Body string `json:"body"`
+		}
+		if err := json.Unmarshal(row.Row, &key); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrFormat, encryptionSKFile, err)
+		}
+		keys = append(keys, key.Body)
+	}
+	return keys, nil
+}
+
+// Verify проверяет, что данные и чувствительные поля каждой единицы данных,
+// включая корзину, расшифровываются
+func Verify(dump model.Dump, crypter aesgcm.Crypter) error {
+	var failed int
+	var first error
+	for _, row := range dump.DataUnits {
+		if err := verifyUnit(row, crypter); err != nil {
+			failed++
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d units, first: %w", ErrDecrypt, failed, len(dump.DataUnits), first)
+	}
+	return nil
+}
+
+// verifyUnit расшифровывает единицу данных снимка
+func verifyUnit(row model.DumpRow, crypter aesgcm.Crypter) error {
+	var unit struct {
+		UnitID     int64         `json:"unitid"`
+		UserID     int           `json:"userid"`
+		UnitName   string        `json:"unitname"`
+		UploadedAt string        `json:"uploadedat"`
+		DataSK     string        `json:"datask"`
+		Fields     []model.Field `json:"fields"`
+	}
+	if err := json.Unmarshal(row.Row, &unit); err != nil {
+		return err
+	}
+	uploadedAt, err := time.Parse(timestampLayout, unit.UploadedAt)
+	if err != nil {
+		return fmt.Errorf("unitid %d: %w", unit.UnitID, err)
+	}
+	_, err = crypter.UnitDecrypt(model.Unit{
+		Key:  model.UnitKey{UserID: unit.UserID, UnitName: unit.UnitName},
+		Meta: model.UnitMeta{DataSK: unit.DataSK, UploadedAt: uploadedAt, Fields: unit.Fields},
+		Data: row.Data,
+	})
+	if err != nil {
+		return fmt.Errorf("unitid %d: %w", unit.UnitID, err)
+	}
+	return nil
+}
+
+// checksum
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
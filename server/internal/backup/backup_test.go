@@ -0,0 +1,177 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/iurnickita/gophkeeper/server/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+// testDump - снимок двух пользователей
+func testDump() model.Dump {
+	return model.Dump{
+		Auth: []model.DumpRow{
+			{UserID: 1, Row: []byte(`{"login":"alice","userid":1,"password":"a"}`)},
+			{UserID: 2, Row: []byte(`{"login":"bob","userid":2,"password":"b"}`)},
+		},
+		EncryptionSK: []model.DumpRow{
+			{Row: []byte(`{"id":1,"body":"key1"}`)},
+		},
+		Folders: []model.DumpRow{
+			{UserID: 2, Row: []byte(`{"userid":2,"path":"f1"}`)},
+		},
+		DataUnits: []model.DumpRow{
+			{UserID: 1, Row: []byte(`{"unitid":1,"userid":1,"unitname":"u1","uploadedat":"2024-05-01T10:11:12.123456","datask":"sk"}`), Data: []byte("data1")},
+			{UserID: 2, Row: []byte(`{"unitid":2,"userid":2,"unitname":"u2","uploadedat":"2024-05-01T10:11:12","datask":"sk"}`), Data: []byte("data1")},
+			{UserID: 2, Row: []byte(`{"unitid":3,"userid":2,"unitname":"u3","uploadedat":"2024-05-01T10:11:12","datask":"bad"}`), Data: []byte{}},
+		},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	var buf bytes.Buffer
+	manifest, err := Write(&buf, testDump())
+	require.NoError(t, err)
+	require.Equal(t, 2, manifest.Users)
+	require.Equal(t, 3, manifest.Units)
+	// Таблицы и два разных блоба
+	require.Len(t, manifest.Files, 6)
+
+	archive, err := Read(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, manifest.Files, archive.Manifest.Files)
+	dump := archive.Dump
+	require.Len(t, dump.Auth, 2)
+	require.Equal(t, 2, dump.Auth[1].UserID)
+	require.Equal(t, 0, dump.EncryptionSK[0].UserID)
+	require.JSONEq(t, `{"userid":2,"path":"f1"}`, string(dump.Folders[0].Row))
+	require.Equal(t, []byte("data1"), dump.DataUnits[1].Data)
+	require.Empty(t, dump.DataUnits[2].Data)
+	require.NotNil(t, dump.DataUnits[2].Data)
+
+	keys, err := Keys(dump)
+	require.NoError(t, err)
+	require.Equal(t, []string{"key1"}, keys)
+}
+
+func TestRead_Integrity(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := Write(&buf, testDump())
+	require.NoError(t, err)
+
+	// Перезапись архива с изменением файла
+	rewrite := func(change func(name string, data []byte) []byte) []byte {
+		gr, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		tr := tar.NewReader(gr)
+		var out bytes.Buffer
+		gw := gzip.NewWriter(&out)
+		tw := tar.NewWriter(gw)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			data, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			if data = change(header.Name, data); data == nil {
+				continue
+			}
+			header.Size = int64(len(data))
+			require.NoError(t, tw.WriteHeader(header))
+			_, err = tw.Write(data)
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+		require.NoError(t, gw.Close())
+		return out.Bytes()
+	}
+
+	tests := []struct {
+		name   string
+		change func(name string, data []byte) []byte
+		err    error
+	}{
+		{
+			name: "changed row",
+			change: func(name string, data []byte) []byte {
+				if name == authFile {
+					return bytes.Replace(data, []byte("alice"), []byte("alica"), 1)
+				}
+				return data
+			},
+			err: ErrIntegrity,
+		},
+		{
+			name: "missing blob",
+			change: func(name string, data []byte) []byte {
+				if strings.HasPrefix(name, blobsDir) {
+					return nil
+				}
+				return data
+			},
+			err: ErrIntegrity,
+		},
+		{
+			name: "no manifest",
+			change: func(name string, data []byte) []byte {
+				if name == manifestFile {
+					return nil
+				}
+				return data
+			},
+			err: ErrFormat,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Read(bytes.NewReader(rewrite(test.change)))
+			require.ErrorIs(t, err, test.err)
+		})
+	}
+
+	_, err = Read(bytes.NewReader([]byte("not a backup")))
+	require.ErrorIs(t, err, ErrFormat)
+}
+
+func TestSelect(t *testing.T) {
+	dump, err := Select(testDump(), []string{"bob", "bob"})
+	require.NoError(t, err)
+	require.Len(t, dump.Auth, 1)
+	require.Len(t, dump.Folders, 1)
+	require.Len(t, dump.DataUnits, 2)
+	require.Len(t, dump.EncryptionSK, 1)
+
+	_, err = Select(testDump(), []string{"carol"})
+	require.ErrorIs(t, err, ErrUnknownUser)
+}
+
+// testCrypter не расшифровывает единицы данных с ключом "bad"
+type testCrypter struct{}
+
+func (testCrypter) UnitEncrypt(unit model.Unit) (model.Unit, error) { return unit, nil }
+
+func (testCrypter) UnitDecrypt(unit model.Unit) (model.Unit, error) {
+	if unit.Meta.DataSK == "bad" {
+		return model.Unit{}, errors.New("cipher: message authentication failed")
+	}
+	return unit, nil
+}
+
+func TestVerify(t *testing.T) {
+	dump := testDump()
+	err := Verify(dump, testCrypter{})
+	require.ErrorIs(t, err, ErrDecrypt)
+	require.ErrorContains(t, err, "1 of 3 units")
+	require.ErrorContains(t, err, "unitid 3")
+
+	dump.DataUnits = dump.DataUnits[:2]
+	require.NoError(t, Verify(dump, testCrypter{}))
+}
@@ -1,47 +1,109 @@
-// Пакет config. Конфигурация с помощью флагов/переменных среды/значений по умолчанию
+// Пакет config. Конфигурация из файла/переменных среды/флагов/значений по умолчанию
 package config
 
 import (
-	"flag"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"os"
 
+	"github.com/iurnickita/gophkeeper/contract/settings"
+	authConfig "github.com/iurnickita/gophkeeper/server/internal/auth/config"
 	crypterConfig "github.com/iurnickita/gophkeeper/server/internal/crypto/aesgcm/config"
 	grpcServerConig "github.com/iurnickita/gophkeeper/server/internal/grpc_server/server/config"
 	loggerConfig "github.com/iurnickita/gophkeeper/server/internal/logger/config"
+	notifyConfig "github.com/iurnickita/gophkeeper/server/internal/notify/config"
 	serviceConfig "github.com/iurnickita/gophkeeper/server/internal/service/config"
 	storeConfig "github.com/iurnickita/gophkeeper/server/internal/store/config"
+	"github.com/spf13/pflag"
+)
+
+// DefaultFile - файл конфигурации по умолчанию. Если не найден, не читается
+const DefaultFile = "gophkeeper-server.yaml"
+
+var (
+	ErrMasterKey     = errors.New("crypter.master_key must be 16, 24 or 32 bytes in hex")
+	ErrNotifyBackend = errors.New("notify.backend must be local or postgres")
 )
 
 // Config - общая конфигурация
 type Config struct {
 	GRPCServer grpcServerConig.Config
+	Auth       authConfig.Config
 	Service    serviceConfig.Config
 	Store      storeConfig.Config
 	Crypter    crypterConfig.Config
 	Logger     loggerConfig.Config
+	Notify     notifyConfig.Config
 }
 
-// GetConfig собирает конфигурацию сервиса
-func GetConfig() Config {
+// GetConfig собирает конфигурацию сервиса. Приоритет: флаги, переменные среды,
+// файл, значения по умолчанию. Набор параметров возвращается и при ошибке проверки,
+// чтобы вывести действующую конфигурацию
+func GetConfig(args []string) (Config, *settings.Set, error) {
 	cfg := Config{}
 
-	// Флаги
-	flag.StringVar(&cfg.Store.DBDsn, "d", "", "database dsn")
-	flag.StringVar(&cfg.Logger.LogLevel, "l", "info", "log level")
+	// По умолчанию
+	cfg.GRPCServer.Address = ":3200"
+	cfg.GRPCServer.TLSCertFile = "cert/server-cert.pem"
+	cfg.GRPCServer.TLSKeyFile = "cert/server-key.pem"
+	cfg.Crypter.NewSKIntervalD = 30
+	cfg.Service.TrashRetentionD = 30
+	cfg.Service.TrashPurgeIntervalM = 60
+	cfg.Logger.LogLevel = "info"
+	cfg.Notify.Backend = "local"
+
+	flags := pflag.NewFlagSet("gophkeeper-server", pflag.ContinueOnError)
+	file := flags.StringP("config", "c", DefaultFile, "config file")
+	set := settings.New(flags,
+		settings.Setting{Key: "grpc.address", Env: "ADDRESS", Flag: "address", Short: "a", Usage: "listen address",
+			Value: settings.String(&cfg.GRPCServer.Address)},
+		settings.Setting{Key: "grpc.tls_cert", Env: "TLS_CERT", Flag: "tls-cert", Usage: "TLS certificate file",
+			Value: settings.String(&cfg.GRPCServer.TLSCertFile)},
+		settings.Setting{Key: "grpc.tls_key", Env: "TLS_KEY", Flag: "tls-key", Usage: "TLS key file",
+			Value: settings.String(&cfg.GRPCServer.TLSKeyFile)},
+		settings.Setting{Key: "auth.pwned_file", Env: "PWNED_FILE", Flag: "pwned-file", Usage: "local Pwned Passwords file or range directory, breached passwords are rejected at registration",
+			Value: settings.String(&cfg.Auth.PwnedFile)},
+		settings.Setting{Key: "store.dsn", Env: "DATABASE_URI", Flag: "database-dsn", Short: "d", Usage: "database dsn",
+			Secret: true, Required: true, Value: settings.String(&cfg.Store.DBDsn)},
+		settings.Setting{Key: "crypter.master_key", Env: "MASTER_KEY",
+			Secret: true, Required: true, Value: settings.String(&cfg.Crypter.MasterSK)},
+		settings.Setting{Key: "crypter.new_key_interval_days", Env: "NEW_KEY_INTERVAL_DAYS", Flag: "new-key-interval-days", Usage: "interval of intermediate key rotation",
+			Value: settings.Int(&cfg.Crypter.NewSKIntervalD)},
+		settings.Setting{Key: "service.trash_retention_days", Env: "TRASH_RETENTION_DAYS", Flag: "trash-retention-days", Usage: "trash retention, 0 - keep forever",
+			Value: settings.Int(&cfg.Service.TrashRetentionD)},
+		settings.Setting{Key: "service.trash_purge_interval_minutes", Env: "TRASH_PURGE_INTERVAL_MINUTES", Flag: "trash-purge-interval-minutes", Usage: "interval of trash purge",
+			Value: settings.Int(&cfg.Service.TrashPurgeIntervalM)},
+		settings.Setting{Key: "logger.level", Env: "LOG_LEVEL", Flag: "log-level", Short: "l", Usage: "log level",
+			Value: settings.String(&cfg.Logger.LogLevel)},
+		settings.Setting{Key: "notify.backend", Env: "NOTIFY_BACKEND", Flag: "notify-backend", Usage: "event transport: local or postgres",
+			Value: settings.String(&cfg.Notify.Backend)},
+	)
+	if err := flags.Parse(args); err != nil {
+		return cfg, nil, err
+	}
 
-	// Переменные окружения
-	if envdsn := os.Getenv("DATABASE_URI"); envdsn != "" {
-		cfg.Store.DBDsn = envdsn
+	// Явно указанный файл обязателен
+	if envfile := os.Getenv("CONFIG"); envfile != "" && !flags.Changed("config") {
+		*file = envfile
 	}
-	if envlevel := os.Getenv("LOG_LEVEL"); envlevel != "" {
-		cfg.Logger.LogLevel = envlevel
+	explicit := flags.Changed("config") || os.Getenv("CONFIG") != ""
+	if err := set.Load(*file, explicit); err != nil {
+		return cfg, set, err
 	}
+	cfg.Notify.DBDsn = cfg.Store.DBDsn
 
-	// По умолчанию на момент разработки
-	cfg.Store.DBDsn = "host=localhost user=bob password=bob dbname=gophkeeper sslmode=disable"
-	cfg.Crypter.MasterSK = "cb459063d4bbbd4ce04a7c5b6e8121e7933630bada8fcb3abc20f6ca0aba3793"
-	cfg.Crypter.NewSKIntervalD = 30
-	cfg.Logger.LogLevel = "debug"
+	return cfg, set, cfg.validate()
+}
 
-	return cfg
+// validate проверяет значения параметров
+func (cfg Config) validate() error {
+	key, err := hex.DecodeString(cfg.Crypter.MasterSK)
+	if err != nil || (len(key) != 16 && len(key) != 24 && len(key) != 32) {
+		return ErrMasterKey
+	}
+	if cfg.Notify.Backend != "local" && cfg.Notify.Backend != "postgres" {
+		return fmt.Errorf("%w: %q", ErrNotifyBackend, cfg.Notify.Backend)
+	}
+	return nil
 }
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/iurnickita/gophkeeper/contract/settings"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetConfig(t *testing.T) {
+	const key = "cb459063d4bbbd4ce04a7c5b6e8121e7933630bada8fcb3abc20f6ca0aba3793"
+	t.Setenv("DATABASE_URI", "host=localhost")
+
+	tests := []struct {
+		name string
+		key  string
+		args []string
+		err  error
+	}{
+		{name: "no master key", err: settings.ErrRequired},
+		{name: "short master key", key: "abcd", err: ErrMasterKey},
+		{name: "unknown backend", key: key, args: []string{"--notify-backend", "kafka"}, err: ErrNotifyBackend},
+		{name: "ok", key: key, args: []string{"-l", "debug"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MASTER_KEY", tt.key)
+			cfg, _, err := GetConfig(append(tt.args, "--config", ""))
+			require.ErrorIs(t, err, tt.err)
+			if tt.err == nil {
+				require.Equal(t, "debug", cfg.Logger.LogLevel)
+				require.Equal(t, "host=localhost", cfg.Notify.DBDsn)
+			}
+		})
+	}
+}
@@ -52,7 +52,10 @@ func encrypt(stringToEncrypt string, keyString string) (encryptedString string,
 func decrypt(encryptedString string, keyString string) (decryptedString string, error error) {
 
 	key, _ := hex.DecodeString(keyString)
-	enc, _ := hex.DecodeString(encryptedString)
+	enc, err := hex.DecodeString(encryptedString)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrCiphertext, err)
+	}
 
 	//Create a new Cipher Block from the key
 	block, err := aes.NewCipher(key)
@@ -68,6 +71,9 @@ func decrypt(encryptedString string, keyString string) (decryptedString string,
 
 	//Get the nonce size
 	nonceSize := aesGCM.NonceSize()
+	if len(enc) < nonceSize+aesGCM.Overhead() {
+		return "", ErrCiphertext
+	}
 
 	//Extract the nonce from the encrypted data
 	nonce, ciphertext := enc[:nonceSize], enc[nonceSize:]
@@ -2,6 +2,9 @@ package aesgcm
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/iurnickita/gophkeeper/server/internal/crypto/aesgcm/config"
@@ -9,6 +12,11 @@ import (
 	"github.com/iurnickita/gophkeeper/server/internal/store"
 )
 
+var (
+	ErrMasterKey  = errors.New("intermediate keys do not match the master key")
+	ErrCiphertext = errors.New("ciphertext is malformed or too short")
+)
+
 type Crypter interface {
 	UnitEncrypt(unit model.Unit) (model.Unit, error)
 	UnitDecrypt(unit model.Unit) (model.Unit, error)
@@ -28,6 +36,17 @@ func (c crypter) UnitEncrypt(unit model.Unit) (model.Unit, error) {
 	}
 	// Запись зашифрованных данных
 	unit.Data = []byte(encrString)
+	// Шифрование чувствительных полей тем же ключом
+	unit.Meta.Fields = slices.Clone(unit.Meta.Fields)
+	for i, field := range unit.Meta.Fields {
+		if !field.Sensitive {
+			continue
+		}
+		unit.Meta.Fields[i].Value, err = encrypt(field.Value, unitSK)
+		if err != nil {
+			return model.Unit{}, err
+		}
+	}
 	// Шифрование уникального ключа промежуточным
 	encrSK, err := c.encryptSK.GetActual()
 	if err != nil {
@@ -56,6 +75,17 @@ func (c crypter) UnitDecrypt(unit model.Unit) (model.Unit, error) {
 	if err != nil {
 		return model.Unit{}, err
 	}
+	// Дешифрование чувствительных полей
+	unit.Meta.Fields = slices.Clone(unit.Meta.Fields)
+	for i, field := range unit.Meta.Fields {
+		if !field.Sensitive {
+			continue
+		}
+		unit.Meta.Fields[i].Value, err = decrypt(field.Value, unitSK)
+		if err != nil {
+			return model.Unit{}, err
+		}
+	}
 	unit.Meta.DataSK = ""
 	unit.Data = []byte(decrString)
 
@@ -72,17 +102,7 @@ func NewCrypter(cfg config.Config, store store.Store) (Crypter, error) {
 	}
 
 	// Дешифрование промежуточных ключей мастер-ключом
-	var decrStrings []string
-	for _, encrString := range encrStrings {
-		decrString, err := decrypt(encrString, cfg.MasterSK)
-		if err != nil {
-			return nil, err
-		}
-		decrStrings = append(decrStrings, decrString) // JSON стркои
-	}
-
-	// Создание объекта промежуточных ключей
-	encryptSK, err := NewEncryptSK(decrStrings)
+	encryptSK, err := openEncryptSK(cfg, encrStrings)
 	if err != nil {
 		return nil, err
 	}
@@ -127,3 +147,27 @@ func NewCrypter(cfg config.Config, store store.Store) (Crypter, error) {
 	crypter.encryptSK = encryptSK
 	return crypter, nil
 }
+
+// OpenCrypter создает объект шифрования по заданным зашифрованным промежуточным ключам,
+// без обращения к хранилищу и создания нового ключа. Используется для проверки резервной копии
+func OpenCrypter(cfg config.Config, encrStrings []string) (Crypter, error) {
+	encryptSK, err := openEncryptSK(cfg, encrStrings)
+	if err != nil {
+		return nil, err
+	}
+	return crypter{cfg: cfg, encryptSK: encryptSK}, nil
+}
+
+// openEncryptSK дешифрует промежуточные ключи мастер-ключом
+func openEncryptSK(cfg config.Config, encrStrings []string) (encryptSK, error) {
+	var decrStrings []string
+	for _, encrString := range encrStrings {
+		decrString, err := decrypt(encrString, cfg.MasterSK)
+		if err != nil {
+			return encryptSK{}, fmt.Errorf("%w: %w", ErrMasterKey, err)
+		}
+		decrStrings = append(decrStrings, decrString) // JSON строки
+	}
+	// Создание объекта промежуточных ключей
+	return NewEncryptSK(decrStrings)
+}
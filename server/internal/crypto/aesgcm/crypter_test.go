@@ -4,7 +4,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/iurnickita/gophkeeper/server/internal/crypto/aesgcm/config"
 	"github.com/iurnickita/gophkeeper/server/internal/model"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCrypter(t *testing.T) {
@@ -44,3 +46,115 @@ func TestCrypter(t *testing.T) {
 	}
 
 }
+
+func TestCrypter_SensitiveFields(t *testing.T) {
+	// Промежуточный ключ
+	var sk encryptSK
+	jsonKey, err := sk.CreateNewKey()
+	require.NoError(t, err)
+	sk, err = NewEncryptSK([]string{jsonKey})
+	require.NoError(t, err)
+	c := crypter{encryptSK: sk}
+
+	unit := model.Unit{
+		Key: model.UnitKey{UserID: 1, UnitName: "infra/db/prod"},
+		Meta: model.UnitMeta{
+			Type: model.UnitTypeLogin,
+			Fields: []model.Field{
+				{Key: "env", Value: "prod"},
+				{Key: "pin", Value: "1234", Sensitive: true},
+			},
+		},
+		Data: []byte("Таинственная тайна 2"),
+	}
+
+	// Шифрование
+	encrUnit, err := c.UnitEncrypt(unit)
+	require.NoError(t, err)
+	require.Equal(t, "prod", encrUnit.Meta.Fields[0].Value)
+	require.NotEqual(t, "1234", encrUnit.Meta.Fields[1].Value)
+	require.Equal(t, "1234", unit.Meta.Fields[1].Value)
+
+	// Дешифрование
+	encrUnit.Meta.UploadedAt = time.Now()
+	decrUnit, err := c.UnitDecrypt(encrUnit)
+	require.NoError(t, err)
+	require.Equal(t, unit.Meta.Fields, decrUnit.Meta.Fields)
+	require.Equal(t, unit.Data, decrUnit.Data)
+}
+
+func TestOpenCrypter(t *testing.T) {
+	cfg := config.Config{MasterSK: createNewKey()}
+	var sk encryptSK
+	jsonKey, err := sk.CreateNewKey()
+	require.NoError(t, err)
+	encrKey, err := encrypt(jsonKey, cfg.MasterSK)
+	require.NoError(t, err)
+
+	c, err := OpenCrypter(cfg, []string{encrKey})
+	require.NoError(t, err)
+	encrUnit, err := c.UnitEncrypt(model.Unit{Data: []byte("Таинственная тайна 3")})
+	require.NoError(t, err)
+	encrUnit.Meta.UploadedAt = time.Now()
+	decrUnit, err := c.UnitDecrypt(encrUnit)
+	require.NoError(t, err)
+	require.Equal(t, []byte("Таинственная тайна 3"), decrUnit.Data)
+
+	// Другой мастер-ключ
+	_, err = OpenCrypter(config.Config{MasterSK: createNewKey()}, []string{encrKey})
+	require.ErrorIs(t, err, ErrMasterKey)
+}
+
+func TestCrypter_ShortCiphertext(t *testing.T) {
+	var sk encryptSK
+	jsonKey, err := sk.CreateNewKey()
+	require.NoError(t, err)
+	sk, err = NewEncryptSK([]string{jsonKey})
+	require.NoError(t, err)
+	c := crypter{encryptSK: sk}
+
+	encrUnit, err := c.UnitEncrypt(model.Unit{Data: []byte("Таинственная тайна 4")})
+	require.NoError(t, err)
+	encrUnit.Meta.UploadedAt = time.Now()
+
+	tests := []struct {
+		name string
+		unit func(unit model.Unit) model.Unit
+	}{
+		{
+			name: "short data",
+			unit: func(unit model.Unit) model.Unit {
+				unit.Data = unit.Data[:8]
+				return unit
+			},
+		},
+		{
+			name: "empty data",
+			unit: func(unit model.Unit) model.Unit {
+				unit.Data = nil
+				return unit
+			},
+		},
+		{
+			name: "short key",
+			unit: func(unit model.Unit) model.Unit {
+				unit.Meta.DataSK = unit.Meta.DataSK[:10]
+				return unit
+			},
+		},
+		{
+			name: "not hex",
+			unit: func(unit model.Unit) model.Unit {
+				unit.Data = []byte("zz")
+				return unit
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := c.UnitDecrypt(test.unit(encrUnit))
+			require.ErrorIs(t, err, ErrCiphertext)
+		})
+	}
+}
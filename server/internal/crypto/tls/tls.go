@@ -6,8 +6,8 @@ import (
 	"google.golang.org/grpc/credentials"
 )
 
-func LoadTLSCredentials() (credentials.TransportCredentials, error) {
-	serverCert, err := tls.LoadX509KeyPair("cert/server-cert.pem", "cert/server-key.pem")
+func LoadTLSCredentials(certFile, keyFile string) (credentials.TransportCredentials, error) {
+	serverCert, err := tls.LoadX509KeyPair(certFile, keyFile)
 	if err != nil {
 		return nil, err
 	}
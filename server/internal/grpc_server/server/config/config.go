@@ -2,4 +2,9 @@ package config
 
 // Конфигурация grpc_server
 type Config struct {
+	// Адрес сервера
+	Address string
+	// Сертификат и ключ TLS
+	TLSCertFile string
+	TLSKeyFile  string
 }
@@ -3,15 +3,20 @@ package grpcserver
 
 import (
 	"context"
+	"errors"
 	"net"
 	"strconv"
+	"time"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/iurnickita/gophkeeper/contract/proto"
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
 	"github.com/iurnickita/gophkeeper/server/internal/auth"
 	gophTLS "github.com/iurnickita/gophkeeper/server/internal/crypto/tls"
 	"github.com/iurnickita/gophkeeper/server/internal/grpc_server/server/config"
@@ -51,7 +56,8 @@ func (s *Server) Register(ctx context.Context, in *pb.RegisterRequest) (*pb.Regi
 		case store.ErrAlreadyExists:
 			return &pb.RegisterResponse{}, status.Error(codes.AlreadyExists, err.Error())
 		// обработка неверного ввода: пустой логин, простой пароль
-		// case :
+		case auth.ErrBreachedPassword:
+			return &pb.RegisterResponse{}, status.Error(codes.InvalidArgument, err.Error())
 		default:
 			return &pb.RegisterResponse{}, status.Error(codes.Internal, err.Error())
 		}
@@ -77,14 +83,30 @@ func (s *Server) Authenticate(ctx context.Context, in *pb.AuthenticateRequest) (
 }
 
 // List
-func (s *Server) List(ctx context.Context, in *pb.Empty) (*pb.ListResponse, error) {
-	return &pb.ListResponse{}, nil
+func (s *Server) List(ctx context.Context, in *pb.ListRequest) (*pb.ListResponse, error) {
+	// Код пользователя
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return &pb.ListResponse{}, status.Error(codes.Internal, err.Error())
+	}
+
+	// Содержимое папки
+	units, folders, err := s.gophkeeper.List(ctx, userID, in.Prefix, in.Recursive)
+	if err != nil {
+		switch err {
+		case unitpath.ErrInvalidName:
+			return &pb.ListResponse{}, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return &pb.ListResponse{}, status.Error(codes.Internal, err.Error())
+		}
+	}
+	return &pb.ListResponse{Units: namesToProto(units), Folders: namesToProto(folders)}, nil
 }
 
 // Read
 func (s *Server) Read(ctx context.Context, in *pb.ReadRequest) (*pb.ReadResponse, error) {
 	// Код пользователя
-	userID, err := strconv.Atoi(ctx.Value(auth.ContextUserID).(string))
+	userID, err := getUserID(ctx)
 	if err != nil {
 		return &pb.ReadResponse{}, status.Error(codes.Internal, err.Error())
 	}
@@ -95,52 +117,416 @@ func (s *Server) Read(ctx context.Context, in *pb.ReadRequest) (*pb.ReadResponse
 		switch err {
 		case store.ErrNoRows:
 			return &pb.ReadResponse{}, status.Error(codes.NotFound, err.Error())
+		case unitpath.ErrInvalidName:
+			return &pb.ReadResponse{}, status.Error(codes.InvalidArgument, err.Error())
 		default:
 			return &pb.ReadResponse{}, status.Error(codes.Internal, err.Error())
 		}
 	}
-	return &pb.ReadResponse{Unittype: int32(unit.Meta.Type), Unitdata: unit.Data}, nil
+	return &pb.ReadResponse{
+		Unittype:    int32(unit.Meta.Type),
+		Unitdata:    unit.Data,
+		Description: unit.Meta.Description,
+		Tags:        unit.Meta.Tags,
+		Fields:      fieldsToProto(unit.Meta.Fields),
+		CreatedAt:   timestamppb.New(unit.Meta.CreatedAt),
+		UpdatedAt:   timestamppb.New(unit.Meta.UpdatedAt),
+		ExpiresAt:   expiresToProto(unit.Meta.ExpiresAt),
+		RotateEvery: rotateToProto(unit.Meta.RotateEvery),
+	}, nil
 }
 
 // Write
-func (s *Server) Write(ctx context.Context, in *pb.WriteRequest) (*pb.Empty, error) {
+func (s *Server) Write(ctx context.Context, in *pb.WriteRequest) (*pb.WriteResponse, error) {
 	// Код пользователя
-	userID, err := strconv.Atoi(ctx.Value(auth.ContextUserID).(string))
+	userID, err := getUserID(ctx)
 	if err != nil {
-		return &pb.Empty{}, status.Error(codes.Internal, err.Error())
+		return &pb.WriteResponse{}, status.Error(codes.Internal, err.Error())
 	}
 
 	// Запись новой единицы данных
 	var unit model.Unit
 	unit.Key = model.UnitKey{UserID: userID, UnitName: in.Unitname}
-	unit.Meta = model.UnitMeta{Type: int(in.Unittype)}
+	unit.Meta = model.UnitMeta{
+		Type:        int(in.Unittype),
+		Description: in.Description,
+		Tags:        in.Tags,
+		Fields:      fieldsFromProto(in.Fields)}
+	if in.ExpiresAt != nil {
+		unit.Meta.ExpiresAt = in.ExpiresAt.AsTime()
+	}
+	unit.Meta.RotateEvery = in.RotateEvery.AsDuration()
+	unit.Index = indexFromProto(in.Index)
 	unit.Data = in.Unitdata
-	err = s.gophkeeper.Write(ctx, unit)
+	unit, err = s.gophkeeper.Write(ctx, unit)
 	if err != nil {
 		switch err {
 		case store.ErrAlreadyExists:
-			return &pb.Empty{}, status.Error(codes.AlreadyExists, err.Error())
+			return &pb.WriteResponse{}, status.Error(codes.AlreadyExists, err.Error())
+		case unitpath.ErrInvalidName:
+			return &pb.WriteResponse{}, status.Error(codes.InvalidArgument, err.Error())
 		default:
-			return &pb.Empty{}, status.Error(codes.Internal, err.Error())
+			return &pb.WriteResponse{}, status.Error(codes.Internal, err.Error())
 		}
 	}
-	return &pb.Empty{}, err
+	return &pb.WriteResponse{
+		CreatedAt: timestamppb.New(unit.Meta.CreatedAt),
+		UpdatedAt: timestamppb.New(unit.Meta.UpdatedAt)}, nil
 }
 
 // Delete
 func (s *Server) Delete(ctx context.Context, in *pb.DeleteRequest) (*pb.Empty, error) {
-	panic("unimplemented")
+	// Код пользователя
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return &pb.Empty{}, status.Error(codes.Internal, err.Error())
+	}
+
+	// Перемещение в корзину
+	err = s.gophkeeper.Delete(ctx, userID, in.Unitname)
+	if err != nil {
+		switch err {
+		case store.ErrNoRows:
+			return &pb.Empty{}, status.Error(codes.NotFound, err.Error())
+		case unitpath.ErrInvalidName:
+			return &pb.Empty{}, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return &pb.Empty{}, status.Error(codes.Internal, err.Error())
+		}
+	}
+	return &pb.Empty{}, nil
+}
+
+// ListTrash
+func (s *Server) ListTrash(ctx context.Context, in *pb.Empty) (*pb.ListTrashResponse, error) {
+	// Код пользователя
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return &pb.ListTrashResponse{}, status.Error(codes.Internal, err.Error())
+	}
+
+	// Содержимое корзины
+	items, err := s.gophkeeper.ListTrash(ctx, userID)
+	if err != nil {
+		return &pb.ListTrashResponse{}, status.Error(codes.Internal, err.Error())
+	}
+	var resp pb.ListTrashResponse
+	for _, item := range items {
+		resp.Items = append(resp.Items, &pb.TrashItem{
+			Id:        item.ID,
+			Name:      &pb.NameRef{Token: item.Name.Token, Enc: item.Name.Enc},
+			Unittype:  int32(item.Type),
+			DeletedAt: timestamppb.New(item.DeletedAt),
+		})
+	}
+	return &resp, nil
+}
+
+// Restore
+func (s *Server) Restore(ctx context.Context, in *pb.RestoreRequest) (*pb.Empty, error) {
+	// Код пользователя
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return &pb.Empty{}, status.Error(codes.Internal, err.Error())
+	}
+
+	// Восстановление из корзины
+	err = s.gophkeeper.Restore(ctx, userID, in.Id)
+	if err != nil {
+		switch err {
+		case store.ErrNoRows:
+			return &pb.Empty{}, status.Error(codes.NotFound, err.Error())
+		case store.ErrAlreadyExists:
+			return &pb.Empty{}, status.Error(codes.AlreadyExists, err.Error())
+		default:
+			return &pb.Empty{}, status.Error(codes.Internal, err.Error())
+		}
+	}
+	return &pb.Empty{}, nil
+}
+
+// Purge
+func (s *Server) Purge(ctx context.Context, in *pb.PurgeRequest) (*pb.PurgeResponse, error) {
+	// Код пользователя
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return &pb.PurgeResponse{}, status.Error(codes.Internal, err.Error())
+	}
+
+	// Окончательное удаление
+	purged, err := s.gophkeeper.Purge(ctx, userID, in.Ids)
+	if err != nil {
+		return &pb.PurgeResponse{}, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.PurgeResponse{Purged: purged}, nil
+}
+
+// Move
+func (s *Server) Move(ctx context.Context, in *pb.MoveRequest) (*pb.Empty, error) {
+	// Код пользователя
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return &pb.Empty{}, status.Error(codes.Internal, err.Error())
+	}
+
+	// Перемещение единицы данных или папки
+	err = s.gophkeeper.Move(ctx, userID, in.FromFolder, renamesFromProto(in.Units), renamesFromProto(in.Folders))
+	if err != nil {
+		switch err {
+		case store.ErrNoRows:
+			return &pb.Empty{}, status.Error(codes.NotFound, err.Error())
+		case store.ErrAlreadyExists:
+			return &pb.Empty{}, status.Error(codes.AlreadyExists, err.Error())
+		case store.ErrConflict:
+			return &pb.Empty{}, status.Error(codes.FailedPrecondition, err.Error())
+		case unitpath.ErrInvalidName:
+			return &pb.Empty{}, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return &pb.Empty{}, status.Error(codes.Internal, err.Error())
+		}
+	}
+	return &pb.Empty{}, nil
+}
+
+// Rename - перемещение в пределах родительской папки, новые токены рассчитывает клиент
+func (s *Server) Rename(ctx context.Context, in *pb.MoveRequest) (*pb.Empty, error) {
+	return s.Move(ctx, in)
+}
+
+// Mkdir
+func (s *Server) Mkdir(ctx context.Context, in *pb.MkdirRequest) (*pb.Empty, error) {
+	// Код пользователя
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return &pb.Empty{}, status.Error(codes.Internal, err.Error())
+	}
+
+	// Создание папки
+	err = s.gophkeeper.Mkdir(ctx, userID, nameFromProto(in.Folder), namesFromProto(in.Parents))
+	if err != nil {
+		switch err {
+		case unitpath.ErrInvalidName:
+			return &pb.Empty{}, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return &pb.Empty{}, status.Error(codes.Internal, err.Error())
+		}
+	}
+	return &pb.Empty{}, nil
+}
+
+// Search
+func (s *Server) Search(ctx context.Context, in *pb.SearchRequest) (*pb.SearchResponse, error) {
+	// Код пользователя
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return &pb.SearchResponse{}, status.Error(codes.Internal, err.Error())
+	}
+
+	// Поиск
+	query := model.SearchQuery{
+		NGrams:    in.NameNgrams,
+		Type:      int(in.Unittype),
+		TagTokens: in.TagTokens,
+		Fields:    fieldsFromProto(in.Fields)}
+	units, err := s.gophkeeper.Search(ctx, userID, query)
+	if err != nil {
+		return &pb.SearchResponse{}, status.Error(codes.Internal, err.Error())
+	}
+	return unitsToProto(units), nil
+}
+
+// Due
+func (s *Server) Due(ctx context.Context, in *pb.DueRequest) (*pb.SearchResponse, error) {
+	// Код пользователя
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return &pb.SearchResponse{}, status.Error(codes.Internal, err.Error())
+	}
+
+	// Просроченные и требующие смены
+	units, err := s.gophkeeper.Due(ctx, userID, in.Within.AsDuration())
+	if err != nil {
+		return &pb.SearchResponse{}, status.Error(codes.Internal, err.Error())
+	}
+	return unitsToProto(units), nil
+}
+
+// Sync
+func (s *Server) Sync(ctx context.Context, in *pb.SyncRequest) (*pb.SyncResponse, error) {
+	// Код пользователя
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return &pb.SyncResponse{}, status.Error(codes.Internal, err.Error())
+	}
+
+	// Изменения после курсора
+	changes, err := s.gophkeeper.Sync(ctx, userID, in.SinceCursor)
+	if err != nil {
+		return &pb.SyncResponse{}, status.Error(codes.Internal, err.Error())
+	}
+	resp := pb.SyncResponse{Cursor: changes.Cursor, Full: changes.Full, Deleted: changes.Deleted}
+	for _, unit := range changes.Units {
+		resp.Units = append(resp.Units, &pb.SyncUnit{Info: unitToProto(unit), Unitdata: unit.Data})
+	}
+	return &resp, nil
+}
+
+// Watch передает клиенту события об изменении его данных до отключения.
+// Первым отправляется событие SUBSCRIBED: изменения после него не будут пропущены
+func (s *Server) Watch(in *pb.Empty, stream pb.Gophkeeper_WatchServer) error {
+	// Код пользователя
+	userID, err := getUserID(stream.Context())
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	// Подписка
+	events, cancel := s.gophkeeper.Watch(userID)
+	defer cancel()
+	err = stream.Send(&pb.WatchEvent{Kind: pb.WatchEvent_SUBSCRIBED})
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "subscriber is too slow, resubscribe")
+			}
+			err := stream.Send(&pb.WatchEvent{
+				Kind:     pb.WatchEvent_Kind(event.Kind),
+				Unitname: event.UnitName,
+				NameEnc:  event.NameEnc,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// unitsToProto маппинг метаданных единиц данных в grpc
+func unitsToProto(units []model.Unit) *pb.SearchResponse {
+	var resp pb.SearchResponse
+	for _, unit := range units {
+		resp.Units = append(resp.Units, unitToProto(unit))
+	}
+	return &resp
+}
+
+// unitToProto маппинг метаданных единицы данных в grpc
+func unitToProto(unit model.Unit) *pb.UnitInfo {
+	return &pb.UnitInfo{
+		Unitname:    unit.Key.UnitName,
+		Unittype:    int32(unit.Meta.Type),
+		Description: unit.Meta.Description,
+		Tags:        unit.Meta.Tags,
+		Fields:      fieldsToProto(unit.Meta.Fields),
+		CreatedAt:   timestamppb.New(unit.Meta.CreatedAt),
+		UpdatedAt:   timestamppb.New(unit.Meta.UpdatedAt),
+		NameEnc:     unit.Index.NameEnc,
+		ExpiresAt:   expiresToProto(unit.Meta.ExpiresAt),
+		RotateEvery: rotateToProto(unit.Meta.RotateEvery),
+	}
+}
+
+// expiresToProto маппинг срока действия в grpc. Бессрочные передаются без значения
+func expiresToProto(expiresAt time.Time) *timestamppb.Timestamp {
+	if expiresAt.IsZero() {
+		return nil
+	}
+	return timestamppb.New(expiresAt)
+}
+
+// rotateToProto маппинг периодичности смены в grpc
+func rotateToProto(rotateEvery time.Duration) *durationpb.Duration {
+	if rotateEvery == 0 {
+		return nil
+	}
+	return durationpb.New(rotateEvery)
+}
+
+// fieldsToProto маппинг пользовательских полей в grpc
+func fieldsToProto(fields []model.Field) []*pb.Field {
+	var pbFields []*pb.Field
+	for _, field := range fields {
+		pbFields = append(pbFields, &pb.Field{Key: field.Key, Value: field.Value, Sensitive: field.Sensitive})
+	}
+	return pbFields
+}
+
+// fieldsFromProto маппинг пользовательских полей из grpc
+func fieldsFromProto(pbFields []*pb.Field) []model.Field {
+	var fields []model.Field
+	for _, field := range pbFields {
+		fields = append(fields, model.Field{Key: field.Key, Value: field.Value, Sensitive: field.Sensitive})
+	}
+	return fields
+}
+
+// nameFromProto маппинг имени слепого индекса из grpc
+func nameFromProto(name *pb.NameRef) model.NameRef {
+	return model.NameRef{Token: name.GetToken(), Enc: name.GetEnc()}
+}
+
+// namesFromProto маппинг имен слепого индекса из grpc
+func namesFromProto(pbNames []*pb.NameRef) []model.NameRef {
+	var names []model.NameRef
+	for _, name := range pbNames {
+		names = append(names, nameFromProto(name))
+	}
+	return names
+}
+
+// namesToProto маппинг имен слепого индекса в grpc
+func namesToProto(names []model.NameRef) []*pb.NameRef {
+	var pbNames []*pb.NameRef
+	for _, name := range names {
+		pbNames = append(pbNames, &pb.NameRef{Token: name.Token, Enc: name.Enc})
+	}
+	return pbNames
+}
+
+// indexFromProto маппинг слепого индекса из grpc
+func indexFromProto(index *pb.BlindIndex) model.BlindIndex {
+	return model.BlindIndex{
+		NameEnc:   index.GetNameEnc(),
+		NGrams:    index.GetNameNgrams(),
+		Folders:   namesFromProto(index.GetFolders()),
+		TagTokens: index.GetTagTokens(),
+	}
+}
+
+// renamesFromProto маппинг переименований из grpc
+func renamesFromProto(pbRenames []*pb.Rename) []model.Rename {
+	var renames []model.Rename
+	for _, rename := range pbRenames {
+		renames = append(renames, model.Rename{From: rename.From, To: rename.To, Index: indexFromProto(rename.Index)})
+	}
+	return renames
+}
+
+// getUserID возвращает код пользователя, записанный в контекст при аутентификации
+func getUserID(ctx context.Context) (int, error) {
+	userID, ok := ctx.Value(auth.ContextUserID).(string)
+	if !ok {
+		return 0, errors.New("user id not found in context")
+	}
+	return strconv.Atoi(userID)
 }
 
 // Serve - запуск сервера
 func Serve(cfg config.Config, auth auth.Auth, gophkeeper service.Service, zaplog *zap.Logger) error {
 	// определяем порт для сервера
-	listen, err := net.Listen("tcp", ":3200")
+	listen, err := net.Listen("tcp", cfg.Address)
 	if err != nil {
 		return err
 	}
 	// tls
-	tlsCredentials, err := gophTLS.LoadTLSCredentials()
+	tlsCredentials, err := gophTLS.LoadTLSCredentials(cfg.TLSCertFile, cfg.TLSKeyFile)
 	if err != nil {
 		return err
 	}
@@ -148,7 +534,8 @@ func Serve(cfg config.Config, auth auth.Auth, gophkeeper service.Service, zaplog
 	// создаём gRPC-сервер
 	s := grpc.NewServer(
 		grpc.Creds(tlsCredentials),
-		grpc.UnaryInterceptor(auth.AuthUnaryInterceptor))
+		grpc.UnaryInterceptor(auth.AuthUnaryInterceptor),
+		grpc.StreamInterceptor(auth.AuthStreamInterceptor))
 	// создание обработчика
 	h := NewServer(cfg, auth, gophkeeper, zaplog)
 	// регистрируем сервис
@@ -2,27 +2,147 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 )
 
 // Unit - модель единицы данных
 type Unit struct {
-	Key  UnitKey
-	Meta UnitMeta
-	Data []byte
+	Key   UnitKey
+	Meta  UnitMeta
+	Index BlindIndex
+	Data  []byte
 }
 
-// UnitKey - ключ единицы данных
+// UnitKey - ключ единицы данных.
+// UnitName - токен слепого индекса имени, открытое имя серверу неизвестно
 type UnitKey struct {
 	UserID   int
 	UnitName string
 }
 
+// NameRef - имя в слепом индексе: токен и зашифрованное клиентом значение
+type NameRef struct {
+	Token string `json:"token"`
+	Enc   []byte `json:"enc"`
+}
+
+// BlindIndex - слепой индекс единицы данных, рассчитанный клиентом
+type BlindIndex struct {
+	// Зашифрованное полное имя
+	NameEnc []byte
+	// Токены n-грамм имени для поиска по подстроке
+	NGrams []string
+	// Родительские папки, начиная с верхней
+	Folders []NameRef
+	// Токены тегов
+	TagTokens []string
+}
+
+// Entry - элемент списка: имя и цепочка родительских папок
+type Entry struct {
+	Name    NameRef
+	Folders []NameRef
+}
+
+// Rename - переименование единицы данных или папки с новым слепым индексом
+type Rename struct {
+	From  string
+	To    string
+	Index BlindIndex
+}
+
 // UnitMeta - метаданные единицы данных
 type UnitMeta struct {
-	Type       int
-	DataSK     string
-	UploadedAt time.Time
+	Type        int
+	DataSK      string
+	UploadedAt  time.Time
+	Description string
+	// Теги, зашифрованные клиентом
+	Tags      []string
+	Fields    []Field
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// Срок действия. Нулевое значение - бессрочно
+	ExpiresAt time.Time
+	// Периодичность смены от даты последнего изменения. 0 - без напоминаний
+	RotateEvery time.Duration
+}
+
+// Field - пользовательское поле единицы данных.
+// Значение чувствительного поля хранится в зашифрованном виде
+type Field struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+// SearchQuery - условия поиска единиц данных. Пустые условия не ограничивают выборку
+type SearchQuery struct {
+	// Токены n-грамм подстроки имени
+	NGrams []string
+	// Тип единицы данных
+	Type int
+	// Токены тегов
+	TagTokens []string
+	// Поля по ключу; при непустом значении - и по значению (только нечувствительные поля)
+	Fields []Field
+}
+
+// Changes - изменения единиц данных пользователя после курсора синхронизации
+type Changes struct {
+	// Номер последнего изменения
+	Cursor int64
+	// Полный снимок: Units содержит все данные пользователя
+	Full bool
+	// Созданные и измененные единицы данных
+	Units []Unit
+	// Токены имен удаленных (в том числе перемещенных) единиц данных
+	Deleted []string
+}
+
+// EventKind - вид изменения единицы данных
+type EventKind int
+
+const (
+	EventCreated EventKind = 1
+	EventUpdated EventKind = 2
+	EventDeleted EventKind = 3
+)
+
+// Event - событие об изменении единицы данных пользователя
+type Event struct {
+	UserID int       `json:"userid"`
+	Kind   EventKind `json:"kind"`
+	// Токен имени
+	UnitName string `json:"unitname"`
+	// Зашифрованное клиентом имя (пусто для удаления)
+	NameEnc []byte `json:"nameenc,omitempty"`
+}
+
+// Dump - согласованный снимок таблиц хранилища для резервного копирования
+type Dump struct {
+	Auth         []DumpRow
+	EncryptionSK []DumpRow
+	Folders      []DumpRow
+	DataUnits    []DumpRow
+}
+
+// DumpRow - строка таблицы снимка: JSON со всеми столбцами, кроме данных единицы данных
+type DumpRow struct {
+	// Пользователь строки (0 - строка не относится к пользователю)
+	UserID int
+	Row    json.RawMessage
+	// Зашифрованные данные единицы данных
+	Data []byte
+}
+
+// TrashItem - единица данных в корзине
+type TrashItem struct {
+	ID        int64
+	Name      NameRef
+	Type      int
+	DeletedAt time.Time
 }
 
 const (
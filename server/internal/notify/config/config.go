@@ -0,0 +1,8 @@
+package config
+
+type Config struct {
+	// Транспорт событий: local - в пределах процесса, postgres - LISTEN/NOTIFY между экземплярами
+	Backend string
+	// Строка подключения к БД для postgres
+	DBDsn string
+}
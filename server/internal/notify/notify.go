@@ -0,0 +1,116 @@
+// Пакет notify. Рассылка событий об изменении данных подписчикам
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/iurnickita/gophkeeper/server/internal/model"
+	"github.com/iurnickita/gophkeeper/server/internal/notify/config"
+	"go.uber.org/zap"
+)
+
+// Broker интерфейс рассылки событий
+type Broker interface {
+	// Publish отправляет событие подписчикам пользователя
+	Publish(ctx context.Context, event model.Event) error
+	// Subscribe возвращает канал событий пользователя и функцию отписки.
+	// Канал закрывается при отписке или если подписчик не успевает читать события
+	Subscribe(userID int) (<-chan model.Event, func())
+	Close() error
+}
+
+// SubscriberBuffer - емкость канала подписчика
+const SubscriberBuffer = 64
+
+var (
+	ErrUnknownBackend = errors.New("unknown notify backend")
+)
+
+// localBroker рассылка в пределах процесса
+type localBroker struct {
+	mux  sync.Mutex
+	subs map[int]map[chan model.Event]struct{}
+}
+
+// Publish implements Broker.
+func (b *localBroker) Publish(ctx context.Context, event model.Event) error {
+	b.dispatch(event)
+	return nil
+}
+
+// dispatch доставляет событие подписчикам пользователя.
+// Отстающий подписчик отключается, чтобы не блокировать остальных
+func (b *localBroker) dispatch(event model.Event) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	for ch := range b.subs[event.UserID] {
+		select {
+		case ch <- event:
+		default:
+			b.remove(event.UserID, ch)
+		}
+	}
+}
+
+// Subscribe implements Broker.
+func (b *localBroker) Subscribe(userID int) (<-chan model.Event, func()) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	ch := make(chan model.Event, SubscriberBuffer)
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[chan model.Event]struct{})
+	}
+	b.subs[userID][ch] = struct{}{}
+
+	cancel := func() {
+		b.mux.Lock()
+		defer b.mux.Unlock()
+		b.remove(userID, ch)
+	}
+	return ch, cancel
+}
+
+// remove отписывает канал. Вызывается под блокировкой
+func (b *localBroker) remove(userID int, ch chan model.Event) {
+	if _, ok := b.subs[userID][ch]; !ok {
+		return
+	}
+	delete(b.subs[userID], ch)
+	close(ch)
+	if len(b.subs[userID]) == 0 {
+		delete(b.subs, userID)
+	}
+}
+
+// Close implements Broker.
+func (b *localBroker) Close() error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	for userID, chans := range b.subs {
+		for ch := range chans {
+			b.remove(userID, ch)
+		}
+	}
+	return nil
+}
+
+// newLocalBroker создает рассылку в пределах процесса
+func newLocalBroker() *localBroker {
+	return &localBroker{subs: make(map[int]map[chan model.Event]struct{})}
+}
+
+// NewBroker создает рассылку событий согласно конфигурации
+func NewBroker(cfg config.Config, zaplog *zap.Logger) (Broker, error) {
+	switch cfg.Backend {
+	case "", "local":
+		return newLocalBroker(), nil
+	case "postgres":
+		return newPgBroker(cfg.DBDsn, zaplog)
+	default:
+		return nil, ErrUnknownBackend
+	}
+}
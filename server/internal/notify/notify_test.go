@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iurnickita/gophkeeper/server/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalBroker(t *testing.T) {
+	broker := newLocalBroker()
+	events, cancel := broker.Subscribe(1)
+	other, cancelOther := broker.Subscribe(2)
+	defer cancelOther()
+
+	event := model.Event{UserID: 1, Kind: model.EventCreated, UnitName: "token"}
+	require.NoError(t, broker.Publish(context.Background(), event))
+	require.Equal(t, event, <-events)
+	require.Empty(t, other)
+
+	// Отписка закрывает канал
+	cancel()
+	_, ok := <-events
+	require.False(t, ok)
+	cancel()
+}
+
+func TestLocalBroker_SlowSubscriber(t *testing.T) {
+	broker := newLocalBroker()
+	events, cancel := broker.Subscribe(1)
+	defer cancel()
+
+	for range SubscriberBuffer + 1 {
+		require.NoError(t, broker.Publish(context.Background(), model.Event{UserID: 1, Kind: model.EventUpdated}))
+	}
+	// Отстающий подписчик отключен: после буфера канал закрыт
+	for range SubscriberBuffer {
+		<-events
+	}
+	_, ok := <-events
+	require.False(t, ok)
+}
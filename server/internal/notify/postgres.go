@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/server/internal/model"
+	"github.com/jackc/pgx/v5"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+)
+
+// pgChannel - канал LISTEN/NOTIFY
+const pgChannel = "gophkeeper_units"
+
+// Задержка переподключения слушателя
+const (
+	pgMinBackoff = time.Second
+	pgMaxBackoff = 30 * time.Second
+)
+
+// pgBroker рассылка между экземплярами сервера через LISTEN/NOTIFY.
+// Событие публикуется в БД и доставляется локальным подписчикам каждого экземпляра,
+// в том числе отправившего
+type pgBroker struct {
+	*localBroker
+	database *sql.DB
+	dsn      string
+	cancel   context.CancelFunc
+	done     chan struct{}
+	zaplog   *zap.Logger
+}
+
+// Publish implements Broker.
+func (b *pgBroker) Publish(ctx context.Context, event model.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = b.database.ExecContext(ctx, "SELECT pg_notify($1, $2)", pgChannel, string(payload))
+	return err
+}
+
+// listen принимает уведомления и переподключается при обрыве соединения
+func (b *pgBroker) listen(ctx context.Context) {
+	defer close(b.done)
+	backoff := pgMinBackoff
+	for {
+		err := b.listenConn(ctx, func() { backoff = pgMinBackoff })
+		if ctx.Err() != nil {
+			return
+		}
+		b.zaplog.Sugar().Errorf("notify listener: %s, reconnect in %s", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, pgMaxBackoff)
+	}
+}
+
+// listenConn слушает канал на одном соединении до ошибки
+func (b *pgBroker) listenConn(ctx context.Context, connected func()) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgChannel)
+	if err != nil {
+		return err
+	}
+	connected()
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var event model.Event
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			b.zaplog.Sugar().Errorf("notify payload: %s", err)
+			continue
+		}
+		b.dispatch(event)
+	}
+}
+
+// Close implements Broker.
+func (b *pgBroker) Close() error {
+	b.cancel()
+	<-b.done
+	b.localBroker.Close()
+	return b.database.Close()
+}
+
+// newPgBroker создает рассылку через LISTEN/NOTIFY и запускает слушателя
+func newPgBroker(dsn string, zaplog *zap.Logger) (*pgBroker, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &pgBroker{
+		localBroker: newLocalBroker(),
+		database:    db,
+		dsn:         dsn,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+		zaplog:      zaplog,
+	}
+	go b.listen(ctx)
+	return b, nil
+}
@@ -1,4 +1,8 @@
 package config
 
 type Config struct {
+	// Срок хранения удаленных единиц данных в корзине. 0 - без автоматической очистки
+	TrashRetentionD int
+	// Интервал запуска очистки корзины
+	TrashPurgeIntervalM int
 }
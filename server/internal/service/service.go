@@ -3,9 +3,13 @@ package service
 
 import (
 	"context"
+	"slices"
+	"time"
 
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
 	"github.com/iurnickita/gophkeeper/server/internal/crypto/aesgcm"
 	"github.com/iurnickita/gophkeeper/server/internal/model"
+	"github.com/iurnickita/gophkeeper/server/internal/notify"
 	"github.com/iurnickita/gophkeeper/server/internal/service/config"
 	"github.com/iurnickita/gophkeeper/server/internal/store"
 	"go.uber.org/zap"
@@ -13,10 +17,19 @@ import (
 
 // Service интерфейс сервиса
 type Service interface {
-	List(ctx context.Context, userID int) ([]string, error)
+	List(ctx context.Context, userID int, folder string, recursive bool) ([]model.NameRef, []model.NameRef, error)
 	Read(ctx context.Context, userID int, unitName string) (model.Unit, error)
-	Write(ctx context.Context, unit model.Unit) error
+	Write(ctx context.Context, unit model.Unit) (model.Unit, error)
 	Delete(ctx context.Context, userID int, unitName string) error
+	Move(ctx context.Context, userID int, fromFolder string, units []model.Rename, folders []model.Rename) error
+	Mkdir(ctx context.Context, userID int, folder model.NameRef, parents []model.NameRef) error
+	Search(ctx context.Context, userID int, query model.SearchQuery) ([]model.Unit, error)
+	Due(ctx context.Context, userID int, within time.Duration) ([]model.Unit, error)
+	Sync(ctx context.Context, userID int, since int64) (model.Changes, error)
+	ListTrash(ctx context.Context, userID int) ([]model.TrashItem, error)
+	Restore(ctx context.Context, userID int, id int64) error
+	Purge(ctx context.Context, userID int, ids []int64) (int64, error)
+	Watch(userID int) (<-chan model.Event, func())
 }
 
 // service реализация сервиса
@@ -24,12 +37,52 @@ type service struct {
 	cfg     config.Config
 	store   store.Store
 	crypter aesgcm.Crypter
+	broker  notify.Broker
 	zaplog  *zap.Logger
 }
 
-// List implements Service.
-func (s service) List(ctx context.Context, userID int) ([]string, error) {
-	return s.store.List(ctx, userID)
+// List возвращает содержимое папки: единицы данных и подпапки (в том числе неявные).
+// folder - токен папки, пустой - корень. Без recursive - только непосредственное содержимое
+func (s service) List(ctx context.Context, userID int, folder string, recursive bool) ([]model.NameRef, []model.NameRef, error) {
+	units, folders, err := s.store.List(ctx, userID, folder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resUnits, resFolders []model.NameRef
+	seen := make(map[string]bool)
+	// addChain добавляет папки из цепочки, лежащие внутри folder
+	addChain := func(chain []model.NameRef) {
+		pos := slices.IndexFunc(chain, func(f model.NameRef) bool { return f.Token == folder })
+		if folder != "" && pos == -1 {
+			return
+		}
+		rest := chain[pos+1:]
+		if !recursive && len(rest) > 0 {
+			rest = rest[:1]
+		}
+		for _, f := range rest {
+			if !seen[f.Token] {
+				seen[f.Token] = true
+				resFolders = append(resFolders, f)
+			}
+		}
+	}
+
+	for _, unit := range units {
+		addChain(unit.Folders)
+		parent := ""
+		if len(unit.Folders) > 0 {
+			parent = unit.Folders[len(unit.Folders)-1].Token
+		}
+		if recursive || parent == folder {
+			resUnits = append(resUnits, unit.Name)
+		}
+	}
+	for _, f := range folders {
+		addChain(append(slices.Clone(f.Folders), f.Name))
+	}
+	return resUnits, resFolders, nil
 }
 
 // Read читает единицу данных
@@ -37,6 +90,10 @@ func (s service) Read(ctx context.Context, userID int, unitName string) (model.U
 	s.zaplog.Sugar().Debug("inbound unitname")
 	s.zaplog.Sugar().Debug(unitName)
 
+	if unitName == "" {
+		return model.Unit{}, unitpath.ErrInvalidName
+	}
+
 	// Чтение
 	unit, err := s.store.Read(ctx, userID, unitName)
 	if err != nil {
@@ -57,39 +114,201 @@ func (s service) Read(ctx context.Context, userID int, unitName string) (model.U
 	return decrUnit, nil
 }
 
-// Write записывает новую единицу данных
-func (s service) Write(ctx context.Context, unit model.Unit) error {
+// Write записывает единицу данных.
+// Возвращает ее с датами создания и изменения на сервере
+func (s service) Write(ctx context.Context, unit model.Unit) (model.Unit, error) {
 	s.zaplog.Sugar().Debug("inbound unit")
 	s.zaplog.Sugar().Debug(unit)
 
+	// Проверка имени
+	if unit.Key.UnitName == "" {
+		return model.Unit{}, unitpath.ErrInvalidName
+	}
+
 	// Шифрование
 	encrUnit, err := s.crypter.UnitEncrypt(unit)
 	if err != nil {
-		return err
+		return model.Unit{}, err
 	}
 	s.zaplog.Sugar().Debug("encrypted unit")
 	s.zaplog.Sugar().Debug(encrUnit)
 
 	// Запись
-	err = s.store.Write(ctx, encrUnit)
+	written, created, err := s.store.Write(ctx, encrUnit)
+	if err != nil {
+		return model.Unit{}, err
+	}
+	unit.Meta.CreatedAt, unit.Meta.UpdatedAt = written.Meta.CreatedAt, written.Meta.UpdatedAt
+
+	// Уведомление подписчиков
+	kind := model.EventUpdated
+	if created {
+		kind = model.EventCreated
+	}
+	s.publish(ctx, model.Event{UserID: unit.Key.UserID, Kind: kind, UnitName: unit.Key.UnitName, NameEnc: unit.Index.NameEnc})
+	return unit, nil
+}
+
+// Delete перемещает единицу данных в корзину
+// Ошибки: unitpath.ErrInvalidName, store.ErrNoRows
+func (s service) Delete(ctx context.Context, userID int, unitName string) error {
+	if unitName == "" {
+		return unitpath.ErrInvalidName
+	}
+	err := s.store.Delete(ctx, userID, unitName)
 	if err != nil {
 		return err
 	}
+	s.publish(ctx, model.Event{UserID: userID, Kind: model.EventDeleted, UnitName: unitName})
 	return nil
 }
 
-// Delete implements Service.
-func (s service) Delete(ctx context.Context, userID int, unitName string) error {
-	panic("unimplemented")
+// ListTrash возвращает содержимое корзины
+func (s service) ListTrash(ctx context.Context, userID int) ([]model.TrashItem, error) {
+	return s.store.ListTrash(ctx, userID)
+}
+
+// Restore возвращает единицу данных из корзины
+// Ошибки: store.ErrNoRows, store.ErrAlreadyExists
+func (s service) Restore(ctx context.Context, userID int, id int64) error {
+	name, err := s.store.Restore(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	s.publish(ctx, model.Event{UserID: userID, Kind: model.EventCreated, UnitName: name.Token, NameEnc: name.Enc})
+	return nil
+}
+
+// Purge окончательно удаляет единицы данных из корзины. Пустой ids - вся корзина
+func (s service) Purge(ctx context.Context, userID int, ids []int64) (int64, error) {
+	return s.store.Purge(ctx, userID, ids)
+}
+
+// purgeExpired периодически удаляет единицы данных, срок хранения которых в корзине истек
+func (s service) purgeExpired(retention time.Duration, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		purged, err := s.store.PurgeExpired(context.Background(), time.Now().Add(-retention))
+		if err != nil {
+			s.zaplog.Error("trash purge: " + err.Error())
+		} else if purged > 0 {
+			s.zaplog.Sugar().Infof("trash purge: %d units removed", purged)
+		}
+		<-ticker.C
+	}
+}
+
+// Move переименовывает единицы данных и папки одной транзакцией.
+// Новые имена и слепой индекс рассчитывает клиент; fromFolder - токен перемещаемой папки
+// Ошибки: unitpath.ErrInvalidName, store.ErrNoRows, store.ErrAlreadyExists, store.ErrConflict
+func (s service) Move(ctx context.Context, userID int, fromFolder string, units []model.Rename, folders []model.Rename) error {
+	for _, rename := range slices.Concat(units, folders) {
+		if rename.From == "" || rename.To == "" {
+			return unitpath.ErrInvalidName
+		}
+	}
+	err := s.store.Move(ctx, userID, fromFolder, units, folders)
+	if err != nil {
+		return err
+	}
+	for _, unit := range units {
+		s.publish(ctx, model.Event{UserID: userID, Kind: model.EventDeleted, UnitName: unit.From})
+		s.publish(ctx, model.Event{UserID: userID, Kind: model.EventCreated, UnitName: unit.To, NameEnc: unit.Index.NameEnc})
+	}
+	return nil
+}
+
+// Mkdir создает папку
+// Ошибки: unitpath.ErrInvalidName
+func (s service) Mkdir(ctx context.Context, userID int, folder model.NameRef, parents []model.NameRef) error {
+	if folder.Token == "" {
+		return unitpath.ErrInvalidName
+	}
+	return s.store.Mkdir(ctx, userID, folder, parents)
+}
+
+// Search ищет единицы данных по имени, типу, тегам и полям.
+// Значения чувствительных полей в результатах не раскрываются
+func (s service) Search(ctx context.Context, userID int, query model.SearchQuery) ([]model.Unit, error) {
+	units, err := s.store.Search(ctx, userID, query)
+	if err != nil {
+		return nil, err
+	}
+	hideSensitive(units)
+	return units, nil
+}
+
+// Due возвращает единицы данных, просроченные или требующие смены
+// в течение within от текущего момента
+func (s service) Due(ctx context.Context, userID int, within time.Duration) ([]model.Unit, error) {
+	if within < 0 {
+		within = 0
+	}
+	units, err := s.store.Due(ctx, userID, time.Now().Add(within))
+	if err != nil {
+		return nil, err
+	}
+	hideSensitive(units)
+	return units, nil
+}
+
+// Sync возвращает изменения после курсора since с расшифрованными данными
+func (s service) Sync(ctx context.Context, userID int, since int64) (model.Changes, error) {
+	changes, err := s.store.Sync(ctx, userID, since)
+	if err != nil {
+		return model.Changes{}, err
+	}
+	for i, unit := range changes.Units {
+		changes.Units[i], err = s.crypter.UnitDecrypt(unit)
+		if err != nil {
+			return model.Changes{}, err
+		}
+	}
+	return changes, nil
+}
+
+// Watch подписывает на события об изменении данных пользователя
+func (s service) Watch(userID int) (<-chan model.Event, func()) {
+	return s.broker.Subscribe(userID)
+}
+
+// publish отправляет событие подписчикам. Ошибка рассылки не отменяет изменение
+func (s service) publish(ctx context.Context, event model.Event) {
+	err := s.broker.Publish(ctx, event)
+	if err != nil {
+		s.zaplog.Error("publish event: " + err.Error())
+	}
+}
+
+// hideSensitive скрывает значения чувствительных полей в метаданных
+func hideSensitive(units []model.Unit) {
+	for _, unit := range units {
+		for i, field := range unit.Meta.Fields {
+			if field.Sensitive {
+				unit.Meta.Fields[i].Value = ""
+			}
+		}
+	}
 }
 
 // NewService создает объект сервиса
-func NewService(cfg config.Config, store store.Store, crypter aesgcm.Crypter, zaplog *zap.Logger) (Service, error) {
+func NewService(cfg config.Config, store store.Store, crypter aesgcm.Crypter, broker notify.Broker, zaplog *zap.Logger) (Service, error) {
 	service := service{
 		cfg:     cfg,
 		store:   store,
 		crypter: crypter,
+		broker:  broker,
 		zaplog:  zaplog}
 
+	// Автоматическая очистка корзины
+	if cfg.TrashRetentionD > 0 {
+		interval := time.Duration(cfg.TrashPurgeIntervalM) * time.Minute
+		if interval <= 0 {
+			interval = time.Hour
+		}
+		go service.purgeExpired(time.Duration(cfg.TrashRetentionD)*24*time.Hour, interval)
+	}
+
 	return &service, nil
 }
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/contract/unitpath"
+	"github.com/iurnickita/gophkeeper/server/internal/model"
+	"github.com/iurnickita/gophkeeper/server/internal/notify"
+	"github.com/iurnickita/gophkeeper/server/internal/store"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// trashStore - хранилище корзины в памяти. Не реализованные методы паникуют
+type trashStore struct {
+	store.Store
+	units []model.Unit
+	trash []model.TrashItem
+	// Аргументы PurgeExpired
+	expired chan time.Time
+}
+
+func (s *trashStore) Delete(ctx context.Context, userID int, unitName string) error {
+	for i, unit := range s.units {
+		if unit.Key.UnitName == unitName {
+			s.units = append(s.units[:i], s.units[i+1:]...)
+			s.trash = append(s.trash, model.TrashItem{ID: int64(len(s.trash) + 1), Name: model.NameRef{Token: unitName, Enc: unit.Index.NameEnc}})
+			return nil
+		}
+	}
+	return store.ErrNoRows
+}
+
+func (s *trashStore) ListTrash(ctx context.Context, userID int) ([]model.TrashItem, error) {
+	return s.trash, nil
+}
+
+func (s *trashStore) Restore(ctx context.Context, userID int, id int64) (model.NameRef, error) {
+	for i, item := range s.trash {
+		if item.ID == id {
+			s.trash = append(s.trash[:i], s.trash[i+1:]...)
+			s.units = append(s.units, model.Unit{Key: model.UnitKey{UserID: userID, UnitName: item.Name.Token}, Index: model.BlindIndex{NameEnc: item.Name.Enc}})
+			return item.Name, nil
+		}
+	}
+	return model.NameRef{}, store.ErrNoRows
+}
+
+func (s *trashStore) Purge(ctx context.Context, userID int, ids []int64) (int64, error) {
+	purged := int64(len(s.trash))
+	s.trash = nil
+	return purged, nil
+}
+
+func (s *trashStore) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	select {
+	case s.expired <- before:
+	default:
+	}
+	return 0, nil
+}
+
+func (s *trashStore) Search(ctx context.Context, userID int, query model.SearchQuery) ([]model.Unit, error) {
+	return s.units, nil
+}
+
+// eventLog - брокер, запоминающий события
+type eventLog struct {
+	notify.Broker
+	events []model.Event
+}
+
+func (b *eventLog) Publish(ctx context.Context, event model.Event) error {
+	b.events = append(b.events, event)
+	return nil
+}
+
+func TestService_Trash(t *testing.T) {
+	ctx := context.Background()
+	st := &trashStore{units: []model.Unit{{Key: model.UnitKey{UserID: 1, UnitName: "tok"}, Index: model.BlindIndex{NameEnc: []byte("enc")}}}}
+	events := &eventLog{}
+	s := service{store: st, broker: events, zaplog: zap.NewNop()}
+
+	require.ErrorIs(t, s.Delete(ctx, 1, ""), unitpath.ErrInvalidName)
+	require.ErrorIs(t, s.Delete(ctx, 1, "other"), store.ErrNoRows)
+	require.Empty(t, events.events)
+
+	require.NoError(t, s.Delete(ctx, 1, "tok"))
+	items, err := s.ListTrash(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	// Восстановление уведомляет о создании с зашифрованным именем
+	require.NoError(t, s.Restore(ctx, 1, items[0].ID))
+	require.ErrorIs(t, s.Restore(ctx, 1, items[0].ID), store.ErrNoRows)
+	require.Equal(t, []model.Event{
+		{UserID: 1, Kind: model.EventDeleted, UnitName: "tok"},
+		{UserID: 1, Kind: model.EventCreated, UnitName: "tok", NameEnc: []byte("enc")},
+	}, events.events)
+
+	require.NoError(t, s.Delete(ctx, 1, "tok"))
+	purged, err := s.Purge(ctx, 1, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, purged)
+}
+
+func TestService_PurgeExpired(t *testing.T) {
+	st := &trashStore{expired: make(chan time.Time, 1)}
+	s := service{store: st, zaplog: zap.NewNop()}
+	go s.purgeExpired(24*time.Hour, time.Hour)
+
+	// Первая очистка выполняется сразу при запуске
+	select {
+	case before := <-st.expired:
+		require.WithinDuration(t, time.Now().Add(-24*time.Hour), before, time.Minute)
+	case <-time.After(time.Second):
+		t.Fatal("no purge on start")
+	}
+}
+
+func TestService_HideSensitive(t *testing.T) {
+	st := &trashStore{units: []model.Unit{{Meta: model.UnitMeta{Fields: []model.Field{
+		{Key: "user", Value: "alice"},
+		{Key: "pin", Value: "1234", Sensitive: true},
+	}}}}}
+	s := service{store: st}
+
+	units, err := s.Search(context.Background(), 1, model.SearchQuery{})
+	require.NoError(t, err)
+	require.Equal(t, []model.Field{{Key: "user", Value: "alice"}, {Key: "pin", Sensitive: true}}, units[0].Meta.Fields)
+}
@@ -4,7 +4,12 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/iurnickita/gophkeeper/server/internal/model"
@@ -17,17 +22,30 @@ import (
 type Store interface {
 	AuthRegister(ctx context.Context, login string, password string) (int, error)
 	AuthLogin(ctx context.Context, login string, password string) (int, error)
-	List(ctx context.Context, userID int) ([]string, error)
+	List(ctx context.Context, userID int, folder string) ([]model.Entry, []model.Entry, error)
 	Read(ctx context.Context, userID int, unitName string) (model.Unit, error)
-	Write(ctx context.Context, unit model.Unit) error
+	Write(ctx context.Context, unit model.Unit) (model.Unit, bool, error)
 	Delete(ctx context.Context, userID int, unitName string) error
+	Move(ctx context.Context, userID int, fromFolder string, units []model.Rename, folders []model.Rename) error
+	Mkdir(ctx context.Context, userID int, folder model.NameRef, parents []model.NameRef) error
+	Search(ctx context.Context, userID int, query model.SearchQuery) ([]model.Unit, error)
+	Due(ctx context.Context, userID int, before time.Time) ([]model.Unit, error)
+	Sync(ctx context.Context, userID int, since int64) (model.Changes, error)
+	ListTrash(ctx context.Context, userID int) ([]model.TrashItem, error)
+	Restore(ctx context.Context, userID int, id int64) (model.NameRef, error)
+	Purge(ctx context.Context, userID int, ids []int64) (int64, error)
+	PurgeExpired(ctx context.Context, before time.Time) (int64, error)
 	GetEncryptSK(ctx context.Context) ([]string, error)
 	SetEncryptSK(ctx context.Context, sk string) error
+	Dump(ctx context.Context) (model.Dump, error)
+	Load(ctx context.Context, dump model.Dump, perUser bool) error
 }
 
 var (
 	ErrNoRows        = errors.New("no rows")
 	ErrAlreadyExists = errors.New("already exists")
+	ErrConflict      = errors.New("data changed concurrently")
+	ErrNotEmpty      = errors.New("store is not empty")
 )
 
 // psqlStore postgresql реализация интерфейса хранилища
@@ -82,63 +100,813 @@ func (s *psqlStore) AuthLogin(ctx context.Context, login string, password string
 	return userid, nil
 }
 
-// List implements Store.
-func (s *psqlStore) List(ctx context.Context, userID int) ([]string, error) {
-	panic("unimplemented")
+// List возвращает единицы данных и явно созданные папки внутри папки folder (рекурсивно).
+// folder - токен папки; пустой folder - все данные пользователя
+func (s *psqlStore) List(ctx context.Context, userID int, folder string) ([]model.Entry, []model.Entry, error) {
+	scope, err := folderScope(folder)
+	if err != nil {
+		return nil, nil, err
+	}
+	units, err := s.selectEntries(ctx,
+		"SELECT unitname, nameenc, folders FROM data_units"+
+			" WHERE userid = $1"+
+			"   AND deletedat IS NULL"+
+			"   AND ($2::text = '[]' OR folders @> $2::jsonb)"+
+			" ORDER BY unitname",
+		userID,
+		scope)
+	if err != nil {
+		return nil, nil, err
+	}
+	folders, err := s.selectEntries(ctx,
+		"SELECT path, pathenc, parents FROM folders"+
+			" WHERE userid = $1"+
+			"   AND ($2::text = '[]' OR parents @> $2::jsonb)"+
+			" ORDER BY path",
+		userID,
+		scope)
+	if err != nil {
+		return nil, nil, err
+	}
+	return units, folders, nil
+}
+
+// selectEntries выполняет запрос, возвращающий токен, зашифрованное имя и цепочку папок
+func (s *psqlStore) selectEntries(ctx context.Context, query string, args ...any) ([]model.Entry, error) {
+	rows, err := s.database.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []model.Entry
+	for rows.Next() {
+		var entry model.Entry
+		var folders []byte
+		err := rows.Scan(&entry.Name.Token, &entry.Name.Enc, &folders)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(folders, &entry.Folders)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// folderScope возвращает условие jsonb для отбора содержимого папки по токену
+func folderScope(folder string) (string, error) {
+	if folder == "" {
+		return "[]", nil
+	}
+	scope, err := json.Marshal([]map[string]string{{"token": folder}})
+	if err != nil {
+		return "", err
+	}
+	return string(scope), nil
 }
 
 // Read implements Store.
 func (s *psqlStore) Read(ctx context.Context, userID int, unitName string) (model.Unit, error) {
 	row := s.database.QueryRowContext(ctx,
-		"SELECT userid, unitname, uploadedat, type, datask, data"+
+		"SELECT userid, unitname, uploadedat, type, datask, data,"+
+			" description, tags, fields,"+
+			" COALESCE(createdat, uploadedat), COALESCE(updatedat, uploadedat),"+
+			" expiresat, rotateevery"+
 			" FROM data_units"+
 			" WHERE userid   = $1"+
-			"   AND unitname = $2",
+			"   AND unitname = $2"+
+			"   AND deletedat IS NULL",
 		userID,
 		unitName)
 	var unit model.Unit
+	var tags, fields []byte
+	var expiresAt sql.NullTime
+	var rotateEvery int64
 	err := row.Scan(&unit.Key.UserID,
 		&unit.Key.UnitName,
 		&unit.Meta.UploadedAt,
 		&unit.Meta.Type,
 		&unit.Meta.DataSK,
-		&unit.Data)
+		&unit.Data,
+		&unit.Meta.Description,
+		&tags,
+		&fields,
+		&unit.Meta.CreatedAt,
+		&unit.Meta.UpdatedAt,
+		&expiresAt,
+		&rotateEvery)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return model.Unit{}, ErrNoRows
 		}
 		return model.Unit{}, err
 	}
+	setSchedule(expiresAt, rotateEvery, &unit.Meta)
+	err = unmarshalMeta(tags, fields, &unit.Meta)
+	if err != nil {
+		return model.Unit{}, err
+	}
 	return unit, nil
 }
 
-// Write implements Store.
-func (s *psqlStore) Write(ctx context.Context, unit model.Unit) error {
-	_, err := s.database.ExecContext(ctx,
-		"INSERT INTO data_units (userid, unitname, uploadedat, type, datask, data)"+
-			"VALUES ($1, $2, $3, $4, $5, $6)",
+// Write записывает единицу данных. Существующая единица перезаписывается
+// с сохранением даты создания. Возвращает единицу данных с датами создания
+// и изменения на сервере и признак создания новой единицы
+func (s *psqlStore) Write(ctx context.Context, unit model.Unit) (model.Unit, bool, error) {
+	tags, err := jsonArray(unit.Meta.Tags)
+	if err != nil {
+		return model.Unit{}, false, err
+	}
+	fields, err := jsonArray(unit.Meta.Fields)
+	if err != nil {
+		return model.Unit{}, false, err
+	}
+	ngrams, err := jsonArray(unit.Index.NGrams)
+	if err != nil {
+		return model.Unit{}, false, err
+	}
+	folders, err := jsonArray(unit.Index.Folders)
+	if err != nil {
+		return model.Unit{}, false, err
+	}
+	tagTokens, err := jsonArray(unit.Index.TagTokens)
+	if err != nil {
+		return model.Unit{}, false, err
+	}
+	tx, err := s.database.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Unit{}, false, err
+	}
+	defer tx.Rollback()
+
+	var created bool
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO data_units (userid, unitname, uploadedat, type, datask, data,"+
+			" description, tags, fields, createdat, updatedat,"+
+			" nameenc, ngrams, folders, tagtokens, expiresat, rotateevery)"+
+			" VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $3, $3, $10, $11, $12, $13, $14, $15)"+
+			" ON CONFLICT (userid, unitname) WHERE deletedat IS NULL DO UPDATE SET"+
+			"  uploadedat  = EXCLUDED.uploadedat,"+
+			"  type        = EXCLUDED.type,"+
+			"  datask      = EXCLUDED.datask,"+
+			"  data        = EXCLUDED.data,"+
+			"  description = EXCLUDED.description,"+
+			"  tags        = EXCLUDED.tags,"+
+			"  fields      = EXCLUDED.fields,"+
+			"  updatedat   = EXCLUDED.updatedat,"+
+			"  nameenc     = EXCLUDED.nameenc,"+
+			"  ngrams      = EXCLUDED.ngrams,"+
+			"  folders     = EXCLUDED.folders,"+
+			"  tagtokens   = EXCLUDED.tagtokens,"+
+			"  expiresat   = EXCLUDED.expiresat,"+
+			"  rotateevery = EXCLUDED.rotateevery"+
+			" RETURNING (xmax = 0), COALESCE(createdat, uploadedat), updatedat",
 		unit.Key.UserID,
 		unit.Key.UnitName,
 		time.Now(),
 		unit.Meta.Type,
 		unit.Meta.DataSK,
-		unit.Data)
+		unit.Data,
+		unit.Meta.Description,
+		tags,
+		fields,
+		unit.Index.NameEnc,
+		ngrams,
+		folders,
+		tagTokens,
+		sql.NullTime{Time: unit.Meta.ExpiresAt, Valid: !unit.Meta.ExpiresAt.IsZero()},
+		int64(unit.Meta.RotateEvery/time.Second)).Scan(&created, &unit.Meta.CreatedAt, &unit.Meta.UpdatedAt)
 	if err != nil {
 		// Проверка: уже существует
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			if pgErr.Code == "23505" {
-				return ErrAlreadyExists
+				return model.Unit{}, false, ErrAlreadyExists
 			}
 		}
+		return model.Unit{}, false, err
+	}
+	err = logChanges(ctx, tx, unit.Key.UserID, change{unitName: unit.Key.UnitName})
+	if err != nil {
+		return model.Unit{}, false, err
+	}
+	return unit, created, tx.Commit()
+}
+
+// Search возвращает метаданные единиц данных, удовлетворяющих условиям поиска.
+// Имя и теги сопоставляются только по токенам слепого индекса.
+// Полезные данные не читаются
+func (s *psqlStore) Search(ctx context.Context, userID int, query model.SearchQuery) ([]model.Unit, error) {
+	var where strings.Builder
+	args := []any{userID}
+	addCond := func(cond string, arg any) {
+		args = append(args, arg)
+		where.WriteString(" AND " + strings.ReplaceAll(cond, "$?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if len(query.NGrams) > 0 {
+		ngrams, err := json.Marshal(query.NGrams)
+		if err != nil {
+			return nil, err
+		}
+		addCond("ngrams @> $?::jsonb", string(ngrams))
+	}
+	if query.Type != 0 {
+		addCond("type = $?", query.Type)
+	}
+	if len(query.TagTokens) > 0 {
+		tagTokens, err := json.Marshal(query.TagTokens)
+		if err != nil {
+			return nil, err
+		}
+		addCond("tagtokens @> $?::jsonb", string(tagTokens))
+	}
+	for _, field := range query.Fields {
+		// Совпадение по ключу или по ключу и значению
+		cond := map[string]any{"key": field.Key}
+		if field.Value != "" {
+			cond["value"] = field.Value
+			cond["sensitive"] = false
+		}
+		jsonCond, err := json.Marshal([]map[string]any{cond})
+		if err != nil {
+			return nil, err
+		}
+		addCond("fields @> $?::jsonb", string(jsonCond))
+	}
+
+	return s.selectMeta(ctx,
+		" WHERE userid = $1"+
+			"   AND deletedat IS NULL"+where.String()+
+			" ORDER BY unitname",
+		args...)
+}
+
+// Due возвращает метаданные единиц данных, срок действия или смены которых
+// наступает не позднее before
+func (s *psqlStore) Due(ctx context.Context, userID int, before time.Time) ([]model.Unit, error) {
+	return s.selectMeta(ctx,
+		" WHERE userid = $1"+
+			"   AND deletedat IS NULL"+
+			"   AND (expiresat <= $2"+
+			"    OR (rotateevery > 0"+
+			"        AND COALESCE(updatedat, uploadedat) + rotateevery * INTERVAL '1 second' <= $2))"+
+			" ORDER BY unitname",
+		userID,
+		before)
+}
+
+// selectMeta читает метаданные единиц данных по условию where без полезных данных
+func (s *psqlStore) selectMeta(ctx context.Context, where string, args ...any) ([]model.Unit, error) {
+	rows, err := s.database.QueryContext(ctx,
+		"SELECT userid, unitname, nameenc, uploadedat, type, description, tags, fields,"+
+			" COALESCE(createdat, uploadedat), COALESCE(updatedat, uploadedat),"+
+			" expiresat, rotateevery"+
+			" FROM data_units"+where,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var units []model.Unit
+	for rows.Next() {
+		var unit model.Unit
+		var tags, fields []byte
+		var expiresAt sql.NullTime
+		var rotateEvery int64
+		err := rows.Scan(&unit.Key.UserID,
+			&unit.Key.UnitName,
+			&unit.Index.NameEnc,
+			&unit.Meta.UploadedAt,
+			&unit.Meta.Type,
+			&unit.Meta.Description,
+			&tags,
+			&fields,
+			&unit.Meta.CreatedAt,
+			&unit.Meta.UpdatedAt,
+			&expiresAt,
+			&rotateEvery)
+		if err != nil {
+			return nil, err
+		}
+		setSchedule(expiresAt, rotateEvery, &unit.Meta)
+		err = unmarshalMeta(tags, fields, &unit.Meta)
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+// setSchedule заполняет срок действия и периодичность смены (в секундах)
+func setSchedule(expiresAt sql.NullTime, rotateEvery int64, meta *model.UnitMeta) {
+	if expiresAt.Valid {
+		meta.ExpiresAt = expiresAt.Time
+	}
+	meta.RotateEvery = time.Duration(rotateEvery) * time.Second
+}
+
+// jsonArray сериализует срез для хранения в jsonb. nil записывается пустым массивом
+func jsonArray[T any](items []T) (string, error) {
+	if items == nil {
+		items = []T{}
+	}
+	jsonItems, err := json.Marshal(items)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonItems), nil
+}
+
+// unmarshalMeta десериализует теги и поля из jsonb
+func unmarshalMeta(tags []byte, fields []byte, meta *model.UnitMeta) error {
+	err := json.Unmarshal(tags, &meta.Tags)
+	if err != nil {
 		return err
 	}
-	return nil
+	return json.Unmarshal(fields, &meta.Fields)
 }
 
-// Delete implements Store.
+// Delete перемещает единицу данных в корзину.
+// Имя освобождается сразу и может быть занято новой единицей данных
 func (s *psqlStore) Delete(ctx context.Context, userID int, unitName string) error {
-	panic("unimplemented")
+	tx, err := s.database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE data_units SET deletedat = $3"+
+			" WHERE userid   = $1"+
+			"   AND unitname = $2"+
+			"   AND deletedat IS NULL",
+		userID,
+		unitName,
+		time.Now())
+	if err != nil {
+		return err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNoRows
+	}
+	err = logChanges(ctx, tx, userID, change{unitName: unitName, deleted: true})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListTrash возвращает содержимое корзины, начиная с последних удаленных
+func (s *psqlStore) ListTrash(ctx context.Context, userID int) ([]model.TrashItem, error) {
+	rows, err := s.database.QueryContext(ctx,
+		"SELECT unitid, unitname, nameenc, type, deletedat FROM data_units"+
+			" WHERE userid = $1"+
+			"   AND deletedat IS NOT NULL"+
+			" ORDER BY deletedat DESC, unitid DESC",
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []model.TrashItem
+	for rows.Next() {
+		var item model.TrashItem
+		err := rows.Scan(&item.ID, &item.Name.Token, &item.Name.Enc, &item.Type, &item.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Restore возвращает единицу данных из корзины.
+// Возвращает имя восстановленной единицы данных
+// Ошибки: ErrNoRows, ErrAlreadyExists (имя занято другой единицей данных)
+func (s *psqlStore) Restore(ctx context.Context, userID int, id int64) (model.NameRef, error) {
+	tx, err := s.database.BeginTx(ctx, nil)
+	if err != nil {
+		return model.NameRef{}, err
+	}
+	defer tx.Rollback()
+
+	var name model.NameRef
+	err = tx.QueryRowContext(ctx,
+		"UPDATE data_units SET deletedat = NULL"+
+			" WHERE userid = $1"+
+			"   AND unitid = $2"+
+			"   AND deletedat IS NOT NULL"+
+			" RETURNING unitname, nameenc",
+		userID,
+		id).Scan(&name.Token, &name.Enc)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.NameRef{}, ErrNoRows
+		}
+		return model.NameRef{}, convertPgError(err)
+	}
+	err = logChanges(ctx, tx, userID, change{unitName: name.Token})
+	if err != nil {
+		return model.NameRef{}, err
+	}
+	return name, tx.Commit()
+}
+
+// Purge окончательно удаляет единицы данных из корзины.
+// Пустой ids - очистка всей корзины. Возвращает количество удаленных
+func (s *psqlStore) Purge(ctx context.Context, userID int, ids []int64) (int64, error) {
+	if ids == nil {
+		ids = []int64{}
+	}
+	res, err := s.database.ExecContext(ctx,
+		"DELETE FROM data_units"+
+			" WHERE userid = $1"+
+			"   AND deletedat IS NOT NULL"+
+			"   AND (cardinality($2::bigint[]) = 0 OR unitid = ANY($2::bigint[]))",
+		userID,
+		ids)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// PurgeExpired окончательно удаляет единицы данных всех пользователей,
+// помещенные в корзину раньше before
+func (s *psqlStore) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.database.ExecContext(ctx,
+		"DELETE FROM data_units"+
+			" WHERE deletedat < $1",
+		before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Move переименовывает единицы данных и явно созданные папки одной транзакцией.
+// При перемещении папки fromFolder - ее токен; перечень units должен
+// включать все содержимое папки, иначе возвращается ErrConflict
+// Ошибки: ErrNoRows, ErrAlreadyExists, ErrConflict
+func (s *psqlStore) Move(ctx context.Context, userID int, fromFolder string, units []model.Rename, folders []model.Rename) error {
+	tx, err := s.database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Проверка полноты перечня: содержимое папки могло измениться после чтения клиентом
+	if fromFolder != "" {
+		scope, err := folderScope(fromFolder)
+		if err != nil {
+			return err
+		}
+		var count int
+		err = tx.QueryRowContext(ctx,
+			"SELECT count(*) FROM data_units"+
+				" WHERE userid = $1"+
+				"   AND deletedat IS NULL"+
+				"   AND folders @> $2::jsonb",
+			userID,
+			scope).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count != len(units) {
+			return ErrConflict
+		}
+	}
+
+	// Единицы данных
+	for _, unit := range units {
+		ngrams, err := jsonArray(unit.Index.NGrams)
+		if err != nil {
+			return err
+		}
+		unitFolders, err := jsonArray(unit.Index.Folders)
+		if err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx,
+			"UPDATE data_units SET unitname = $3, nameenc = $4, ngrams = $5, folders = $6"+
+				" WHERE userid   = $1"+
+				"   AND unitname = $2"+
+				"   AND deletedat IS NULL",
+			userID,
+			unit.From,
+			unit.To,
+			unit.Index.NameEnc,
+			ngrams,
+			unitFolders)
+		if err != nil {
+			return convertPgError(err)
+		}
+		moved, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if moved == 0 {
+			return ErrNoRows
+		}
+		err = logChanges(ctx, tx, userID, change{unitName: unit.From, deleted: true}, change{unitName: unit.To})
+		if err != nil {
+			return err
+		}
+	}
+	if fromFolder == "" {
+		if len(units) == 0 {
+			return ErrNoRows
+		}
+		return tx.Commit()
+	}
+
+	// Явно созданные папки: удаление старых, запись перемещенных.
+	// Совпадающие с существующими объединяются
+	scope, err := folderScope(fromFolder)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.selectTokens(ctx, tx,
+		"DELETE FROM folders"+
+			" WHERE userid = $1"+
+			"   AND (path = $2 OR parents @> $3::jsonb)"+
+			" RETURNING path",
+		userID,
+		fromFolder,
+		scope)
+	if err != nil {
+		return err
+	}
+	if len(units) == 0 && len(deleted) == 0 {
+		return ErrNoRows
+	}
+	for _, folder := range folders {
+		if !slices.Contains(deleted, folder.From) {
+			continue
+		}
+		parents, err := jsonArray(folder.Index.Folders)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO folders (userid, path, pathenc, parents)"+
+				" VALUES ($1, $2, $3, $4)"+
+				" ON CONFLICT DO NOTHING",
+			userID,
+			folder.To,
+			folder.Index.NameEnc,
+			parents)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// change - запись журнала изменений
+type change struct {
+	unitName string
+	deleted  bool
+}
+
+// logChanges записывает изменения в журнал под очередными номерами последовательности пользователя.
+// Более ранние записи по тому же имени больше не нужны и удаляются
+func logChanges(ctx context.Context, tx *sql.Tx, userID int, changes ...change) error {
+	for _, change := range changes {
+		var seq int64
+		err := tx.QueryRowContext(ctx,
+			"INSERT INTO sync_seq (userid, seq)"+
+				" VALUES ($1, 1)"+
+				" ON CONFLICT (userid) DO UPDATE SET seq = sync_seq.seq + 1"+
+				" RETURNING seq",
+			userID).Scan(&seq)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			"DELETE FROM change_log"+
+				" WHERE userid   = $1"+
+				"   AND unitname = $2",
+			userID,
+			change.unitName)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO change_log (userid, seq, unitname, deleted)"+
+				" VALUES ($1, $2, $3, $4)",
+			userID,
+			seq,
+			change.unitName,
+			change.deleted)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sync возвращает изменения единиц данных после курсора since.
+// Нулевой, неизвестный серверу или выданный до восстановления курсор дает полный снимок
+func (s *psqlStore) Sync(ctx context.Context, userID int, since int64) (model.Changes, error) {
+	tx, err := s.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return model.Changes{}, err
+	}
+	defer tx.Rollback()
+
+	// Текущий курсор
+	var changes model.Changes
+	var resetSeq int64
+	err = tx.QueryRowContext(ctx,
+		"SELECT seq, resetseq FROM sync_seq"+
+			" WHERE userid = $1",
+		userID).Scan(&changes.Cursor, &resetSeq)
+	if err != nil && err != sql.ErrNoRows {
+		return model.Changes{}, err
+	}
+
+	// Полный снимок
+	if since <= 0 || since > changes.Cursor || since < resetSeq {
+		changes.Full = true
+		changes.Units, err = selectUnits(ctx, tx,
+			" WHERE userid = $1"+
+				"   AND deletedat IS NULL",
+			userID)
+		if err != nil {
+			return model.Changes{}, err
+		}
+		return changes, tx.Commit()
+	}
+
+	// Изменения по журналу
+	rows, err := tx.QueryContext(ctx,
+		"SELECT DISTINCT ON (unitname) unitname, deleted FROM change_log"+
+			" WHERE userid = $1"+
+			"   AND seq    > $2"+
+			" ORDER BY unitname, seq DESC",
+		userID,
+		since)
+	if err != nil {
+		return model.Changes{}, err
+	}
+	var updated []string
+	for rows.Next() {
+		var unitName string
+		var deleted bool
+		if err := rows.Scan(&unitName, &deleted); err != nil {
+			rows.Close()
+			return model.Changes{}, err
+		}
+		if deleted {
+			changes.Deleted = append(changes.Deleted, unitName)
+		} else {
+			updated = append(updated, unitName)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return model.Changes{}, err
+	}
+	if len(updated) == 0 {
+		return changes, tx.Commit()
+	}
+
+	changes.Units, err = selectUnits(ctx, tx,
+		" WHERE userid = $1"+
+			"   AND deletedat IS NULL"+
+			"   AND unitname = ANY($2::text[])",
+		userID,
+		updated)
+	if err != nil {
+		return model.Changes{}, err
+	}
+	// Отсутствующие к моменту чтения считаются удаленными
+	for _, unitName := range updated {
+		found := slices.ContainsFunc(changes.Units, func(unit model.Unit) bool {
+			return unit.Key.UnitName == unitName
+		})
+		if !found {
+			changes.Deleted = append(changes.Deleted, unitName)
+		}
+	}
+	return changes, tx.Commit()
+}
+
+// selectUnits читает единицы данных с полезными данными в транзакции
+func selectUnits(ctx context.Context, tx *sql.Tx, where string, args ...any) ([]model.Unit, error) {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT userid, unitname, nameenc, uploadedat, type, datask, data,"+
+			" description, tags, fields,"+
+			" COALESCE(createdat, uploadedat), COALESCE(updatedat, uploadedat),"+
+			" expiresat, rotateevery"+
+			" FROM data_units"+where,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var units []model.Unit
+	for rows.Next() {
+		var unit model.Unit
+		var tags, fields []byte
+		var expiresAt sql.NullTime
+		var rotateEvery int64
+		err := rows.Scan(&unit.Key.UserID,
+			&unit.Key.UnitName,
+			&unit.Index.NameEnc,
+			&unit.Meta.UploadedAt,
+			&unit.Meta.Type,
+			&unit.Meta.DataSK,
+			&unit.Data,
+			&unit.Meta.Description,
+			&tags,
+			&fields,
+			&unit.Meta.CreatedAt,
+			&unit.Meta.UpdatedAt,
+			&expiresAt,
+			&rotateEvery)
+		if err != nil {
+			return nil, err
+		}
+		setSchedule(expiresAt, rotateEvery, &unit.Meta)
+		err = unmarshalMeta(tags, fields, &unit.Meta)
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+// selectTokens выполняет запрос в транзакции, возвращающий один строковый столбец
+func (s *psqlStore) selectTokens(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tokens []string
+	for rows.Next() {
+		var token string
+		err := rows.Scan(&token)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Mkdir создает пустую папку. Повторное создание не является ошибкой
+func (s *psqlStore) Mkdir(ctx context.Context, userID int, folder model.NameRef, parents []model.NameRef) error {
+	jsonParents, err := jsonArray(parents)
+	if err != nil {
+		return err
+	}
+	_, err = s.database.ExecContext(ctx,
+		"INSERT INTO folders (userid, path, pathenc, parents)"+
+			" VALUES ($1, $2, $3, $4)"+
+			" ON CONFLICT DO NOTHING",
+		userID,
+		folder.Token,
+		folder.Enc,
+		jsonParents)
+	return err
+}
+
+// convertPgError преобразует ошибку уникальности postgresql в ErrAlreadyExists
+func convertPgError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if pgErr.Code == "23505" {
+			return ErrAlreadyExists
+		}
+	}
+	return err
 }
 
 // GetEncryptSK
@@ -181,6 +949,219 @@ func (s *psqlStore) SetEncryptSK(ctx context.Context, sk string) error {
 	return nil
 }
 
+// Dump возвращает согласованный снимок учетных записей, промежуточных ключей,
+// папок и единиц данных (включая корзину)
+func (s *psqlStore) Dump(ctx context.Context) (model.Dump, error) {
+	tx, err := s.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return model.Dump{}, err
+	}
+	defer tx.Rollback()
+
+	var dump model.Dump
+	tables := []struct {
+		rows  *[]model.DumpRow
+		query string
+	}{
+		{&dump.Auth, "SELECT userid, to_jsonb(t), NULL::bytea FROM auth t ORDER BY userid"},
+		{&dump.EncryptionSK, "SELECT 0, to_jsonb(t), NULL::bytea FROM encryption_sk t ORDER BY id"},
+		{&dump.Folders, "SELECT userid, to_jsonb(t), NULL::bytea FROM folders t ORDER BY userid, path"},
+		// Дата создания старых строк фиксируется в снимке
+		{&dump.DataUnits, "SELECT userid, to_jsonb(t) - 'data' || jsonb_build_object('createdat', COALESCE(t.createdat, t.uploadedat)), data" +
+			" FROM data_units t ORDER BY unitid"},
+	}
+	for _, table := range tables {
+		if *table.rows, err = dumpRows(ctx, tx, table.query); err != nil {
+			return model.Dump{}, err
+		}
+	}
+	return dump, tx.Commit()
+}
+
+// dumpRows читает строки снимка таблицы
+func dumpRows(ctx context.Context, tx *sql.Tx, query string) ([]model.DumpRow, error) {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var dump []model.DumpRow
+	for rows.Next() {
+		var userID sql.NullInt64
+		var row, data []byte
+		if err := rows.Scan(&userID, &row, &data); err != nil {
+			return nil, err
+		}
+		dump = append(dump, model.DumpRow{UserID: int(userID.Int64), Row: row, Data: data})
+	}
+	return dump, rows.Err()
+}
+
+// Load восстанавливает снимок в одной транзакции.
+// perUser = false: полное восстановление, хранилище должно быть пустым.
+// perUser = true: данные пользователей снимка заменяются, остальные не затрагиваются;
+// промежуточные ключи не восстанавливаются
+func (s *psqlStore) Load(ctx context.Context, dump model.Dump, perUser bool) error {
+	tx, err := s.database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if perUser {
+		err = loadUsers(ctx, tx, dump)
+	} else {
+		err = loadAll(ctx, tx, dump)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// loadAll восстанавливает снимок в пустое хранилище
+func loadAll(ctx context.Context, tx *sql.Tx, dump model.Dump) error {
+	var used bool
+	err := tx.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM auth)"+
+			"    OR EXISTS (SELECT 1 FROM data_units)"+
+			"    OR EXISTS (SELECT 1 FROM encryption_sk)").Scan(&used)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrNotEmpty
+	}
+
+	tables := []struct {
+		name string
+		rows []model.DumpRow
+	}{
+		{"encryption_sk", dump.EncryptionSK},
+		{"auth", dump.Auth},
+		{"folders", dump.Folders},
+		{"data_units", dump.DataUnits},
+	}
+	for _, table := range tables {
+		for _, row := range table.rows {
+			if err := insertRow(ctx, tx, table.name, row, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Последовательности продолжаются после восстановленных значений
+	for table, column := range map[string]string{"auth": "userid", "data_units": "unitid", "encryption_sk": "id"} {
+		_, err := tx.ExecContext(ctx,
+			"SELECT setval(pg_get_serial_sequence('"+table+"', '"+column+"'),"+
+				" COALESCE(MAX("+column+"), 0) + 1, false) FROM "+table)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO sync_seq (userid, seq, resetseq)"+
+			" SELECT userid, $1, $1 FROM auth"+
+			" ON CONFLICT (userid) DO UPDATE SET seq = EXCLUDED.seq, resetseq = EXCLUDED.resetseq",
+		resetSeq())
+	return err
+}
+
+// resetSeq - номер последовательности синхронизации после восстановления.
+// Время в микросекундах больше любого курсора, выданного клиентам до восстановления:
+// изменения нумеруются по одному и не обгоняют время. Курсоры меньше него дают полный снимок
+func resetSeq() int64 {
+	return time.Now().UnixMicro()
+}
+
+// loadUsers заменяет данные пользователей снимка. Существующая учетная запись
+// сохраняет свой userid, новая получает следующий. Журнал изменений пользователя
+// очищается, последовательность переносится за курсоры клиентов:
+// клиенты получат полный снимок при следующей синхронизации
+func loadUsers(ctx context.Context, tx *sql.Tx, dump model.Dump) error {
+	users := make(map[int]int, len(dump.Auth))
+	for _, user := range dump.Auth {
+		var userID int
+		err := tx.QueryRowContext(ctx,
+			"SELECT userid FROM auth"+
+				" WHERE login = $1::jsonb->>'login'",
+			string(user.Row)).Scan(&userID)
+		switch {
+		case err == sql.ErrNoRows:
+			err = tx.QueryRowContext(ctx,
+				"SELECT nextval(pg_get_serial_sequence('auth', 'userid'))").Scan(&userID)
+		case err == nil:
+			for _, table := range []string{"auth", "data_units", "folders", "change_log", "sync_seq"} {
+				if _, err = tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE userid = $1", userID); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if err := insertRow(ctx, tx, "auth", user, map[string]any{"userid": userID}); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO sync_seq (userid, seq, resetseq)"+
+				" VALUES ($1, $2, $2)",
+			userID,
+			resetSeq())
+		if err != nil {
+			return err
+		}
+		users[user.UserID] = userID
+	}
+
+	for _, folder := range dump.Folders {
+		userID, ok := users[folder.UserID]
+		if !ok {
+			continue
+		}
+		if err := insertRow(ctx, tx, "folders", folder, map[string]any{"userid": userID}); err != nil {
+			return err
+		}
+	}
+	for _, unit := range dump.DataUnits {
+		userID, ok := users[unit.UserID]
+		if !ok {
+			continue
+		}
+		// unitid общий для всех пользователей: выдается заново
+		var unitID int64
+		err := tx.QueryRowContext(ctx,
+			"SELECT nextval(pg_get_serial_sequence('data_units', 'unitid'))").Scan(&unitID)
+		if err != nil {
+			return err
+		}
+		if err := insertRow(ctx, tx, "data_units", unit, map[string]any{"userid": userID, "unitid": unitID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertRow добавляет строку снимка. Значения set заменяют столбцы строки
+func insertRow(ctx context.Context, tx *sql.Tx, table string, row model.DumpRow, set map[string]any) error {
+	if set == nil {
+		set = map[string]any{}
+	}
+	if table == "data_units" {
+		set["data"] = `\x` + hex.EncodeToString(row.Data)
+	}
+	override, err := json.Marshal(set)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO "+table+
+			" SELECT * FROM jsonb_populate_record(NULL::"+table+", $1::jsonb || $2::jsonb)",
+		string(row.Row),
+		string(override))
+	return err
+}
+
 // NewStore создает объект хранилища
 func NewStore(cfg config.Config) (Store, error) {
 	db, err := sql.Open("pgx", cfg.DBDsn)
@@ -203,7 +1184,7 @@ func NewStore(cfg config.Config) (Store, error) {
 	_, err = db.Exec(
 		"CREATE TABLE IF NOT EXISTS data_units (" +
 			" userid INTEGER," +
-			" unitname VARCHAR (20) NOT NULL," +
+			" unitname VARCHAR (255) NOT NULL," +
 			" uploadedat TIMESTAMP NOT NULL," +
 			" type SMALLINT NOT NULL," +
 			" datask VARCHAR (400) NOT NULL," +
@@ -214,6 +1195,135 @@ func NewStore(cfg config.Config) (Store, error) {
 		return nil, err
 	}
 
+	// Иерархические имена: расширение ранее созданной таблицы
+	_, err = db.Exec(
+		"ALTER TABLE data_units ALTER COLUMN unitname TYPE VARCHAR (255);")
+	if err != nil {
+		return nil, err
+	}
+
+	// Метаданные единиц данных
+	_, err = db.Exec(
+		"ALTER TABLE data_units" +
+			" ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT ''," +
+			" ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'," +
+			" ADD COLUMN IF NOT EXISTS fields JSONB NOT NULL DEFAULT '[]'," +
+			" ADD COLUMN IF NOT EXISTS createdat TIMESTAMP," +
+			" ADD COLUMN IF NOT EXISTS updatedat TIMESTAMP;")
+	if err != nil {
+		return nil, err
+	}
+
+	// Слепой индекс: зашифрованное клиентом имя и токены для поиска
+	_, err = db.Exec(
+		"ALTER TABLE data_units" +
+			" ADD COLUMN IF NOT EXISTS nameenc BYTEA," +
+			" ADD COLUMN IF NOT EXISTS ngrams JSONB NOT NULL DEFAULT '[]'," +
+			" ADD COLUMN IF NOT EXISTS folders JSONB NOT NULL DEFAULT '[]'," +
+			" ADD COLUMN IF NOT EXISTS tagtokens JSONB NOT NULL DEFAULT '[]';")
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range []string{"ngrams", "folders", "tagtokens"} {
+		_, err = db.Exec(
+			"CREATE INDEX IF NOT EXISTS data_units_" + column + "_idx" +
+				" ON data_units USING GIN (" + column + ");")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Срок действия и периодичность смены
+	_, err = db.Exec(
+		"ALTER TABLE data_units" +
+			" ADD COLUMN IF NOT EXISTS expiresat TIMESTAMP," +
+			" ADD COLUMN IF NOT EXISTS rotateevery BIGINT NOT NULL DEFAULT 0;")
+	if err != nil {
+		return nil, err
+	}
+
+	// Корзина: удаленные единицы данных хранятся до окончательной очистки.
+	// Уникальность имени действует только среди неудаленных
+	_, err = db.Exec(
+		"ALTER TABLE data_units" +
+			" ADD COLUMN IF NOT EXISTS unitid BIGSERIAL," +
+			" ADD COLUMN IF NOT EXISTS deletedat TIMESTAMP," +
+			" DROP CONSTRAINT IF EXISTS data_units_pkey;")
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(
+		"CREATE UNIQUE INDEX IF NOT EXISTS data_units_unitid_idx" +
+			" ON data_units (unitid);")
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(
+		"CREATE UNIQUE INDEX IF NOT EXISTS data_units_unitname_idx" +
+			" ON data_units (userid, unitname) WHERE deletedat IS NULL;")
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(
+		"CREATE INDEX IF NOT EXISTS data_units_deletedat_idx" +
+			" ON data_units (deletedat) WHERE deletedat IS NOT NULL;")
+	if err != nil {
+		return nil, err
+	}
+
+	// Журнал изменений для синхронизации: последовательность пользователя
+	// и последнее изменение по каждому имени
+	_, err = db.Exec(
+		"CREATE TABLE IF NOT EXISTS sync_seq (" +
+			" userid INTEGER PRIMARY KEY," +
+			" seq BIGINT NOT NULL" +
+			" );")
+	if err != nil {
+		return nil, err
+	}
+	// Курсоры меньше resetseq выданы до восстановления из резервной копии
+	_, err = db.Exec(
+		"ALTER TABLE sync_seq" +
+			" ADD COLUMN IF NOT EXISTS resetseq BIGINT NOT NULL DEFAULT 0;")
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(
+		"CREATE TABLE IF NOT EXISTS change_log (" +
+			" userid INTEGER," +
+			" seq BIGINT," +
+			" unitname VARCHAR (255) NOT NULL," +
+			" deleted BOOLEAN NOT NULL," +
+			" PRIMARY KEY (userid, seq)" +
+			" );")
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(
+		"CREATE INDEX IF NOT EXISTS change_log_unitname_idx" +
+			" ON change_log (userid, unitname);")
+	if err != nil {
+		return nil, err
+	}
+
+	// Таблица явно созданных (в том числе пустых) папок
+	_, err = db.Exec(
+		"CREATE TABLE IF NOT EXISTS folders (" +
+			" userid INTEGER," +
+			" path VARCHAR (255) NOT NULL," +
+			" PRIMARY KEY (userid, path)" +
+			" );")
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(
+		"ALTER TABLE folders" +
+			" ADD COLUMN IF NOT EXISTS pathenc BYTEA," +
+			" ADD COLUMN IF NOT EXISTS parents JSONB NOT NULL DEFAULT '[]';")
+	if err != nil {
+		return nil, err
+	}
+
 	// Таблица промежуточных(постоянных) паролей
 	_, err = db.Exec(
 		"CREATE TABLE IF NOT EXISTS encryption_sk (" +
@@ -0,0 +1,193 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/iurnickita/gophkeeper/server/internal/model"
+	"github.com/iurnickita/gophkeeper/server/internal/store/config"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore подключается к тестовой базе TEST_DATABASE_URI. Без нее тест пропускается
+func testStore(t *testing.T) *psqlStore {
+	dsn := os.Getenv("TEST_DATABASE_URI")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URI is not set")
+	}
+	s, err := NewStore(config.Config{DBDsn: dsn})
+	require.NoError(t, err)
+	return s.(*psqlStore)
+}
+
+// testUser регистрирует пользователя с уникальным логином
+func testUser(t *testing.T, s *psqlStore) int {
+	login := fmt.Sprintf("t%d", time.Now().UnixNano()%1e15)
+	userID, err := s.AuthRegister(context.Background(), login, "password")
+	require.NoError(t, err)
+	return userID
+}
+
+// testUnit
+func testUnit(userID int, name string, data string) model.Unit {
+	return model.Unit{
+		Key:   model.UnitKey{UserID: userID, UnitName: name},
+		Meta:  model.UnitMeta{Type: 1, DataSK: "sk"},
+		Index: model.BlindIndex{NameEnc: []byte(name)},
+		Data:  []byte(data),
+	}
+}
+
+func TestStore_Write(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	userID := testUser(t, s)
+
+	// Возвращаются даты сервера: версия для отложенных изменений клиента
+	written, created, err := s.Write(ctx, testUnit(userID, "a", "v1"))
+	require.NoError(t, err)
+	require.True(t, created)
+	unit, err := s.Read(ctx, userID, "a")
+	require.NoError(t, err)
+	require.True(t, unit.Meta.UpdatedAt.Equal(written.Meta.UpdatedAt))
+
+	rewritten, created, err := s.Write(ctx, testUnit(userID, "a", "v2"))
+	require.NoError(t, err)
+	require.False(t, created)
+	require.True(t, rewritten.Meta.CreatedAt.Equal(written.Meta.CreatedAt))
+	require.False(t, rewritten.Meta.UpdatedAt.Before(written.Meta.UpdatedAt))
+}
+
+func TestStore_Trash(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	userID := testUser(t, s)
+
+	for _, name := range []string{"a", "b"} {
+		_, _, err := s.Write(ctx, testUnit(userID, name, "v1"))
+		require.NoError(t, err)
+	}
+
+	// Удаление перемещает в корзину и освобождает имя
+	require.NoError(t, s.Delete(ctx, userID, "a"))
+	require.ErrorIs(t, s.Delete(ctx, userID, "a"), ErrNoRows)
+	_, err := s.Read(ctx, userID, "a")
+	require.ErrorIs(t, err, ErrNoRows)
+	items, err := s.ListTrash(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, model.NameRef{Token: "a", Enc: []byte("a")}, items[0].Name)
+
+	// Имя занято новой единицей данных: восстановление невозможно
+	_, _, err = s.Write(ctx, testUnit(userID, "a", "v2"))
+	require.NoError(t, err)
+	_, err = s.Restore(ctx, userID, items[0].ID)
+	require.ErrorIs(t, err, ErrAlreadyExists)
+
+	require.NoError(t, s.Delete(ctx, userID, "a"))
+	name, err := s.Restore(ctx, userID, items[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, "a", name.Token)
+	unit, err := s.Read(ctx, userID, "a")
+	require.NoError(t, err)
+	require.Equal(t, "v1", string(unit.Data))
+	_, err = s.Restore(ctx, userID, items[0].ID)
+	require.ErrorIs(t, err, ErrNoRows)
+
+	// Очистка по коду и целиком: неудаленные данные не затрагиваются
+	require.NoError(t, s.Delete(ctx, userID, "b"))
+	items, err = s.ListTrash(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	purged, err := s.Purge(ctx, userID, []int64{items[0].ID})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, purged)
+	purged, err = s.Purge(ctx, userID, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, purged)
+	items, err = s.ListTrash(ctx, userID)
+	require.NoError(t, err)
+	require.Empty(t, items)
+	_, err = s.Read(ctx, userID, "a")
+	require.NoError(t, err)
+}
+
+func TestStore_PurgeExpired(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	userID := testUser(t, s)
+
+	for _, name := range []string{"old", "new"} {
+		_, _, err := s.Write(ctx, testUnit(userID, name, "v"))
+		require.NoError(t, err)
+		require.NoError(t, s.Delete(ctx, userID, name))
+	}
+	// Дата удаления в прошлом, чтобы не затронуть данные других тестов
+	deletedAt := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := s.database.ExecContext(ctx,
+		"UPDATE data_units SET deletedat = $3 WHERE userid = $1 AND unitname = $2",
+		userID, "old", deletedAt)
+	require.NoError(t, err)
+
+	purged, err := s.PurgeExpired(ctx, deletedAt.Add(time.Hour))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, purged)
+	items, err := s.ListTrash(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, "new", items[0].Name.Token)
+}
+
+func TestStore_LoadUsers(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	userID := testUser(t, s)
+
+	for _, name := range []string{"a", "b"} {
+		_, _, err := s.Write(ctx, testUnit(userID, name, "v1"))
+		require.NoError(t, err)
+	}
+	unit, err := s.Read(ctx, userID, "a")
+	require.NoError(t, err)
+	full, err := s.Dump(ctx)
+	require.NoError(t, err)
+	dump := model.Dump{}
+	for _, row := range full.Auth {
+		if row.UserID == userID {
+			dump.Auth = append(dump.Auth, row)
+		}
+	}
+	for _, row := range full.DataUnits {
+		if row.UserID == userID {
+			dump.DataUnits = append(dump.DataUnits, row)
+		}
+	}
+
+	// Изменения после снимка: курсор клиента больше восстановленного журнала
+	require.NoError(t, s.Delete(ctx, userID, "b"))
+	changes, err := s.Sync(ctx, userID, 0)
+	require.NoError(t, err)
+	cursor := changes.Cursor
+
+	require.NoError(t, s.Load(ctx, dump, true))
+
+	// Курсор, выданный до восстановления, дает полный снимок
+	changes, err = s.Sync(ctx, userID, cursor)
+	require.NoError(t, err)
+	require.True(t, changes.Full)
+	require.Len(t, changes.Units, 2)
+	restored, err := s.Read(ctx, userID, "a")
+	require.NoError(t, err)
+	require.True(t, unit.Meta.CreatedAt.Equal(restored.Meta.CreatedAt))
+
+	// Курсор после восстановления - изменения по журналу
+	_, _, err = s.Write(ctx, testUnit(userID, "c", "v1"))
+	require.NoError(t, err)
+	changes, err = s.Sync(ctx, userID, changes.Cursor)
+	require.NoError(t, err)
+	require.False(t, changes.Full)
+	require.Len(t, changes.Units, 1)
+}